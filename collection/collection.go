@@ -219,14 +219,19 @@ func (c Collection) Normalize() Collection {
 	return result
 }
 
+// Equal returns whether this collection is equal to other, following the
+// FHIRPath equality semantics. Collections are equal if they have the same
+// length, and each item is equal to the item in the same position of the other
+// collection.
+//
+// Items whose equality cannot be determined are treated as not equal.
 func (c Collection) Equal(other Collection) bool {
 	if len(c) != len(other) {
 		return false
 	}
 
 	for i := range c {
-		// TODO: implement proper comparison
-		if c[i] != other[i] {
+		if !system.Equal(c[i], other[i]) {
 			return false
 		}
 	}
@@ -234,6 +239,19 @@ func (c Collection) Equal(other Collection) bool {
 	return true
 }
 
+// Contains returns whether any item in this collection is equal to v,
+// following the FHIRPath equality semantics.
+//
+// Items whose equality with v cannot be determined are treated as not equal.
+func (c Collection) Contains(v any) bool {
+	for _, item := range c {
+		if system.Equal(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c Collection) convertErr(got any, want string) error {
 	return fmt.Errorf("type %T %w to %v", got, ErrNotConvertible, want)
 }
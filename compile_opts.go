@@ -1,13 +1,245 @@
 package fhirpath
 
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/compile"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/funcs"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+)
+
 type CompileOption interface {
 	setCompile(*compileConfig) error
 }
 
 type compileConfig struct {
-	Text string
+	Text        string
+	Version     string
+	Lenient     bool
+	Variables   map[string]reflect.TypeSpecifier
+	RootType    reflect.TypeSpecifier
+	Functions   funcs.Table
+	Definitions map[string]*definition
+}
+
+// options converts this configuration into the options of the compiler.
+func (c *compileConfig) options() (compile.Options, error) {
+	var opts compile.Options
+	switch c.Version {
+	case "N2":
+		opts.Functions = funcs.N2
+	default:
+		opts.Functions = funcs.N1
+	}
+	if len(c.Functions) > 0 {
+		opts.Functions = opts.Functions.Clone()
+		maps.Copy(opts.Functions, c.Functions)
+	}
+	opts.Lenient = c.Lenient
+	opts.RootType = c.RootType
+	opts.Variables = c.Variables
+	if opts.Variables == nil {
+		opts.Variables = map[string]reflect.TypeSpecifier{}
+	}
+	if len(c.Definitions) > 0 {
+		functions, err := c.define(opts)
+		if err != nil {
+			return compile.Options{}, err
+		}
+		opts.Functions = functions
+	}
+	return opts, nil
+}
+
+// define returns the functions of the options with the defined functions
+// added. Each definition is compiled once for each configuration that it is
+// used in, and reused by every expression compiled in that configuration.
+func (c *compileConfig) define(opts compile.Options) (funcs.Table, error) {
+	key := c.definitionKey()
+	functions := opts.Functions.Clone()
+	pending := map[string]compile.Definition{}
+	for name, def := range c.Definitions {
+		if fn, ok := def.lookup(key); ok {
+			functions[name] = fn
+			continue
+		}
+		pending[name] = def.Definition
+	}
+	if len(pending) == 0 {
+		return functions, nil
+	}
+	opts.Functions = functions
+	functions, err := compile.Define(opts, pending)
+	if err != nil {
+		return nil, err
+	}
+	for name := range pending {
+		c.Definitions[name].store(key, functions[name])
+	}
+	return functions, nil
+}
+
+// definitionKey identifies the configuration that the defined functions are
+// compiled in, by its version and leniency, and by the custom and defined
+// functions that their bodies may invoke.
+func (c *compileConfig) definitionKey() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "N2=%v lenient=%v", c.Version == "N2", c.Lenient)
+	for _, name := range sortedNames(c.Functions) {
+		fmt.Fprintf(&b, " %v=%p", name, c.Functions[name])
+	}
+	for _, name := range sortedNames(c.Definitions) {
+		fmt.Fprintf(&b, " %v=%p", name, c.Definitions[name])
+	}
+	return b.String()
+}
+
+// sortedNames returns the keys of the map, in sorted order.
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setVersion sets the version of the FHIRPath language to compile with.
+func (c *compileConfig) setVersion(version string) error {
+	if c.Version != "" && c.Version != version {
+		return fmt.Errorf("fhirpath: cannot use version %v; already configured to use %v", version, c.Version)
+	}
+	c.Version = version
+	return nil
+}
+
+// newFunc returns the definition of the custom function implemented by fn.
+func newFunc(name string, fn any) (*funcs.Function, error) {
+	if !identifierPattern.MatchString(name) {
+		return nil, fmt.Errorf("fhirpath: cannot add function '%v': not a valid identifier", name)
+	}
+	function, err := funcs.Reflect(name, fn)
+	if err != nil {
+		return nil, fmt.Errorf("fhirpath: cannot add function '%v': %w", name, err)
+	}
+	return function, nil
+}
+
+// addFunction adds the definition of a custom function, which may not replace
+// a function of FHIRPath or another custom function.
+func (c *compileConfig) addFunction(name string, function *funcs.Function) error {
+	if err := c.checkFunctionName(name); err != nil {
+		return fmt.Errorf("fhirpath: cannot add function '%v': %w", name, err)
+	}
+	if c.Functions == nil {
+		c.Functions = funcs.Table{}
+	}
+	c.Functions[name] = function
+	return nil
+}
+
+// checkFunctionName checks that a custom function may be named name, which
+// may not be that of a function of FHIRPath, whether or not it is implemented,
+// or of another custom function.
+func (c *compileConfig) checkFunctionName(name string) error {
+	if _, ok := funcs.N2[name]; ok {
+		return errors.New("already defined by FHIRPath")
+	}
+	if _, ok := funcs.Spec[name]; ok {
+		return errors.New("reserved by FHIRPath")
+	}
+	if _, ok := c.Functions[name]; ok {
+		return errors.New("already added")
+	}
+	if _, ok := c.Definitions[name]; ok {
+		return errors.New("already defined")
+	}
+	return nil
+}
+
+// definition is a function defined by a FHIRPath expression with
+// [DefineFunction], along with the functions compiled from it in each
+// configuration that it has been used in.
+type definition struct {
+	compile.Definition
+
+	mu       sync.Mutex
+	compiled map[string]*funcs.Function
+}
+
+// newDefinition returns the definition of the function defined by the FHIRPath
+// expression body. The parameter named '$this' declares the type of the input
+// of the function.
+func newDefinition(name, body string, params []Parameter) (*definition, error) {
+	if !identifierPattern.MatchString(name) {
+		return nil, fmt.Errorf("fhirpath: cannot define function '%v': not a valid identifier", name)
+	}
+	def := &definition{Definition: compile.Definition{Body: body}}
+	seen := map[string]bool{}
+	for _, param := range params {
+		if param.Name != "$this" && (!identifierPattern.MatchString(param.Name) || envcontext.IsStandard(param.Name)) {
+			return nil, fmt.Errorf("fhirpath: cannot define function '%v': parameter '%v' is not a valid name", name, param.Name)
+		}
+		if seen[param.Name] {
+			return nil, fmt.Errorf("fhirpath: cannot define function '%v': parameter '%v' is declared more than once", name, param.Name)
+		}
+		seen[param.Name] = true
+		if _, ok := types.Parse(param.Type); param.Type != "" && !ok {
+			return nil, fmt.Errorf("fhirpath: cannot define function '%v': parameter '%v' has type %v: %w", name, param.Name, param.Type, ErrUnknownType)
+		}
+		if param.Name == "$this" {
+			def.Input = param.Type
+			continue
+		}
+		def.Params = append(def.Params, param)
+	}
+	return def, nil
+}
+
+// lookup returns the function compiled from the definition in the
+// configuration identified by the key.
+func (d *definition) lookup(key string) (*funcs.Function, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fn, ok := d.compiled[key]
+	return fn, ok
+}
+
+// store records the function compiled from the definition in the
+// configuration identified by the key.
+func (d *definition) store(key string, fn *funcs.Function) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.compiled == nil {
+		d.compiled = map[string]*funcs.Function{}
+	}
+	d.compiled[key] = fn
 }
 
+// defineFunction adds the function defined by a FHIRPath expression, which
+// may not replace a function of FHIRPath or another custom function.
+func (c *compileConfig) defineFunction(name string, def *definition) error {
+	if err := c.checkFunctionName(name); err != nil {
+		return fmt.Errorf("fhirpath: cannot define function '%v': %w", name, err)
+	}
+	if c.Definitions == nil {
+		c.Definitions = map[string]*definition{}
+	}
+	c.Definitions[name] = def
+	return nil
+}
+
+// identifierPattern matches the names that a function may be invoked by.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 func (c *compileConfig) apply(opts ...CompileOption) error {
 	for _, opt := range opts {
 		if err := opt.setCompile(c); err != nil {
@@ -28,17 +260,95 @@ var _ CompileOption = (*compileOption)(nil)
 // N1 returns a [CompileOption] that configures the compiler to use the FHIR N1
 // version of the FHIRPath language.
 //
+// This is the default if no version is specified.
+//
 // Only one of N1 or [N2] may be specified at a time.
 func N1() CompileOption {
-	return notImplemented{}
+	return compileOption(func(cfg *compileConfig) error {
+		return cfg.setVersion("N1")
+	})
 }
 
 // N2 returns a [CompileOption] that configures the compiler to use the FHIR N2
-// version of the FHIRPath language.
+// version of the FHIRPath language. This enables the functions added after the
+// normative release, such as lowBoundary, highBoundary, and precision.
 //
 // Only one of [N1] or N2 may be specified at a time.
 func N2() CompileOption {
-	return notImplemented{}
+	return compileOption(func(cfg *compileConfig) error {
+		return cfg.setVersion("N2")
+	})
+}
+
+// Lenient returns a [CompileOption] that configures the compiler to accept
+// paths that follow the conventions of the FHIR JSON format, but which are not
+// strictly valid FHIRPath. This allows a choice element to be named by its
+// type, so that 'Observation.valueQuantity' is equivalent to
+// 'Observation.value.ofType(Quantity)'.
+func Lenient() CompileOption {
+	return compileOption(func(cfg *compileConfig) error {
+		cfg.Lenient = true
+		return nil
+	})
+}
+
+// DeclareVariable returns a [CompileOption] that declares the environment
+// variable with the specified name and type, so that it may be referenced as
+// '%name'. Referencing a variable that is neither declared nor defined by FHIR
+// (such as '%resource' or '%ucum') is a compile error, as is declaring a
+// variable that FHIR defines.
+//
+// The value of the variable is provided with [WithVariable] when evaluating the
+// expression, and must be a collection of values of the declared type. The
+// type may be qualified by its namespace, such as 'FHIR.Coding' or
+// 'System.String', and 'System.Any' accepts values of any type. A value of a
+// type derived from the declared type is also accepted, such as a Patient for
+// a variable declared as 'Resource', as is a value that is implicitly
+// converted into it, such as an Integer for a variable declared as 'Decimal'.
+// If the type is not defined in either the FHIR or System namespace, an error
+// wrapping [ErrUnknownType] is returned.
+func DeclareVariable(name string, typ reflect.TypeSpecifier) CompileOption {
+	return compileOption(func(cfg *compileConfig) error {
+		if name == "" {
+			return fmt.Errorf("fhirpath: cannot declare a variable without a name")
+		}
+		if envcontext.IsStandard(name) {
+			return fmt.Errorf("fhirpath: cannot declare variable '%%%v': already defined by FHIR", name)
+		}
+		if _, ok := types.Parse(typ); !ok {
+			return fmt.Errorf("fhirpath: cannot declare variable '%%%v' as %v: %w", name, typ, ErrUnknownType)
+		}
+		if existing, ok := cfg.Variables[name]; ok && existing != typ {
+			return fmt.Errorf("fhirpath: cannot declare variable '%%%v' as %v; already declared as %v", name, typ, existing)
+		}
+		if cfg.Variables == nil {
+			cfg.Variables = map[string]reflect.TypeSpecifier{}
+		}
+		cfg.Variables[name] = typ
+		return nil
+	})
+}
+
+// WithRootType returns a [CompileOption] that declares the type of the input
+// that the expression is evaluated against, such as "Patient". The type of
+// every part of the expression is then inferred at compile time, and mistakes
+// that would otherwise silently evaluate to an empty result are reported as
+// compile errors: navigating into an element that the type does not define,
+// such as 'Patient.nmae', invoking a function on an input that it does not
+// apply to, comparing values that can never be equal, such as 'gender = 1',
+// or applying an arithmetic operator to values of types that it is not
+// defined for, such as '1 + name'.
+//
+// The type may be qualified by its namespace, such as 'FHIR.Patient'. The
+// inferred type of the result is reported by [Path.Type].
+func WithRootType(typ reflect.TypeSpecifier) CompileOption {
+	return compileOption(func(cfg *compileConfig) error {
+		if _, ok := types.Parse(typ); !ok {
+			return fmt.Errorf("fhirpath: cannot use root type %v: %w", typ, ErrUnknownType)
+		}
+		cfg.RootType = typ
+		return nil
+	})
 }
 
 // R4 returns a [CompileOption] that configures the compiler to use the FHIR R4
@@ -53,26 +363,108 @@ func SimpleR4() CompileOption {
 	return notImplemented{}
 }
 
+// Lambda is an argument of a function added with [AddFunc] that is passed to
+// it unevaluated, so that the function may evaluate it against each item of
+// its input -- as with the criteria of 'where(criteria)'. Within the argument,
+// '$this' refers to the item, and '$index' to its index within the input.
+type Lambda = funcs.Lambda
+
 // AddFunc returns a [CompileOption] that adds a custom function to the
-// FHIRPath compiler.
+// FHIRPath compiler, which may then be invoked by name as 'name(args...)'.
+//
+// fn must be a Go function with the signature:
+//
+//	func([context.Context,] collection.Collection, params...) (collection.Collection, error)
 //
-// fn must be a valid function that takes a [collection.Collection], input
-// parameters, and returns a [collection.Collection] and an [error]. Parameters
-// must be a conforming FHIRPath type; either from the base FHIR instance, or
-// from the [system] package.
+// It is invoked with the input collection of the function, and with each of
+// its arguments converted into the type of its parameter:
+//
+//   - A [collection.Collection] receives the evaluated argument as is.
+//   - A [Lambda] receives the argument unevaluated.
+//   - A type of the [system] package, such as [system.String], or of the FHIR
+//     model, such as *fhir.Coding, receives the single item of the evaluated
+//     argument. FHIR primitives and System types are converted into one
+//     another, as are System types that convert implicitly, such as an Integer
+//     into a Decimal. If the argument is empty, fn is not invoked and the
+//     result is empty; if it holds more than one item, it is an error.
+//
+// If fn is variadic, its last parameter receives any number of arguments,
+// each converted as above.
+//
+// The signature of fn is validated when compiling, and an error wrapping
+// [ErrInvalidSignature] is returned if it is not supported. Invocations are
+// checked at compile time: passing the wrong number of arguments is an
+// error, as is passing an argument whose type can never convert into that
+// of its parameter, such as a String literal to a [system.Integer].
+//
+// A custom function may not replace a function defined by FHIRPath, nor take
+// the name of one that this package does not yet implement, such as 'where'.
 func AddFunc(name string, fn any) CompileOption {
-	return notImplemented{}
+	function, err := newFunc(name, fn)
+	return compileOption(func(cfg *compileConfig) error {
+		if err != nil {
+			return err
+		}
+		return cfg.addFunction(name, function)
+	})
 }
 
 // AddFuncs returns a [CompileOption] that adds custom functions to the FHIRPath
-// compiler.
-//
-// Supplied functions must be a valid function that takes a
-// [collection.Collection], input parameters, and returns a
-// [collection.Collection] and an [error]. Parameters must be a conforming
-// FHIRPath type; either from the base FHIR instance, or from the [system] package.
+// compiler, indexed by name. Each function is added as with [AddFunc].
 func AddFuncs(funcs map[string]any) CompileOption {
-	return notImplemented{}
+	opts := make([]CompileOption, 0, len(funcs))
+	for _, name := range sortedNames(funcs) {
+		opts = append(opts, AddFunc(name, funcs[name]))
+	}
+	return compileOption(func(cfg *compileConfig) error {
+		return cfg.apply(opts...)
+	})
+}
+
+// Parameter is a parameter of a function defined with [DefineFunction]. The
+// Type is that of the items of the argument, such as 'System.String'; if
+// empty, the argument may be of any type.
+type Parameter = compile.Parameter
+
+// DefineFunction returns a [CompileOption] that defines a custom function in
+// FHIRPath itself, which may then be invoked by name as 'name(args...)'. The
+// body is the FHIRPath expression that the function evaluates -- for example,
+// with the body "given.first() & ' ' & family" and a '$this' parameter of type
+// 'HumanName', the function is invoked as "Patient.name.fullName()".
+//
+// Within the body, '$this' refers to the input of the function, and each of
+// the params to the value of its argument, as an environment variable -- so
+// that a parameter named 'separator' is referenced as '%separator'. The
+// arguments are evaluated against the input of the function, as with those of
+// a function added with [AddFunc]. No other declared variables may be
+// referenced by the body, though those defined by FHIR, such as '%resource',
+// may be.
+//
+// A parameter named '$this' is not an argument, but declares the type of the
+// input of the function, such as 'HumanName'. If it is declared, the body is
+// type-checked against it, as with [WithRootType], and invoking the function
+// on an input of another type is a compile error when the type of the input
+// is known.
+//
+// The body is compiled the first time that the option is used by [Compile] or
+// [Complete] with a given version, leniency, and set of custom functions, and
+// is reused by every later expression compiled with the same configuration.
+// Errors in the body are reported as errors of compiling the expression, even
+// if the function is never invoked. The body may invoke other functions,
+// including other defined functions, but not itself: a function that invokes
+// itself, directly or through other functions, is an error wrapping
+// [ErrRecursiveFunction].
+//
+// A defined function may not replace a function defined by FHIRPath or
+// another custom function.
+func DefineFunction(name, body string, params ...Parameter) CompileOption {
+	def, err := newDefinition(name, body, params)
+	return compileOption(func(cfg *compileConfig) error {
+		if err != nil {
+			return err
+		}
+		return cfg.defineFunction(name, def)
+	})
 }
 
 type notImplemented struct{}
@@ -0,0 +1,288 @@
+package fhirpath
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/compile"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/funcs"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+)
+
+// CompletionKind is the kind of thing that a [Completion] names.
+type CompletionKind string
+
+const (
+	// CompletionElement is an element of the input, such as 'name' of a
+	// Patient.
+	CompletionElement CompletionKind = "element"
+
+	// CompletionFunction is a function that may be invoked on the input.
+	CompletionFunction CompletionKind = "function"
+
+	// CompletionVariable is an environment variable, such as '%resource'.
+	CompletionVariable CompletionKind = "variable"
+
+	// CompletionType is the name of a type, such as 'Quantity' in
+	// 'value is Quantity'.
+	CompletionType CompletionKind = "type"
+)
+
+// Completion is a candidate for completing an expression at a cursor.
+type Completion struct {
+	// Label is the text of the candidate, which replaces the partial
+	// identifier that ends at the cursor. Variables are labelled without their
+	// '%' prefix, and functions without their arguments.
+	Label string
+
+	// Kind is the kind of thing that the candidate names.
+	Kind CompletionKind
+
+	// Detail is a short description of the candidate: the type of an element
+	// or variable, the signature of a function, or the qualified name of a
+	// type.
+	Detail string
+
+	// Documentation is a description of the candidate.
+	Documentation string
+}
+
+// Complete returns the candidates for completing the expression at the cursor,
+// which is a byte offset into the expression. Only the text before the cursor
+// is considered, and the candidates are those that begin with the partial
+// identifier that ends at the cursor, if any -- so that 'Patient.na' completes
+// to 'name'.
+//
+// The candidates depend on what may follow the text:
+//
+//   - After a '.', the elements of the inferred type of the input, and the
+//     functions that may be invoked on it.
+//   - After a '%', the environment variables that FHIR defines, and those
+//     declared with [DeclareVariable].
+//   - After 'is', 'as', or 'ofType(', the names of types.
+//
+// The rootType is the type of the input of the expression, as with
+// [WithRootType], and may be empty if it is not known. The expression is
+// usually incomplete while it is being edited, so syntax errors in it are
+// recovered from, rather than reported; an error is only returned if the
+// cursor or options are invalid.
+func Complete(expr string, cursor int, rootType reflect.TypeSpecifier, opts ...CompileOption) ([]Completion, error) {
+	if cursor < 0 || cursor > len(expr) {
+		return nil, fmt.Errorf("fhirpath: cursor %d is out of range of the expression", cursor)
+	}
+	var cfg compileConfig
+	if err := cfg.apply(opts...); err != nil {
+		return nil, err
+	}
+	if rootType != "" {
+		if err := WithRootType(rootType).setCompile(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	cfg.RootType = rootType
+	options, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+
+	text := expr[:cursor]
+	if inLiteral(text) {
+		return nil, nil
+	}
+	before := strings.TrimRightFunc(text, isIdentifierChar)
+	prefix := text[len(before):]
+
+	var candidates []Completion
+	if strings.HasSuffix(before, "%") {
+		candidates, err = completeVariables(options)
+	} else if match := typePosition.FindStringSubmatch(before); match != nil {
+		candidates = completeTypes(match[1])
+	} else {
+		candidates, err = completeMembers(before, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Completion
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate.Label), strings.ToLower(prefix)) {
+			result = append(result, candidate)
+		}
+	}
+	return result, nil
+}
+
+// typePosition matches text that a type specifier may follow, capturing the
+// namespace that qualifies it, if any.
+var typePosition = regexp.MustCompile(`(?:\b(?:is|as)\s+|\b(?:is|as|ofType)\s*\(\s*)(?:(FHIR|System)\s*\.\s*)?$`)
+
+// completeMembers returns the elements and functions that may be named by an
+// identifier that follows the text.
+func completeMembers(text string, opts compile.Options) ([]Completion, error) {
+	scope, ok, err := compile.ScopeAt(text, opts)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if scope.Terminologies {
+		return completeFunctions(funcs.Terminologies, scope.Focus), nil
+	}
+
+	var result []Completion
+	focus := scope.Focus.Item()
+	if scope.Root && focus.IsKnown() {
+		name, _ := strings.CutPrefix(string(focus.Name), "FHIR.")
+		result = append(result, Completion{
+			Label:         name,
+			Kind:          CompletionType,
+			Detail:        string(focus.Name),
+			Documentation: "The type of the input of the expression.",
+		})
+	}
+
+	var elements []Completion
+	seen := map[string]bool{}
+	for _, member := range focus.Members() {
+		if seen[member.Name] {
+			continue
+		}
+		seen[member.Name] = true
+		doc := fmt.Sprintf("Element of %v.", member.Owner)
+		if member.Owner != focus.Name {
+			doc = fmt.Sprintf("Element of %v, inherited from %v.", focus.Name, member.Owner)
+		}
+		elements = append(elements, Completion{
+			Label:         member.Name,
+			Kind:          CompletionElement,
+			Detail:        string(member.Type.Specifier()),
+			Documentation: doc,
+		})
+	}
+	sortCompletions(elements)
+	result = append(result, elements...)
+	return append(result, completeFunctions(opts.Functions, scope.Focus)...), nil
+}
+
+// completeFunctions returns the functions of the table that may be invoked on
+// an input of the type.
+func completeFunctions(table funcs.Table, input types.Type) []Completion {
+	var result []Completion
+	for name, fn := range table {
+		if !fn.Accepts(input) {
+			continue
+		}
+		result = append(result, Completion{
+			Label:         name,
+			Kind:          CompletionFunction,
+			Detail:        fn.Signature,
+			Documentation: fn.Doc,
+		})
+	}
+	sortCompletions(result)
+	return result
+}
+
+// variableDocs are the descriptions of the environment variables that FHIR
+// defines without a fixed value.
+var variableDocs = map[string]string{
+	envcontext.Context:       "The original node that is passed to the evaluation engine.",
+	envcontext.Resource:      "The resource that contains the original node in '%context'.",
+	envcontext.RootResource:  "The container of the resource in '%resource'.",
+	envcontext.Terminologies: "The terminology service API.",
+}
+
+// completeVariables returns the environment variables that may be referenced.
+func completeVariables(opts compile.Options) ([]Completion, error) {
+	variables, err := compile.VariableTypes(opts)
+	if err != nil {
+		return nil, err
+	}
+	var result []Completion
+	for name, typ := range variables {
+		doc, ok := variableDocs[name]
+		if value, standard := envcontext.Standard(name); standard {
+			doc, ok = fmt.Sprintf("The URL of the code system: %v", value), true
+		}
+		if !ok {
+			doc = "A declared environment variable."
+		}
+		result = append(result, Completion{
+			Label:         name,
+			Kind:          CompletionVariable,
+			Detail:        string(typ.Specifier()),
+			Documentation: doc,
+		})
+	}
+	sortCompletions(result)
+	return result, nil
+}
+
+// completeTypes returns the types of the namespace, or of every namespace if
+// it is empty.
+func completeTypes(namespace string) []Completion {
+	var result []Completion
+	for _, name := range types.Names() {
+		ns, local, _ := strings.Cut(string(name), ".")
+		if namespace != "" && ns != namespace {
+			continue
+		}
+		doc := fmt.Sprintf("A type of the %v namespace.", ns)
+		if info, ok := model.ClassInfo(local); ok && ns == "FHIR" && info.BaseType != "" {
+			doc = fmt.Sprintf("A type of the FHIR namespace, derived from %v.", info.BaseType)
+		}
+		result = append(result, Completion{
+			Label:         local,
+			Kind:          CompletionType,
+			Detail:        string(name),
+			Documentation: doc,
+		})
+	}
+	return result
+}
+
+// sortCompletions sorts the completions by label.
+func sortCompletions(completions []Completion) {
+	sort.Slice(completions, func(i, j int) bool {
+		return completions[i].Label < completions[j].Label
+	})
+}
+
+// isIdentifierChar returns whether the rune may be part of an identifier.
+func isIdentifierChar(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// inLiteral returns whether the text ends within a string, a delimited
+// identifier, or a comment, where there is nothing to complete.
+func inLiteral(text string) bool {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '\'' || c == '`':
+			quote = c
+		case strings.HasPrefix(text[i:], "//"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				return true
+			}
+			i += end
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				return true
+			}
+			i += end + 3
+		}
+	}
+	return quote != 0
+}
@@ -0,0 +1,54 @@
+/*
+Package conformance provides an interface for validating that resources conform
+to FHIR profiles, as used by the FHIRPath 'conformsTo' function.
+*/
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownProfile is an error raised when validating a resource against a
+// profile that the validator does not know.
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Validator is an interface for validating resources against FHIR profiles.
+type Validator interface {
+	// ConformsTo reports whether the resource conforms to the profile that is
+	// identified by the canonical url. If the profile is not known, an error
+	// wrapping ErrUnknownProfile is returned.
+	ConformsTo(ctx context.Context, resource any, url string) (bool, error)
+
+	isValidator()
+}
+
+// ValidatorFunc is a convenience type that implements the [Validator]
+// abstraction.
+//
+// This enables using normal function definitions to handle custom validation.
+type ValidatorFunc func(ctx context.Context, resource any, url string) (bool, error)
+
+// ConformsTo calls the underlying function to validate the resource.
+func (f ValidatorFunc) ConformsTo(ctx context.Context, resource any, url string) (bool, error) {
+	return f(ctx, resource, url)
+}
+
+func (ValidatorFunc) isValidator() {}
+
+var _ Validator = (*ValidatorFunc)(nil)
+
+// BaseValidator is an embeddable type that implements the [Validator]
+// interface.
+//
+// This type must be embedded into a type that wishes to implement the
+// [Validator] interface, as it will enable forward-compatibility, and
+// implement unexported functions that are required in the interface.
+type BaseValidator struct{}
+
+func (BaseValidator) ConformsTo(_ context.Context, _ any, url string) (bool, error) {
+	return false, fmt.Errorf("%w '%v': no validator configured", ErrUnknownProfile, url)
+}
+
+func (BaseValidator) isValidator() {}
@@ -0,0 +1,333 @@
+/*
+Package snapshot provides a [conformance.Validator] that validates resources
+against the snapshots of StructureDefinitions that are held in memory.
+*/
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/structuredefinition"
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/conformance"
+	"github.com/friendly-fhir/go-fhirpath/internal/compile"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/funcs"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// Validator is a [conformance.Validator] that validates resources against the
+// snapshots of StructureDefinitions. For each element of a snapshot, this
+// validates:
+//
+//   - The cardinality of the element, within each instance of its parent.
+//   - The type of the element, if it is a choice ([x]) element.
+//   - The fixed[x] or pattern[x] value of the element.
+//   - The FHIRPath expressions of the error constraints on the element.
+//
+// Slices, and elements defined by a contentReference, are not validated.
+// Constraints whose expressions use features that this module does not
+// implement are also not validated; [Validator.Skipped] reports their keys.
+type Validator struct {
+	profiles map[string]*profile
+	conformance.BaseValidator
+}
+
+// New returns a [Validator] for the StructureDefinitions, which must each have
+// a url and a snapshot. A profile may be identified by its url, or by its url
+// and version as 'url|version'.
+func New(definitions ...*structuredefinition.StructureDefinition) (*Validator, error) {
+	v := &Validator{profiles: map[string]*profile{}}
+	for _, sd := range definitions {
+		if sd.URL == nil || sd.URL.Value == "" {
+			return nil, fmt.Errorf("snapshot: StructureDefinition '%v' has no url", sd.ID)
+		}
+		url := sd.URL.Value
+		if sd.Snapshot == nil {
+			return nil, fmt.Errorf("snapshot: StructureDefinition '%v' has no snapshot", url)
+		}
+		p, err := newProfile(sd)
+		if err != nil {
+			return nil, err
+		}
+		v.profiles[url] = p
+		if sd.Version != nil && sd.Version.Value != "" {
+			v.profiles[url+"|"+sd.Version.Value] = p
+		}
+	}
+	return v, nil
+}
+
+// ConformsTo reports whether the resource conforms to the profile identified
+// by the url.
+func (v *Validator) ConformsTo(ctx context.Context, resource any, url string) (bool, error) {
+	p, ok := v.profiles[url]
+	if !ok {
+		return false, fmt.Errorf("%w '%v'", conformance.ErrUnknownProfile, url)
+	}
+	return p.validate(ctx, resource)
+}
+
+// Skipped returns the keys of the constraints of the profile identified by the
+// url that are not validated, because their expressions use features that this
+// module does not implement.
+func (v *Validator) Skipped(url string) ([]string, error) {
+	p, ok := v.profiles[url]
+	if !ok {
+		return nil, fmt.Errorf("%w '%v'", conformance.ErrUnknownProfile, url)
+	}
+	return p.skipped, nil
+}
+
+var _ conformance.Validator = (*Validator)(nil)
+
+// profile is the compiled form of a StructureDefinition snapshot.
+type profile struct {
+	resourceType string
+	elements     []*element
+	skipped      []string
+}
+
+// element is the compiled form of a single ElementDefinition.
+type element struct {
+	parent      []string
+	name        string
+	min         int
+	max         int // -1 if unbounded
+	choice      bool
+	types       []string
+	fixed       fhir.Element
+	pattern     fhir.Element
+	constraints []expr.Expression
+	skipped     []string
+}
+
+func newProfile(sd *structuredefinition.StructureDefinition) (*profile, error) {
+	p := &profile{}
+	if sd.Type != nil {
+		p.resourceType = sd.Type.Value
+	}
+	for _, ed := range sd.Snapshot.Element {
+		if ed.SliceName != nil || strings.Contains(ed.ID, ":") || ed.ContentReference != nil || ed.Path == nil {
+			continue
+		}
+		e, err := newElement(ed)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: %v: element '%v': %w", sd.URL.Value, ed.Path.Value, err)
+		}
+		p.elements = append(p.elements, e)
+		p.skipped = append(p.skipped, e.skipped...)
+	}
+	return p, nil
+}
+
+func newElement(ed *fhir.ElementDefinition) (*element, error) {
+	segments := strings.Split(ed.Path.Value, ".")
+	e := &element{
+		max:     -1,
+		fixed:   ed.Fixed,
+		pattern: ed.Pattern,
+	}
+	if len(segments) > 1 {
+		e.parent = segments[1 : len(segments)-1]
+		e.name, e.choice = strings.CutSuffix(segments[len(segments)-1], "[x]")
+	}
+	if ed.Min != nil {
+		e.min = int(ed.Min.Value)
+	}
+	if ed.Max != nil && ed.Max.Value != "*" {
+		max, err := strconv.Atoi(ed.Max.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max '%v'", ed.Max.Value)
+		}
+		e.max = max
+	}
+	for _, t := range ed.Type {
+		if t.Code != nil {
+			e.types = append(e.types, t.Code.Value)
+		}
+	}
+	for _, constraint := range ed.Constraint {
+		if constraint.Expression == nil || constraint.Severity == nil || constraint.Severity.Value != "error" {
+			continue
+		}
+		var key string
+		if constraint.Key != nil {
+			key = constraint.Key.Value
+		}
+		expression, _, err := compile.Compile(constraint.Expression.Value, compile.Options{Functions: funcs.N2})
+		if errors.Is(err, compile.ErrUnimplemented) {
+			e.skipped = append(e.skipped, key)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("constraint '%v': %w", key, err)
+		}
+		e.constraints = append(e.constraints, expression)
+	}
+	return e, nil
+}
+
+// validate validates the resource against every element of the profile.
+func (p *profile) validate(ctx context.Context, resource any) (bool, error) {
+	if p.resourceType != "" && model.TypeName(resource) != p.resourceType {
+		return false, nil
+	}
+	for _, e := range p.elements {
+		ok, err := e.validate(ctx, resource)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// node is an instance of an element, along with the resource that contains it
+// and the root of that resource, which define the '%resource' and
+// '%rootResource' variables of its constraints.
+type node struct {
+	value    any
+	resource any
+	root     any
+}
+
+// child returns the node of a child of this node with the name. A child that
+// is itself a resource is its own '%resource', and is its own root unless it
+// is contained.
+func (n node) child(name string, value any) node {
+	result := node{value: value, resource: n.resource, root: n.root}
+	if _, ok := value.(fhir.Resource); ok {
+		result.resource = value
+		if name != "contained" {
+			result.root = value
+		}
+	}
+	return result
+}
+
+// children returns the nodes of the children of this node with the name.
+func (n node) children(name string) []node {
+	var result []node
+	for _, value := range model.Children(n.value, name) {
+		result = append(result, n.child(name, value))
+	}
+	return result
+}
+
+// validate validates every instance of the element within the resource.
+func (e *element) validate(ctx context.Context, resource any) (bool, error) {
+	parents := []node{{value: resource, resource: resource, root: resource}}
+	for _, name := range e.parent {
+		var children []node
+		for _, parent := range parents {
+			children = append(children, parent.children(name)...)
+		}
+		parents = children
+	}
+
+	for _, parent := range parents {
+		values := []node{parent}
+		if e.name != "" {
+			values = parent.children(e.name)
+			if len(values) < e.min || (e.max >= 0 && len(values) > e.max) {
+				return false, nil
+			}
+		}
+		for _, value := range values {
+			ok, err := e.validateValue(ctx, value)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// validateValue validates a single instance of the element. Its constraints
+// are evaluated with the instance as '%context'.
+func (e *element) validateValue(ctx context.Context, n node) (bool, error) {
+	value := n.value
+	if e.choice && !hasType(value, e.types) {
+		return false, nil
+	}
+	if e.fixed != nil && !matches(value, e.fixed, true) {
+		return false, nil
+	}
+	if e.pattern != nil && !matches(value, e.pattern, false) {
+		return false, nil
+	}
+	if len(e.constraints) == 0 {
+		return true, nil
+	}
+	ctx = envcontext.WithNode(ctx, value, n.resource, n.root)
+	for _, constraint := range e.constraints {
+		result, err := constraint.Evaluate(ctx, collection.Collection{value})
+		if err != nil {
+			return false, err
+		}
+		if result, err := result.SingletonBoolean(); err == nil && result.Equal(collection.False) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// hasType returns whether the value is of one of the FHIR types.
+func hasType(value any, types []string) bool {
+	name := model.TypeName(value)
+	if name == "" {
+		return true
+	}
+	for _, t := range types {
+		if strings.EqualFold(t, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns whether the value matches the pattern, such that every
+// element of the pattern is present in the value with a matching value. If
+// exact, the value must also not have any element that the pattern does not.
+func matches(value, pattern any, exact bool) bool {
+	if want, ok := model.PrimitiveValue(pattern); ok {
+		got, _ := model.PrimitiveValue(value)
+		return got != nil && system.Equal(got, want)
+	}
+	if model.TypeName(value) != model.TypeName(pattern) {
+		return false
+	}
+	for _, field := range model.Fields(reflect.TypeOf(pattern)) {
+		if field.Name == "id" {
+			continue
+		}
+		want := model.Children(pattern, field.Name)
+		got := model.Children(value, field.Name)
+		if exact && len(got) != len(want) {
+			return false
+		}
+		for _, w := range want {
+			if !containsMatch(got, w, exact) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsMatch(values []any, pattern any, exact bool) bool {
+	for _, value := range values {
+		if matches(value, pattern, exact) {
+			return true
+		}
+	}
+	return false
+}
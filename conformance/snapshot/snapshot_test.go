@@ -0,0 +1,327 @@
+package snapshot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/observation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/structuredefinition"
+	"github.com/friendly-fhir/go-fhirpath/conformance"
+	"github.com/friendly-fhir/go-fhirpath/conformance/snapshot"
+	"github.com/google/go-cmp/cmp"
+)
+
+const profileURL = "http://example.org/StructureDefinition/test-patient"
+
+func elementOf(path string, min uint32, max string) *fhir.ElementDefinition {
+	return &fhir.ElementDefinition{
+		ID:   path,
+		Path: &fhir.String{Value: path},
+		Min:  &fhir.UnsignedInt{Value: min},
+		Max:  &fhir.String{Value: max},
+	}
+}
+
+func constraintOf(key, severity, expression string) *fhir.ElementDefinitionConstraint {
+	return &fhir.ElementDefinitionConstraint{
+		Key:        &fhir.ID{Value: key},
+		Severity:   &fhir.Code{Value: severity},
+		Expression: &fhir.String{Value: expression},
+	}
+}
+
+func testProfile() *structuredefinition.StructureDefinition {
+	active := elementOf("Patient.active", 1, "1")
+	gender := elementOf("Patient.gender", 0, "1")
+	gender.Fixed = &fhir.Code{Value: "female"}
+	name := elementOf("Patient.name", 1, "*")
+	name.Constraint = []*fhir.ElementDefinitionConstraint{
+		constraintOf("tst-1", "error", "family != 'Unknown'"),
+		constraintOf("tst-2", "warning", "family = 'Never'"),
+		constraintOf("tst-3", "error", "family.exists()"),
+	}
+	official := elementOf("Patient.name", 1, "1")
+	official.ID = "Patient.name:official"
+	official.SliceName = &fhir.String{Value: "official"}
+	identifier := elementOf("Patient.identifier", 0, "*")
+	identifier.Pattern = &fhir.Identifier{System: &fhir.URI{Value: "http://example.org/mrn"}}
+	deceased := elementOf("Patient.deceased[x]", 0, "1")
+	deceased.Type = []*fhir.ElementDefinitionType{
+		{Code: &fhir.URI{Value: "boolean"}},
+	}
+	family := elementOf("Patient.name.family", 0, "1")
+	telecom := elementOf("Patient.telecom", 0, "1")
+
+	return &structuredefinition.StructureDefinition{
+		URL:     &fhir.URI{Value: profileURL},
+		Version: &fhir.String{Value: "1.0.0"},
+		Type:    &fhir.URI{Value: "Patient"},
+		Snapshot: &structuredefinition.StructureDefinitionSnapshot{
+			Element: []*fhir.ElementDefinition{
+				elementOf("Patient", 0, "*"),
+				active, gender, name, official, identifier, deceased, family, telecom,
+			},
+		},
+	}
+}
+
+func TestValidatorConformsTo(t *testing.T) {
+	conforming := func() *patient.Patient {
+		return &patient.Patient{
+			Active: &fhir.Boolean{Value: true},
+			Name:   []*fhir.HumanName{{Family: &fhir.String{Value: "Doe"}}},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		url      string
+		resource any
+		want     bool
+	}{
+		{"Conforming resource", profileURL, conforming(), true},
+		{"Versioned url", profileURL + "|1.0.0", conforming(), true},
+		{"Different resource type", profileURL, &observation.Observation{}, false},
+		{"Missing required element", profileURL, func() any {
+			p := conforming()
+			p.Active = nil
+			return p
+		}(), false},
+		{"Unbounded elements", profileURL, func() any {
+			p := conforming()
+			p.Name = append(p.Name, &fhir.HumanName{Given: []*fhir.String{{Value: "Jane"}}})
+			return p
+		}(), true},
+		{"Too many elements", profileURL, func() any {
+			p := conforming()
+			p.Telecom = []*fhir.ContactPoint{{}, {}}
+			return p
+		}(), false},
+		{"Matching fixed value", profileURL, func() any {
+			p := conforming()
+			p.Gender = &fhir.Code{Value: "female"}
+			return p
+		}(), true},
+		{"Different fixed value", profileURL, func() any {
+			p := conforming()
+			p.Gender = &fhir.Code{Value: "male"}
+			return p
+		}(), false},
+		{"Matching pattern", profileURL, func() any {
+			p := conforming()
+			p.Identifier = []*fhir.Identifier{{
+				System: &fhir.URI{Value: "http://example.org/mrn"},
+				Value:  &fhir.String{Value: "12345"},
+			}}
+			return p
+		}(), true},
+		{"Different pattern", profileURL, func() any {
+			p := conforming()
+			p.Identifier = []*fhir.Identifier{{
+				System: &fhir.URI{Value: "http://example.org/other"},
+				Value:  &fhir.String{Value: "12345"},
+			}}
+			return p
+		}(), false},
+		{"Allowed choice type", profileURL, func() any {
+			p := conforming()
+			p.Deceased = &fhir.Boolean{Value: false}
+			return p
+		}(), true},
+		{"Disallowed choice type", profileURL, func() any {
+			p := conforming()
+			p.Deceased = &fhir.DateTime{Value: "2020-01-01"}
+			return p
+		}(), false},
+		{"Failing error constraint", profileURL, func() any {
+			p := conforming()
+			p.Name[0].Family = &fhir.String{Value: "Unknown"}
+			return p
+		}(), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator, err := snapshot.New(testProfile())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			got, err := validator.ConformsTo(context.Background(), tc.resource, tc.url)
+			if err != nil {
+				t.Fatalf("ConformsTo(%q) error = %v", tc.url, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("ConformsTo(%q) = %v; want %v", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidatorConformsTo_EnvironmentVariables(t *testing.T) {
+	name := elementOf("Patient.name", 0, "*")
+	name.Constraint = []*fhir.ElementDefinitionConstraint{
+		constraintOf("env-1", "error", "%context.family = 'Doe'"),
+		constraintOf("env-2", "error", "%resource.active = false"),
+		constraintOf("env-3", "error", "%rootResource.active = false"),
+	}
+	contained := elementOf("Patient.contained", 0, "*")
+	contained.Constraint = []*fhir.ElementDefinitionConstraint{
+		constraintOf("env-4", "error", "%resource.active = true"),
+		constraintOf("env-5", "error", "%rootResource.active = false"),
+	}
+	profile := &structuredefinition.StructureDefinition{
+		URL:  &fhir.URI{Value: profileURL},
+		Type: &fhir.URI{Value: "Patient"},
+		Snapshot: &structuredefinition.StructureDefinitionSnapshot{
+			Element: []*fhir.ElementDefinition{elementOf("Patient", 0, "*"), name, contained},
+		},
+	}
+	validator, err := snapshot.New(profile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resource := &patient.Patient{
+		Active:    &fhir.Boolean{Value: false},
+		Name:      []*fhir.HumanName{{Family: &fhir.String{Value: "Doe"}}},
+		Contained: []fhir.Resource{&patient.Patient{Active: &fhir.Boolean{Value: true}}},
+	}
+
+	got, err := validator.ConformsTo(context.Background(), resource, profileURL)
+	if err != nil {
+		t.Fatalf("ConformsTo() error = %v", err)
+	}
+
+	if want := true; got != want {
+		t.Errorf("ConformsTo() = %v; want %v", got, want)
+	}
+}
+
+func TestValidatorConformsTo_ProfileInvariants(t *testing.T) {
+	// The invariants of the Narrative datatype of FHIR R4.
+	narrative := elementOf("Narrative", 0, "*")
+	narrative.Constraint = []*fhir.ElementDefinitionConstraint{
+		constraintOf("ele-1", "error", "hasValue() or (children().count() > id.count())"),
+	}
+	div := elementOf("Narrative.div", 1, "1")
+	div.Constraint = []*fhir.ElementDefinitionConstraint{
+		constraintOf("txt-1", "error", "htmlChecks()"),
+		constraintOf("txt-2", "error", "htmlChecks()"),
+	}
+	profile := &structuredefinition.StructureDefinition{
+		URL:  &fhir.URI{Value: profileURL},
+		Type: &fhir.URI{Value: "Narrative"},
+		Snapshot: &structuredefinition.StructureDefinitionSnapshot{
+			Element: []*fhir.ElementDefinition{narrative, div},
+		},
+	}
+	validator, err := snapshot.New(profile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got, _ := validator.Skipped(profileURL); !cmp.Equal(got, []string{"ele-1"}) {
+		t.Errorf("Skipped() = %v; want [ele-1]", got)
+	}
+
+	testCases := []struct {
+		name string
+		div  string
+		want bool
+	}{
+		{"Conforming narrative", `<div xmlns="http://www.w3.org/1999/xhtml">Jane <b>Doe</b></div>`, true},
+		{"Narrative with script", `<div xmlns="http://www.w3.org/1999/xhtml"><script/>x</div>`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resource := &fhir.Narrative{Div: &fhir.XHTML{Value: tc.div}}
+
+			got, err := validator.ConformsTo(context.Background(), resource, profileURL)
+			if err != nil {
+				t.Fatalf("ConformsTo() error = %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("ConformsTo() = %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidatorSkipped(t *testing.T) {
+	validator, err := snapshot.New(testProfile())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got, err := validator.Skipped(profileURL)
+	if err != nil {
+		t.Fatalf("Skipped() error = %v", err)
+	}
+
+	if diff := cmp.Diff(got, []string{"tst-3"}); diff != "" {
+		t.Errorf("Skipped() mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestValidatorConformsTo_UnknownProfile_ReturnsError(t *testing.T) {
+	validator, err := snapshot.New(testProfile())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = validator.ConformsTo(context.Background(), &patient.Patient{}, "http://example.org/unknown")
+
+	if got, want := err, conformance.ErrUnknownProfile; !errors.Is(got, want) {
+		t.Errorf("ConformsTo() error = %v; want %v", got, want)
+	}
+}
+
+// definitionOf returns a StructureDefinition whose only element has the
+// constraint.
+func definitionOf(constraint *fhir.ElementDefinitionConstraint) *structuredefinition.StructureDefinition {
+	name := elementOf("Patient.name", 0, "*")
+	name.Constraint = []*fhir.ElementDefinitionConstraint{constraint}
+	return &structuredefinition.StructureDefinition{
+		URL: &fhir.URI{Value: profileURL},
+		Snapshot: &structuredefinition.StructureDefinitionSnapshot{
+			Element: []*fhir.ElementDefinition{name},
+		},
+	}
+}
+
+func TestNew_InvalidDefinition_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		definition *structuredefinition.StructureDefinition
+	}{
+		{"Missing url", &structuredefinition.StructureDefinition{
+			Snapshot: &structuredefinition.StructureDefinitionSnapshot{},
+		}},
+		{"Missing snapshot", &structuredefinition.StructureDefinition{
+			URL: &fhir.URI{Value: profileURL},
+		}},
+		{"Invalid max", &structuredefinition.StructureDefinition{
+			URL: &fhir.URI{Value: profileURL},
+			Snapshot: &structuredefinition.StructureDefinitionSnapshot{
+				Element: []*fhir.ElementDefinition{elementOf("Patient.active", 0, "many")},
+			},
+		}},
+		{"Constraint with syntax error", definitionOf(constraintOf("tst-1", "error", "family = "))},
+		{"Constraint with unknown function", definitionOf(constraintOf("tst-1", "error", "family.unknown()"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := snapshot.New(tc.definition)
+
+			if err == nil {
+				t.Errorf("New() error = nil; want error")
+			}
+		})
+	}
+}
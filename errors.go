@@ -4,13 +4,96 @@ import (
 	"errors"
 
 	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/conformance"
+	"github.com/friendly-fhir/go-fhirpath/internal/compile"
+	"github.com/friendly-fhir/go-fhirpath/internal/funcs"
+	"github.com/friendly-fhir/go-fhirpath/system"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
 )
 
+// CompileError is an error that occurred while compiling a FHIRPath
+// expression, annotated with the position in the source text that caused it.
+type CompileError = compile.Error
+
 var (
 	// ErrUnimplemented is returned when a feature is not yet implemented.
-	ErrUnimplemented = errors.New("unimplemented")
+	ErrUnimplemented = compile.ErrUnimplemented
+
+	// ErrSyntax is returned when compiling an expression that is not
+	// syntactically valid FHIRPath.
+	ErrSyntax = compile.ErrSyntax
+
+	// ErrUnknownFunction is returned when compiling an expression that invokes
+	// a function that is not defined for the configured FHIRPath version.
+	ErrUnknownFunction = compile.ErrUnknownFunction
+
+	// ErrUndeclaredVariable is returned when compiling an expression that
+	// references an environment variable that is neither declared with
+	// [DeclareVariable] nor defined by FHIR.
+	ErrUndeclaredVariable = compile.ErrUndeclaredVariable
+
+	// ErrUnknownElement is returned when compiling an expression with
+	// [WithRootType] that navigates into an element that its input does not
+	// define, such as 'Patient.nmae'.
+	ErrUnknownElement = compile.ErrUnknownElement
+
+	// ErrUnknownType is returned when compiling an expression with
+	// [WithRootType] that names a type that is defined in neither the FHIR
+	// nor the System namespace.
+	ErrUnknownType = compile.ErrUnknownType
+
+	// ErrTypeMismatch is returned when compiling an expression with
+	// [WithRootType] that uses a value where its type can never apply, such as
+	// comparing a code to an Integer with 'gender = 1'.
+	ErrTypeMismatch = compile.ErrTypeMismatch
+
+	// ErrInvalidSignature is returned when compiling with a custom function,
+	// added with [AddFunc], whose Go signature cannot be invoked by FHIRPath.
+	ErrInvalidSignature = funcs.ErrInvalidSignature
+
+	// ErrRecursiveFunction is returned when compiling with a function defined
+	// with [DefineFunction] that invokes itself, either directly or through
+	// other functions.
+	ErrRecursiveFunction = compile.ErrRecursion
+
+	// ErrNotInResource is returned when evaluating an expression with
+	// [InResource] against a node that is not within the resource.
+	ErrNotInResource = errors.New("node not within resource")
+
+	// ErrMissingVariable is returned when evaluating an expression without a
+	// value for a variable that it was compiled with [DeclareVariable] for.
+	ErrMissingVariable = errors.New("missing variable")
+
+	// ErrVariableType is returned when evaluating an expression with a variable
+	// whose value is not of the type that it was declared with.
+	ErrVariableType = errors.New("variable type mismatch")
+
+	// ErrNotComparable is an error raised when comparing values whose types
+	// cannot be compared to one another.
+	ErrNotComparable = system.ErrNotComparable
+
+	// ErrInvalidOperands is an error raised when an arithmetic operator is
+	// applied to values whose types it is not defined for.
+	ErrInvalidOperands = system.ErrInvalidOperands
+
+	// ErrOverflow is an error raised when the result of an integer arithmetic
+	// operation does not fit within the range of its type.
+	ErrOverflow = system.ErrOverflow
 
 	// ErrNotSingleton is an error raised if a collection is not a singleton, but
 	// one was expected.
 	ErrNotSingleton = collection.ErrNotSingleton
+
+	// ErrUnknownProfile is an error raised when 'conformsTo' is evaluated for a
+	// profile that the configured validator does not know.
+	ErrUnknownProfile = conformance.ErrUnknownProfile
+
+	// ErrUnknownValueSet is an error raised when 'memberOf' is evaluated for a
+	// value set that the configured terminology does not know.
+	ErrUnknownValueSet = terminology.ErrUnknownValueSet
+
+	// ErrUnknownCodeSystem is an error raised when 'subsumes' or 'subsumedBy'
+	// is evaluated for a code system that the configured terminology does not
+	// know.
+	ErrUnknownCodeSystem = terminology.ErrUnknownCodeSystem
 )
@@ -1,23 +1,38 @@
 package fhirpath
 
 import (
+	"fmt"
+	"math"
 	"time"
 
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhirpath/collection"
+
+	"github.com/friendly-fhir/go-fhirpath/conformance"
 	"github.com/friendly-fhir/go-fhirpath/resolver"
+	"github.com/friendly-fhir/go-fhirpath/system"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
 	"github.com/friendly-fhir/go-fhirpath/tracer"
 )
 
 type Tracer = tracer.Tracer
 type Resolver = resolver.Resolver
+type ProfileValidator = conformance.Validator
+type Terminology = terminology.Terminology
 
 type EvalOption interface {
 	setEvaluate(*evaluateConfig) error
 }
 
 type evaluateConfig struct {
-	Time     time.Time
-	Tracer   Tracer
-	Resolver Resolver
+	Time             time.Time
+	Tracer           Tracer
+	Resolver         Resolver
+	StrictResolve    bool
+	ProfileValidator ProfileValidator
+	Terminology      Terminology
+	Variables        map[string]Collection
+	Resource         fhir.Resource
 }
 
 func (c *evaluateConfig) apply(opts ...EvalOption) error {
@@ -65,3 +80,129 @@ func WithResolver(resolver resolver.Resolver) EvalOption {
 		return nil
 	})
 }
+
+// StrictResolve returns an [EvalOption] that configures the 'resolve()'
+// function to fail the evaluation when the resolver fails to resolve a
+// reference.
+//
+// By default, without this specified, references that fail to resolve are
+// omitted from the result, as are references that are not found.
+func StrictResolve() EvalOption {
+	return evaluateOption(func(cfg *evaluateConfig) error {
+		cfg.StrictResolve = true
+		return nil
+	})
+}
+
+// WithProfileValidator returns an [EvalOption] that configures the evaluator to
+// use the specified validator for the 'conformsTo' function.
+//
+// By default, without this specified, 'conformsTo' fails for every profile.
+func WithProfileValidator(validator conformance.Validator) EvalOption {
+	return evaluateOption(func(cfg *evaluateConfig) error {
+		cfg.ProfileValidator = validator
+		return nil
+	})
+}
+
+// WithTerminology returns an [EvalOption] that configures the evaluator to use
+// the specified terminology service for the 'memberOf', 'subsumes', and
+// 'subsumedBy' functions.
+//
+// By default, without this specified, these functions fail for every value set
+// and code system.
+func WithTerminology(terminology terminology.Terminology) EvalOption {
+	return evaluateOption(func(cfg *evaluateConfig) error {
+		cfg.Terminology = terminology
+		return nil
+	})
+}
+
+// InResource returns an [EvalOption] that evaluates the expression against a
+// node within the resource, such as an element of a Patient, or a resource in
+// a Bundle entry or in the 'contained' of another. The node remains the
+// '%context' of the evaluation, but '%resource' is the resource that most
+// closely contains it, and '%rootResource' is the container of that resource.
+// Contained references, such as '#id', are resolved against '%rootResource'.
+//
+// The node, or each of its items if it is a [Collection], must be a FHIR
+// resource or element within the resource, as found by identity; otherwise an
+// error wrapping [ErrNotInResource] is returned. Variables set with
+// [WithVariable] take precedence over these.
+//
+// By default, without this specified, the node is its own '%resource' and
+// '%rootResource' if it is a resource, and these are undefined otherwise.
+func InResource(resource fhir.Resource) EvalOption {
+	return evaluateOption(func(cfg *evaluateConfig) error {
+		cfg.Resource = resource
+		return nil
+	})
+}
+
+// WithVariable returns an [EvalOption] that sets the value of the environment
+// variable with the specified name, which is referenced as '%name'. The
+// variable must be declared with [DeclareVariable] when the expression is
+// compiled, and the value must be of the declared type.
+//
+// The value may be a [Collection], a single FHIR or System value, or a Go
+// string, bool, int, int32, int64, or float64, which is converted into its
+// System type. A nil value is the empty collection.
+//
+// Variables that are defined by FHIR, such as '%resource', may also be set
+// this way to override their default values.
+func WithVariable(name string, value any) EvalOption {
+	return evaluateOption(func(cfg *evaluateConfig) error {
+		values, err := variableOf(value)
+		if err != nil {
+			return fmt.Errorf("fhirpath: variable '%%%v': %w", name, err)
+		}
+		if cfg.Variables == nil {
+			cfg.Variables = map[string]Collection{}
+		}
+		cfg.Variables[name] = values
+		return nil
+	})
+}
+
+// variableOf converts the value of a variable into a collection.
+func variableOf(value any) (Collection, error) {
+	switch v := value.(type) {
+	case nil:
+		return collection.Empty, nil
+	case Collection:
+		for _, item := range v {
+			if !isValue(item) {
+				return nil, fmt.Errorf("unsupported value type %T", item)
+			}
+		}
+		return v, nil
+	case string:
+		return collection.Of(system.String(v)), nil
+	case bool:
+		return collection.Of(system.Boolean(v)), nil
+	case int:
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return collection.Of(system.Integer64(v)), nil
+		}
+		return collection.Of(system.Integer(v)), nil
+	case int32:
+		return collection.Of(system.Integer(v)), nil
+	case int64:
+		return collection.Of(system.Integer64(v)), nil
+	case float64:
+		return collection.Of(system.NewDecimal(v)), nil
+	}
+	if !isValue(value) {
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+	return collection.Of(value), nil
+}
+
+// isValue returns whether the value may be held in a collection.
+func isValue(value any) bool {
+	switch value.(type) {
+	case fhir.Resource, fhir.Element, system.Any:
+		return true
+	}
+	return false
+}
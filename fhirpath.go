@@ -3,8 +3,20 @@ package fhirpath
 import (
 	"context"
 	"fmt"
+	stdreflect "reflect"
+	"sort"
+	"strings"
 
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
 	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/compile"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/evalcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/namespace"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
 	"github.com/friendly-fhir/go-fhirpath/system"
 )
 
@@ -13,20 +25,37 @@ type Collection = collection.Collection
 
 // Path represents a compiled FHIRPath expression.
 type Path struct {
-	path string
+	path      string
+	expr      expr.Expression
+	typ       types.Type
+	variables map[string]reflect.TypeSpecifier
 }
 
 // Compile compiles the FHIRPath expression and returns a Path object. If the
 // expression is invalid, an error is returned.
 //
-// Compilation will always use the latest version of the FHIRPath language,
-// but may be configured with options to enable other language features.
+// Compilation uses the normative N1 version of the FHIRPath language by
+// default, but may be configured with options to enable other language
+// features, such as [N2].
 func Compile(path string, opts ...CompileOption) (*Path, error) {
 	var cfg compileConfig
 	if err := cfg.apply(opts...); err != nil {
 		return nil, err
 	}
-	return nil, ErrUnimplemented
+	options, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+	expression, typ, err := compile.Compile(path, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Path{
+		path:      path,
+		expr:      expression,
+		typ:       typ,
+		variables: cfg.Variables,
+	}, nil
 }
 
 // MustCompile is a convenience function that compiles the FHIRPath expression
@@ -39,16 +68,140 @@ func MustCompile(path string, opts ...CompileOption) *Path {
 	return result
 }
 
+// Type returns the inferred type of the result of the expression, including its
+// cardinality -- e.g. 'List<FHIR.HumanName>' for 'Patient.name', or
+// 'System.Boolean' for 'Patient.active = true'. The types of elements are
+// inferred from the type declared with [WithRootType]; where a type cannot be
+// inferred, it is reported as 'System.Any'.
+func (p *Path) Type() reflect.TypeSpecifier {
+	return p.typ.Specifier()
+}
+
 // Eval evaluates the FHIRPath expression and returns the result as a
 // collection of values. If the expression is invalid, an error is returned.
 // The resource argument is the FHIR resource to evaluate the expression against.
+//
+// The resource is the '%context' of the evaluation and, if it is a FHIR
+// resource, also its '%resource' and '%rootResource', unless it is a node
+// within another resource given with [InResource]. Variables set with
+// [WithVariable] take precedence over these. Before the expression is
+// evaluated, an error wrapping [ErrMissingVariable] is returned if a declared
+// variable has no value, and one wrapping [ErrVariableType] if its value is
+// not of its declared type.
 func (p *Path) Eval(ctx context.Context, resource any, opts ...EvalOption) (Collection, error) {
 	var cfg evaluateConfig
 	if err := cfg.apply(opts...); err != nil {
 		return nil, err
 	}
+	input := inputOf(resource)
+	if err := p.checkVariables(cfg.Variables); err != nil {
+		return nil, err
+	}
+
+	root := resource
+	if cfg.Resource != nil {
+		container, rootResource, err := locate(cfg.Resource, input)
+		if err != nil {
+			return nil, err
+		}
+		root = rootResource
+		ctx = envcontext.WithNode(ctx, resource, container, rootResource)
+	}
+
+	ctx = evalcontext.With(ctx, &evalcontext.Config{
+		Root:             root,
+		Resolver:         cfg.Resolver,
+		StrictResolve:    cfg.StrictResolve,
+		ProfileValidator: cfg.ProfileValidator,
+		Terminology:      cfg.Terminology,
+	})
+	if len(cfg.Variables) > 0 {
+		values := make(map[string]any, len(cfg.Variables))
+		for name, value := range cfg.Variables {
+			values[name] = value
+		}
+		ctx = envcontext.WithEntries(ctx, values)
+	}
+	ctx = envcontext.WithFocus(ctx, resource)
+	return p.expr.Evaluate(ctx, input)
+}
 
-	return nil, ErrUnimplemented
+// checkVariables checks that every declared variable has a value, and that
+// its value is of its declared type, of a type derived from it, or of a type
+// that is implicitly converted into it -- such as an Integer for a Decimal.
+func (p *Path) checkVariables(values map[string]Collection) error {
+	names := make([]string, 0, len(p.variables))
+	for name := range p.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		typ := p.variables[name]
+		value, ok := values[name]
+		if !ok {
+			return fmt.Errorf("%w: variable '%%%v' is declared as %v", ErrMissingVariable, name, typ)
+		}
+		declared, _ := types.Parse(typ)
+		if declared.Name == "System.Any" {
+			continue
+		}
+		for _, item := range value {
+			if !isAssignable(item, declared) {
+				return fmt.Errorf("%w: variable '%%%v' has a value of type %v; want %v", ErrVariableType, name, typeNameOf(item), typ)
+			}
+		}
+	}
+	return nil
+}
+
+// isAssignable returns whether the item may be the value of a variable of the
+// declared type.
+func isAssignable(item any, declared types.Type) bool {
+	if model.IsType(item, string(declared.Name)) {
+		return true
+	}
+	if !strings.HasPrefix(string(declared.Name), "System.") {
+		return false
+	}
+	return types.Type{Name: typeNameOf(item)}.AssignableTo(declared)
+}
+
+// locate finds the items of the input within the resource, and returns the
+// resource and root resource that contain them, which must be the same for
+// every item.
+func locate(resource fhir.Resource, input Collection) (any, any, error) {
+	container, rootResource := any(resource), any(resource)
+	for i, item := range input {
+		itemContainer, itemRoot, ok := model.Locate(resource, item)
+		if !ok {
+			return nil, nil, fmt.Errorf("fhirpath: %w: %T", ErrNotInResource, item)
+		}
+		if i > 0 && (itemContainer != container || itemRoot != rootResource) {
+			return nil, nil, fmt.Errorf("fhirpath: %w: items are within different resources", ErrNotInResource)
+		}
+		container, rootResource = itemContainer, itemRoot
+	}
+	return container, rootResource, nil
+}
+
+// typeNameOf returns the qualified name of the type of the value.
+func typeNameOf(value any) reflect.TypeSpecifier {
+	t := stdreflect.TypeOf(value)
+	if ns := namespace.Select(t, namespace.R4, namespace.System); ns != nil {
+		return ns.QualifiedName(t)
+	}
+	return reflect.TypeSpecifier(t.String())
+}
+
+// inputOf returns the input collection for the resource being evaluated.
+func inputOf(resource any) Collection {
+	switch v := resource.(type) {
+	case nil:
+		return collection.Empty
+	case Collection:
+		return v
+	}
+	return Collection{resource}
 }
 
 // MustEval is a convenience function that evaluates the FHIRPath expression
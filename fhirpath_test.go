@@ -0,0 +1,2172 @@
+package fhirpath_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/bundle"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/codesystem"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/conceptmap"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/encounter"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/observation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/organization"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/practitioner"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/valueset"
+	"github.com/friendly-fhir/go-fhirpath"
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/conformance"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+	"github.com/friendly-fhir/go-fhirpath/resolver/resolvertest"
+	"github.com/friendly-fhir/go-fhirpath/system"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompile_InvalidSyntax_ReturnsCompileError(t *testing.T) {
+	_, err := fhirpath.Compile("1 <")
+
+	var compileErr *fhirpath.CompileError
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("Compile() error = %v; want CompileError", err)
+	}
+	if got, want := err, fhirpath.ErrSyntax; !errors.Is(got, want) {
+		t.Errorf("Compile() error = %v; want %v", got, want)
+	}
+}
+
+func TestCompile_InvalidLongLiteral_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+	}{
+		{"Decimal", "1.5L"},
+		{"Separated suffix", "123 L"},
+		{"Lowercase suffix", "123l"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile(tc.expr)
+
+			if got, want := err, fhirpath.ErrSyntax; !errors.Is(got, want) {
+				t.Errorf("Compile(%q) error = %v; want %v", tc.expr, got, want)
+			}
+		})
+	}
+}
+
+func TestEvalComparison(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Integer less than", "1 < 2", collection.True},
+		{"Integer greater than", "1 > 2", collection.False},
+		{"Integer less or equal", "2 <= 2", collection.True},
+		{"Integer greater or equal", "1 >= 2", collection.False},
+		{"Integer and decimal", "1 < 1.5", collection.True},
+		{"String", "'abc' < 'abd'", collection.True},
+		{"Empty operand", "{} < 1", collection.Empty},
+		{"Date of different precisions is indeterminate", "@2012 < @2012-01", collection.Empty},
+		{"Date of different precisions with earlier year", "@2011 < @2012-06", collection.True},
+		{"Date of same precision", "@2012-01-01 < @2012-01-02", collection.True},
+		{"Date and datetime", "@2012-01-01 <= @2012-01-01T10:00", collection.Empty},
+		{"DateTime seconds and milliseconds are a single precision", "@2012-01-01T10:30:00 >= @2012-01-01T10:30:00.000", collection.True},
+		{"DateTime with timezones", "@2012-01-01T10:30:00+02:00 < @2012-01-01T09:00:00Z", collection.True},
+		{"Time of different precisions is indeterminate", "@T10 > @T10:30", collection.Empty},
+		{"Time of same precision", "@T10:30 > @T10:29", collection.True},
+		{"Quantity of same units", "4 'mg' < 5 'mg'", collection.True},
+		{"Quantity of convertible units", "1 'g' > 999 'mg'", collection.True},
+		{"Quantity of incompatible units is indeterminate", "1 'g' < 1 'm'", collection.Empty},
+		{"Quantity of calendar and definite durations", "1 year > 1 'a'", collection.Empty},
+		{"Quantity of calendar durations", "1 year > 11 months", collection.True},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalComparison_IncomparableTypes_ReturnsError(t *testing.T) {
+	path := fhirpath.MustCompile("1 < 'abc'")
+
+	_, err := path.Eval(context.Background(), nil)
+
+	if got, want := err, fhirpath.ErrNotComparable; !errors.Is(got, want) {
+		t.Errorf("Eval() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalMembership(t *testing.T) {
+	allowedCodes := collection.Of(system.String("male"), system.String("female"))
+	testCases := []struct {
+		name  string
+		expr  string
+		input any
+		want  collection.Collection
+	}{
+		{"Code in allowed codes", "code in %allowedCodes", &fhir.Coding{Code: &fhir.Code{Value: "female"}}, collection.True},
+		{"Code not in allowed codes", "code in %allowedCodes", &fhir.Coding{Code: &fhir.Code{Value: "other"}}, collection.False},
+		{"Empty left operand", "code in %allowedCodes", &fhir.Coding{}, collection.Empty},
+		{"Empty right operand", "1 in {}", nil, collection.False},
+		{"Contains", "%allowedCodes contains 'male'", nil, collection.True},
+		{"Contains empty operand", "%allowedCodes contains {}", nil, collection.Empty},
+		{"Date of different precision", "@2012 in @2012-01", nil, collection.False},
+		{"Quantity of convertible units", "1000 'mg' in 1 'g'", nil, collection.True},
+		{"In union of literals", "1 in (1 | 2)", nil, collection.True},
+		{"Not in union of literals", "3 in (1 | 2)", nil, collection.False},
+		{"Union contains", "(1 | 2) contains 2", nil, collection.True},
+		{"Union with variable", "'other' in (%allowedCodes | 'other')", nil, collection.True},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr, fhirpath.DeclareVariable("allowedCodes", "System.String"))
+
+			got, err := path.Eval(context.Background(), tc.input, fhirpath.WithVariable("allowedCodes", allowedCodes))
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalMembership_MultipleItems_ReturnsError(t *testing.T) {
+	allowedCodes := collection.Of(system.String("male"), system.String("female"))
+	path := fhirpath.MustCompile("%allowedCodes in %allowedCodes", fhirpath.DeclareVariable("allowedCodes", "System.String"))
+
+	_, err := path.Eval(context.Background(), nil, fhirpath.WithVariable("allowedCodes", allowedCodes))
+
+	if got, want := err, fhirpath.ErrNotSingleton; !errors.Is(got, want) {
+		t.Errorf("Eval() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalUnion(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Distinct items", "1 | 2", collection.Of(system.Integer(1), system.Integer(2))},
+		{"Duplicate items", "1 | 2 | 1", collection.Of(system.Integer(1), system.Integer(2))},
+		{"Equal items of different types", "1 | 1.0", collection.Of(system.Integer(1))},
+		{"Equal quantities of different units", "1 'g' | 1000 'mg'", collection.Of(system.MustParseQuantity("1 'g'"))},
+		{"Empty operands", "{} | {}", collection.Empty},
+		{"Empty left operand", "{} | 'a'", collection.Of(system.String("a"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalIndexer(t *testing.T) {
+	first := &fhir.HumanName{Family: &fhir.String{Value: "Doe"}}
+	second := &fhir.HumanName{Family: &fhir.String{Value: "Smith"}}
+	input := &patient.Patient{Name: []*fhir.HumanName{first, second}}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"First item", "Patient.name[0]", collection.Of(first)},
+		{"Last item", "Patient.name[1].family", collection.Of(second.Family)},
+		{"Index expression", "Patient.name[2 - 1]", collection.Of(second)},
+		{"Index out of range", "Patient.name[2]", collection.Empty},
+		{"Negative index", "Patient.name[-1]", collection.Empty},
+		{"Empty index", "Patient.name[{}]", collection.Empty},
+		{"Empty source", "Patient.address[0]", collection.Empty},
+		{"Literal collection", "(1 | 2 | 3)[1]", collection.Of(system.Integer(2))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalIndexer_NonIntegerIndex_ReturnsError(t *testing.T) {
+	path := fhirpath.MustCompile("(1 | 2)['a']")
+
+	_, err := path.Eval(context.Background(), nil)
+
+	if err == nil {
+		t.Errorf("Eval(%q) error = nil; want error", path)
+	}
+}
+
+func TestEvalEquality(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Integer equal", "1 = 1", collection.True},
+		{"Integer and decimal", "1 = 1.0", collection.True},
+		{"String not equal", "'abc' != 'abd'", collection.True},
+		{"Empty operand", "{} = 1", collection.Empty},
+		{"Date of different precisions is indeterminate", "@2012 = @2012-01", collection.Empty},
+		{"Quantity of convertible units", "1 'g' = 1000 'mg'", collection.True},
+		{"Different types", "1 = 'abc'", collection.False},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalBoundary_KeepsPrecision(t *testing.T) {
+	path := fhirpath.MustCompile("1.587.lowBoundary(8)", fhirpath.N2())
+
+	got, err := path.Eval(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+
+	if got, want := fmt.Sprint(got), "[1.58650000]"; got != want {
+		t.Errorf("Eval() = %v; want %v", got, want)
+	}
+}
+
+func TestEvalBoundary(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Decimal low boundary", "1.587.lowBoundary()", collection.Of(system.MustParseDecimal("1.5865"))},
+		{"Decimal high boundary with precision", "1.587.highBoundary(2)", collection.Of(system.MustParseDecimal("1.59"))},
+		{"Date low boundary", "@2014.lowBoundary(6)", collection.Of(system.MustParseDate("2014-01"))},
+		{"Date expanded to datetime", "@2020-03.highBoundary(17)", collection.Of(system.MustParseDateTime("2020-03-31T23:59:59.999"))},
+		{"DateTime high boundary", "@2014-01-01T08.highBoundary()", collection.Of(system.MustParseDateTime("2014-01-01T08:59:59.999"))},
+		{"Time low boundary", "@T10:30.lowBoundary(9)", collection.Of(system.MustParseTime("10:30:00.000"))},
+		{"Invalid precision", "@2014.lowBoundary(5)", collection.Empty},
+		{"Empty precision", "1.587.lowBoundary({})", collection.Empty},
+		{"Empty input", "{}.lowBoundary()", collection.Empty},
+		{"String input", "'abc'.highBoundary()", collection.Empty},
+		{"Decimal precision", "1.58700.precision()", collection.Of(system.Integer(5))},
+		{"Decimal boundary precision", "1.587.lowBoundary(8).precision()", collection.Of(system.Integer(8))},
+		{"Date precision", "@2014.precision()", collection.Of(system.Integer(4))},
+		{"DateTime precision", "@2014-01-05T10:30:00.000.precision()", collection.Of(system.Integer(17))},
+		{"Time precision", "@T10:30.precision()", collection.Of(system.Integer(4))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr, fhirpath.N2())
+
+			got, err := path.Eval(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if !got.Equal(tc.want) {
+				t.Errorf("Eval(%q) = %v; want %v", tc.expr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompile_N2FunctionWithoutN2_ReturnsError(t *testing.T) {
+	_, err := fhirpath.Compile("@2014.lowBoundary()")
+
+	if got, want := err, fhirpath.ErrUnknownFunction; !errors.Is(got, want) {
+		t.Errorf("Compile() error = %v; want %v", got, want)
+	}
+}
+
+func TestCompile_ConflictingVersions_ReturnsError(t *testing.T) {
+	_, err := fhirpath.Compile("1", fhirpath.N1(), fhirpath.N2())
+
+	if err == nil {
+		t.Errorf("Compile() error = nil; want error")
+	}
+}
+
+func TestCompileType(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		opts []fhirpath.CompileOption
+		want reflect.TypeSpecifier
+	}{
+		{"Root type", "Patient", nil, "FHIR.Patient"},
+		{"Element", "Patient.gender", nil, "FHIR.code"},
+		{"List element", "Patient.name", nil, "List<FHIR.HumanName>"},
+		{"Element of list", "Patient.name.family", nil, "List<FHIR.string>"},
+		{"Inherited element", "Patient.id", nil, "System.String"},
+		{"Element without type name", "name.given", nil, "List<FHIR.string>"},
+		{"Choice element", "Patient.deceased", nil, "FHIR.Element"},
+		{"Choice of type", "Patient.deceased.ofType(boolean)", nil, "FHIR.boolean"},
+		{"Lenient choice named by type", "Patient.deceasedDateTime", []fhirpath.CompileOption{fhirpath.Lenient()}, "FHIR.dateTime"},
+		{"Element of unknown type", "Patient.contained.name", nil, "List<System.Any>"},
+		{"Comparison", "Patient.gender = 'male'", nil, "System.Boolean"},
+		{"Arithmetic", "1 + 2.5", nil, "System.Decimal"},
+		{"Long arithmetic", "1L + 2", nil, "System.Long"},
+		{"Concatenation of FHIR primitive", "Patient.name.family.first() & ' '", nil, "System.String"},
+		{"Date arithmetic", "Patient.birthDate + 1 'year'", nil, "System.Date"},
+		{"Function", "Patient.birthDate.lowBoundary()", []fhirpath.CompileOption{fhirpath.N2()}, "System.Date"},
+		{"Variable", "%var", []fhirpath.CompileOption{fhirpath.DeclareVariable("var", "Coding")}, "List<FHIR.Coding>"},
+		{"Resource variable", "%resource.name", nil, "List<FHIR.HumanName>"},
+		{"Indexer", "Patient.name[0]", nil, "FHIR.HumanName"},
+		{"Element of indexer", "Patient.name[0].given", nil, "List<FHIR.string>"},
+		{"Is operator", "Patient.deceased is boolean", nil, "System.Boolean"},
+		{"As operator", "Patient.deceased as dateTime", nil, "FHIR.dateTime"},
+		{"As function", "Patient.deceased.as(dateTime)", nil, "FHIR.dateTime"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]fhirpath.CompileOption{fhirpath.WithRootType("Patient")}, tc.opts...)
+			path, err := fhirpath.Compile(tc.expr, opts...)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tc.expr, err)
+			}
+
+			if got := path.Type(); got != tc.want {
+				t.Errorf("Compile(%q).Type() = %v; want %v", tc.expr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompileType_WithoutRootType(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want reflect.TypeSpecifier
+	}{
+		{"Element", "Patient.name", "List<System.Any>"},
+		{"Literal", "'abc'", "System.String"},
+		{"Comparison", "Patient.gender = 1", "System.Boolean"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			if got := path.Type(); got != tc.want {
+				t.Errorf("Compile(%q).Type() = %v; want %v", tc.expr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompile_WithRootType_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want error
+	}{
+		{"Misspelled element", "Patient.nmae", fhirpath.ErrUnknownElement},
+		{"Misspelled element of list", "Patient.name.gven", fhirpath.ErrUnknownElement},
+		{"Element of System type", "Patient.id.value", fhirpath.ErrUnknownElement},
+		{"Choice named by type", "Patient.deceasedBoolean", fhirpath.ErrUnknownElement},
+		{"Other root type", "Observation.value", fhirpath.ErrTypeMismatch},
+		{"Unknown type specifier", "Patient.deceased.ofType(Bolean)", fhirpath.ErrUnknownType},
+		{"Comparison of incompatible types", "Patient.gender = 1", fhirpath.ErrTypeMismatch},
+		{"Index of wrong type", "Patient.name['a']", fhirpath.ErrTypeMismatch},
+		{"Comparison of complex types", "Patient.name = Patient.address", fhirpath.ErrTypeMismatch},
+		{"Membership of incompatible types", "Patient.active in Patient.name.given", fhirpath.ErrTypeMismatch},
+		{"Inequality of incompatible types", "Patient.birthDate > 'abc'", fhirpath.ErrTypeMismatch},
+		{"Function of wrong input type", "Patient.name.memberOf('http://example.org')", fhirpath.ErrTypeMismatch},
+		{"Function argument", "Patient.name.extension(gven)", fhirpath.ErrUnknownElement},
+		{"Arithmetic on complex type", "1 + Patient.name", fhirpath.ErrTypeMismatch},
+		{"Concatenation of complex type", "Patient.name & 'x'", fhirpath.ErrTypeMismatch},
+		{"Arithmetic on resource", "Patient * 2", fhirpath.ErrTypeMismatch},
+		{"Arithmetic on incompatible types", "Patient.birthDate + 1", fhirpath.ErrTypeMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile(tc.expr, fhirpath.WithRootType("Patient"))
+
+			if got, want := err, tc.want; !errors.Is(got, want) {
+				t.Errorf("Compile(%q) error = %v; want %v", tc.expr, got, want)
+			}
+			var compileErr *fhirpath.CompileError
+			if !errors.As(err, &compileErr) {
+				t.Errorf("Compile(%q) error = %v; want CompileError", tc.expr, err)
+			}
+		})
+	}
+}
+
+func TestCompile_UnknownRootType_ReturnsError(t *testing.T) {
+	_, err := fhirpath.Compile("Patient.name", fhirpath.WithRootType("Patinet"))
+
+	if got, want := err, fhirpath.ErrUnknownType; !errors.Is(got, want) {
+		t.Errorf("Compile() error = %v; want %v", got, want)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	humanName := []string{"extension", "family", "given", "id", "period", "prefix", "suffix", "text", "use", "as", "conformsTo", "convertsToLong", "extension", "first", "getValue", "hasValue", "is", "ofType", "sort", "toLong", "type"}
+	testCases := []struct {
+		name string
+		expr string
+		opts []fhirpath.CompileOption
+		want []string
+	}{
+		{"Member of root", "Patient.na", nil, []string{"name"}},
+		{"Root type", "Pat", nil, []string{"Patient"}},
+		{"Members of list", "Patient.name.", nil, humanName},
+		{"Functions by input type", "Patient.birthDate.", []fhirpath.CompileOption{fhirpath.N2()}, []string{
+			"extension", "id", "value", "as", "conformsTo", "convertsToLong", "extension", "first", "getValue", "hasValue", "highBoundary", "is", "lowBoundary", "ofType", "precision", "sort", "toLong", "type",
+		}},
+		{"Case-insensitive prefix", "Patient.name.FAM", nil, []string{"family"}},
+		{"Function argument", "Patient.name.extension(gi", nil, []string{"given"}},
+		{"Unknown function argument", "Patient.name.where(gi", nil, []string{"given"}},
+		{"Unimplemented operator", "Patient.active and Patient.na", nil, []string{"name"}},
+		{"After syntax error", "Patient.name.)fam", nil, []string{"family"}},
+		{"Variables", "%", []fhirpath.CompileOption{fhirpath.DeclareVariable("threshold", "Quantity")}, []string{
+			"context", "loinc", "resource", "rootResource", "sct", "terminologies", "threshold", "ucum",
+		}},
+		{"Variable prefix", "%re", nil, []string{"resource"}},
+		{"Members of variable", "%resource.gen", nil, []string{"gender", "generalPractitioner"}},
+		{"Terminologies methods", "%terminologies.val", nil, []string{"validateCS", "validateVS"}},
+		{"Type after is", "Patient.deceased is Bool", nil, []string{"boolean", "Boolean"}},
+		{"Qualified type after as", "Patient.deceased as System.Date", nil, []string{"Date", "DateTime"}},
+		{"Type argument", "Patient.deceased.ofType(FHIR.dateT", nil, []string{"dateTime"}},
+		{"Type without backbone elements", "Patient.contained is Accou", nil, []string{"Account"}},
+		{"Type without elements of data types", "Patient.contained is Timing", nil, []string{"Timing"}},
+		{"Members of type argument", "Patient.deceased.ofType(Quantity).un", nil, []string{"unit"}},
+		{"Members of cast", "(Patient.deceased as Quantity).un", nil, []string{"unit"}},
+		{"Members after indexer", "Patient.name[0].fa", nil, []string{"family"}},
+		{"Inside string", "Patient.name.given = 'fam", nil, nil},
+		{"Inside comment", "Patient.name // fam", nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			completions, err := fhirpath.Complete(tc.expr, len(tc.expr), "Patient", tc.opts...)
+			if err != nil {
+				t.Fatalf("Complete(%q) error = %v", tc.expr, err)
+			}
+
+			var got []string
+			for _, completion := range completions {
+				got = append(got, completion.Label)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("Complete(%q) mismatch (-want +got):\n%v", tc.expr, cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestComplete_Candidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		expr   string
+		cursor int
+		want   fhirpath.Completion
+	}{
+		{"Element", "Patient.gender", 11, fhirpath.Completion{
+			Label:         "gender",
+			Kind:          fhirpath.CompletionElement,
+			Detail:        "FHIR.code",
+			Documentation: "Element of FHIR.Patient.",
+		}},
+		{"Inherited element", "Patient.tex", 11, fhirpath.Completion{
+			Label:         "text",
+			Kind:          fhirpath.CompletionElement,
+			Detail:        "FHIR.Narrative",
+			Documentation: "Element of FHIR.Patient, inherited from FHIR.DomainResource.",
+		}},
+		{"Function", "Patient.gender.memberOf('vs')", 23, fhirpath.Completion{
+			Label:         "memberOf",
+			Kind:          fhirpath.CompletionFunction,
+			Detail:        "memberOf(valueset : String) : Boolean",
+			Documentation: "Returns whether the input code, Coding, or CodeableConcept is a member of the value set.",
+		}},
+		{"Variable", "%ucu", 4, fhirpath.Completion{
+			Label:         "ucum",
+			Kind:          fhirpath.CompletionVariable,
+			Detail:        "System.String",
+			Documentation: "The URL of the code system: http://unitsofmeasure.org",
+		}},
+		{"Type", "Patient.contained is Patien", 27, fhirpath.Completion{
+			Label:         "Patient",
+			Kind:          fhirpath.CompletionType,
+			Detail:        "FHIR.Patient",
+			Documentation: "A type of the FHIR namespace, derived from FHIR.DomainResource.",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			completions, err := fhirpath.Complete(tc.expr, tc.cursor, "Patient")
+			if err != nil {
+				t.Fatalf("Complete(%q) error = %v", tc.expr, err)
+			}
+			if len(completions) == 0 {
+				t.Fatalf("Complete(%q) = []; want completions", tc.expr)
+			}
+
+			if got, want := completions[0], tc.want; !cmp.Equal(got, want) {
+				t.Errorf("Complete(%q)[0] mismatch (-want +got):\n%v", tc.expr, cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func TestComplete_WithoutRootType(t *testing.T) {
+	completions, err := fhirpath.Complete("Patient.na", 10, "")
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if got := len(completions); got != 0 {
+		t.Errorf("Complete() = %v; want no completions", completions)
+	}
+}
+
+func TestComplete_InvalidArguments_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cursor   int
+		rootType reflect.TypeSpecifier
+		wantErr  error
+	}{
+		{"Negative cursor", -1, "Patient", nil},
+		{"Cursor after expression", 11, "Patient", nil},
+		{"Unknown root type", 10, "Patinet", fhirpath.ErrUnknownType},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Complete("Patient.na", tc.cursor, tc.rootType)
+
+			if err == nil {
+				t.Fatalf("Complete() error = nil; want error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Complete() error = %v; want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvalArithmetic(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Long literal", "123L", collection.Of(system.Integer64(123))},
+		{"Negative long literal", "-123L", collection.Of(system.Integer64(-123))},
+		{"Integer addition", "1 + 2", collection.Of(system.Integer(3))},
+		{"Integer promoted to long", "2147483647 + 1L", collection.Of(system.Integer64(2147483648))},
+		{"Long promoted to decimal", "1L + 0.5", collection.Of(system.MustParseDecimal("1.5"))},
+		{"Long multiplication", "3L * 4", collection.Of(system.Integer64(12))},
+		{"Integer division is decimal", "1 / 2", collection.Of(system.MustParseDecimal("0.5"))},
+		{"Div", "7L div 2", collection.Of(system.Integer64(3))},
+		{"Mod", "7 mod 2", collection.Of(system.Integer(1))},
+		{"Division by zero", "1 / 0", collection.Empty},
+		{"Empty operand", "1 + {}", collection.Empty},
+		{"String addition", "'a' + 'b'", collection.Of(system.String("ab"))},
+		{"String concatenation with empty", "'a' & {}", collection.Of(system.String("a"))},
+		{"Long comparison", "5L > 4", collection.True},
+		{"Long equality", "5L = 5", collection.True},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if !got.Equal(tc.want) {
+				t.Errorf("Eval(%q) = %v; want %v", tc.expr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvalArithmetic_IntegerOverflow_ReturnsError(t *testing.T) {
+	path := fhirpath.MustCompile("2147483647 + 1")
+
+	_, err := path.Eval(context.Background(), nil)
+
+	if got, want := err, fhirpath.ErrOverflow; !errors.Is(got, want) {
+		t.Errorf("Eval() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalToLong(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Integer", "5.toLong()", collection.Of(system.Integer64(5))},
+		{"String", "'-42'.toLong()", collection.Of(system.Integer64(-42))},
+		{"Boolean", "true.toLong()", collection.Of(system.Integer64(1))},
+		{"Invalid string", "'4.2'.toLong()", collection.Empty},
+		{"Decimal", "4.2.toLong()", collection.Empty},
+		{"Empty", "{}.toLong()", collection.Empty},
+		{"Converts string", "'9223372036854775807'.convertsToLong()", collection.True},
+		{"Out of range string", "'9223372036854775808'.convertsToLong()", collection.False},
+		{"Converts empty", "{}.convertsToLong()", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if !got.Equal(tc.want) {
+				t.Errorf("Eval(%q) = %v; want %v", tc.expr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvalSort(t *testing.T) {
+	newEncounter := func(id, start, status string) *encounter.Encounter {
+		return &encounter.Encounter{
+			ID:     id,
+			Period: &fhir.Period{Start: &fhir.DateTime{Value: start}},
+			Status: &fhir.Code{Value: status},
+		}
+	}
+	first := newEncounter("first", "2020-01-01T10:00:00Z", "finished")
+	second := newEncounter("second", "2020-02-01T10:00:00Z", "in-progress")
+	third := newEncounter("third", "2020-02-01T10:00:00Z", "finished")
+	unplanned := &encounter.Encounter{ID: "unplanned"}
+	variables := []fhirpath.EvalOption{
+		fhirpath.WithVariable("encounters", collection.Of(third, first, second, unplanned)),
+		fhirpath.WithVariable("codes", collection.Of(system.String("b"), system.String("B"), system.String("a"))),
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Ascending is stable", "%encounters.sort(period.start)", collection.Of(unplanned, first, third, second)},
+		{"Descending", "%encounters.sort(-period.start)", collection.Of(third, second, first, unplanned)},
+		{"Multiple keys", "%encounters.sort(-period.start, status)", collection.Of(third, second, first, unplanned)},
+		{"Multiple keys descending", "%encounters.sort(status, -period.start)", collection.Of(unplanned, third, first, second)},
+		{"Index", "%encounters.sort(-$index)", collection.Of(unplanned, second, first, third)},
+		{"Without keys", "%codes.sort()", collection.Of(system.String("B"), system.String("a"), system.String("b"))},
+		{"This descending", "%codes.sort(-$this)", collection.Of(system.String("b"), system.String("a"), system.String("B"))},
+		{"Strings by code point", "('é' | 'z' | 'Z' | 'e').sort()", collection.Of(system.String("Z"), system.String("e"), system.String("z"), system.String("é"))},
+		{"Empty input", "{}.sort()", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr,
+				fhirpath.DeclareVariable("encounters", "FHIR.Encounter"),
+				fhirpath.DeclareVariable("codes", "System.String"),
+			)
+
+			got, err := path.Eval(context.Background(), nil, variables...)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalSort_IncomparableKeys_ReturnsError(t *testing.T) {
+	values := collection.Of(system.Integer(1), system.String("a"))
+	path := fhirpath.MustCompile("%values.sort()", fhirpath.DeclareVariable("values", "System.Any"))
+
+	_, err := path.Eval(context.Background(), nil, fhirpath.WithVariable("values", values))
+
+	if got, want := err, fhirpath.ErrNotComparable; !errors.Is(got, want) {
+		t.Errorf("Eval() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalExtension(t *testing.T) {
+	birthTime := &fhir.Extension{
+		URL:   "http://hl7.org/fhir/StructureDefinition/patient-birthTime",
+		Value: &fhir.DateTime{Value: "1974-12-25T14:35:45-05:00"},
+	}
+	race := &fhir.Extension{
+		URL: "http://hl7.org/fhir/us/core/StructureDefinition/us-core-race",
+		Extension: []*fhir.Extension{
+			{URL: "ombCategory", Value: &fhir.Coding{Code: &fhir.Code{Value: "2106-3"}}},
+			{URL: "text", Value: &fhir.String{Value: "White"}},
+		},
+	}
+	input := &patient.Patient{
+		BirthDate: &fhir.Date{Value: "1974-12-25", Extension: []*fhir.Extension{birthTime}},
+		Extension: []*fhir.Extension{race},
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Primitive extension", "Patient.birthDate.extension('http://hl7.org/fhir/StructureDefinition/patient-birthTime')", collection.Of(birthTime)},
+		{"Primitive extension value", "Patient.birthDate.extension(%`ext-patient-birthTime`).value", collection.Of(birthTime.Value)},
+		{"Nested extension", "Patient.extension('http://hl7.org/fhir/us/core/StructureDefinition/us-core-race').extension('ombCategory').value.code", collection.Of(&fhir.Code{Value: "2106-3"})},
+		{"Unknown extension", "Patient.extension('http://example.com/unknown')", collection.Empty},
+		{"Empty url", "Patient.extension({})", collection.Empty},
+		{"Extension constant", "%'ext-patient-birthTime'", collection.Of(system.String("http://hl7.org/fhir/StructureDefinition/patient-birthTime"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalEnvironmentVariables(t *testing.T) {
+	input := &patient.Patient{Gender: &fhir.Code{Value: "female"}}
+	other := &patient.Patient{Gender: &fhir.Code{Value: "male"}}
+
+	testCases := []struct {
+		name string
+		expr string
+		opts []fhirpath.EvalOption
+		want collection.Collection
+	}{
+		{"Context", "%context", nil, collection.Of(input)},
+		{"Resource", "%resource.gender", nil, collection.Of(input.Gender)},
+		{"Root resource", "%rootResource.gender", nil, collection.Of(input.Gender)},
+		{"Overridden resource", "%resource.gender", []fhirpath.EvalOption{fhirpath.WithVariable("resource", other)}, collection.Of(other.Gender)},
+		{"UCUM", "%ucum", nil, collection.Of(system.String("http://unitsofmeasure.org"))},
+		{"SNOMED CT", "%sct", nil, collection.Of(system.String("http://snomed.info/sct"))},
+		{"LOINC", "%loinc", nil, collection.Of(system.String("http://loinc.org"))},
+		{"Value set", "%`vs-administrative-gender`", nil, collection.Of(system.String("http://hl7.org/fhir/ValueSet/administrative-gender"))},
+		{"Overridden value set", "%`vs-test`", []fhirpath.EvalOption{fhirpath.WithVariable("vs-test", "http://example.org")}, collection.Of(system.String("http://example.org"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input, tc.opts...)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalEnvironmentVariables_ElementFocus_ResourceUndefined(t *testing.T) {
+	input := &fhir.HumanName{Family: &fhir.String{Value: "Doe"}}
+	path := fhirpath.MustCompile("%resource")
+
+	_, err := path.Eval(context.Background(), input)
+
+	if err == nil {
+		t.Errorf("Eval(%q) error = nil; want error", path)
+	}
+}
+
+func TestEvalInResource(t *testing.T) {
+	contained := &patient.Patient{ID: "p", Gender: &fhir.Code{Value: "female"}}
+	obs := &observation.Observation{
+		ID:        "o",
+		Contained: []fhir.Resource{contained},
+		Subject:   &fhir.Reference{Reference: &fhir.String{Value: "#p"}},
+		Status:    &fhir.Code{Value: "final"},
+	}
+	root := &bundle.Bundle{ID: "b", Entry: []*bundle.BundleEntry{{Resource: obs}}}
+
+	testCases := []struct {
+		name string
+		expr string
+		node any
+		want collection.Collection
+	}{
+		{"Context", "%context", obs.Status, collection.Of(obs.Status)},
+		{"Resource of element", "%resource.id", obs.Status, collection.Of(system.String("o"))},
+		{"Root resource of element", "%rootResource.id", obs.Status, collection.Of(system.String("o"))},
+		{"Resource of entry", "%resource.id", obs, collection.Of(system.String("o"))},
+		{"Resource of bundle", "%resource.id", root, collection.Of(system.String("b"))},
+		{"Resource of contained element", "%resource.id", contained.Gender, collection.Of(system.String("p"))},
+		{"Root resource of contained element", "%rootResource.id", contained.Gender, collection.Of(system.String("o"))},
+		{"Contained reference of element", "resolve().gender", obs.Subject, collection.Of(contained.Gender)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), tc.node, fhirpath.InResource(root))
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalInResource_NodeNotInResource_ReturnsError(t *testing.T) {
+	root := &patient.Patient{Gender: &fhir.Code{Value: "female"}}
+	other := &patient.Patient{Gender: &fhir.Code{Value: "male"}}
+	path := fhirpath.MustCompile("%resource")
+
+	_, err := path.Eval(context.Background(), other.Gender, fhirpath.InResource(root))
+
+	if got, want := err, fhirpath.ErrNotInResource; !errors.Is(got, want) {
+		t.Errorf("Eval(%q) error = %v; want %v", path, got, want)
+	}
+}
+
+func TestEvalVariable(t *testing.T) {
+	input := &patient.Patient{Gender: &fhir.Code{Value: "female"}}
+
+	testCases := []struct {
+		name  string
+		typ   reflect.TypeSpecifier
+		value any
+		expr  string
+		want  collection.Collection
+	}{
+		{"Go string", "String", "hello", "%var", collection.Of(system.String("hello"))},
+		{"Go int", "Integer", 41, "%var + 1", collection.Of(system.Integer(42))},
+		{"Go bool", "Boolean", true, "%var", collection.True},
+		{"FHIR value", "FHIR.code", &fhir.Code{Value: "female"}, "Patient.gender in %var", collection.True},
+		{"Collection", "System.String", collection.Of(system.String("a"), system.String("b")), "'b' in %var", collection.True},
+		{"Any type", "System.Any", collection.Of(system.Integer(1), system.String("a")), "%var contains 'a'", collection.True},
+		{"Nil value", "String", nil, "%var", collection.Empty},
+		{"Quoted name", "String", "hello", "%'var'", collection.Of(system.String("hello"))},
+		{"Derived type", "Resource", &patient.Patient{ID: "example"}, "%var.id", collection.Of(system.String("example"))},
+		{"Derived type of element", "Quantity", &fhir.Age{Unit: &fhir.String{Value: "a"}}, "%var.unit", collection.Of(&fhir.String{Value: "a"})},
+		{"Implicitly converted type", "Decimal", 1, "%var + 0.5", collection.Of(system.MustParseDecimal("1.5"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr, fhirpath.DeclareVariable("var", tc.typ))
+
+			got, err := path.Eval(context.Background(), input, fhirpath.WithVariable("var", tc.value))
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestCompile_UndeclaredVariable_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+	}{
+		{"Identifier", "%undeclared"},
+		{"Quoted name", "%'undeclared'"},
+		{"Declared with different name", "%declared + %undeclared"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile(tc.expr, fhirpath.DeclareVariable("declared", "Integer"))
+
+			if got, want := err, fhirpath.ErrUndeclaredVariable; !errors.Is(got, want) {
+				t.Errorf("Compile(%q) error = %v; want %v", tc.expr, got, want)
+			}
+		})
+	}
+}
+
+func TestCompile_InvalidDeclaration_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []fhirpath.CompileOption
+	}{
+		{"Empty name", []fhirpath.CompileOption{fhirpath.DeclareVariable("", "String")}},
+		{"Conflicting types", []fhirpath.CompileOption{
+			fhirpath.DeclareVariable("var", "String"),
+			fhirpath.DeclareVariable("var", "Integer"),
+		}},
+		{"Name defined by FHIR", []fhirpath.CompileOption{fhirpath.DeclareVariable("ucum", "String")}},
+		{"Shorthand defined by FHIR", []fhirpath.CompileOption{fhirpath.DeclareVariable("vs-example", "String")}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile("1", tc.opts...)
+
+			if err == nil {
+				t.Errorf("Compile() error = nil; want error")
+			}
+		})
+	}
+}
+
+func TestCompile_UnknownVariableType_ReturnsError(t *testing.T) {
+	_, err := fhirpath.Compile("%var", fhirpath.DeclareVariable("var", "Strnig"))
+
+	if got, want := err, fhirpath.ErrUnknownType; !errors.Is(got, want) {
+		t.Errorf("Compile() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalVariable_WrongType_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name  string
+		typ   reflect.TypeSpecifier
+		value any
+	}{
+		{"Go value", "Integer", "hello"},
+		{"FHIR value", "FHIR.Coding", &fhir.Code{Value: "female"}},
+		{"Wrong namespace", "FHIR.Quantity", system.MustParseQuantity("1 'g'")},
+		{"Base of declared type", "Age", &fhir.Quantity{Unit: &fhir.String{Value: "a"}}},
+		{"Mixed collection", "System.String", collection.Of(system.String("a"), system.Integer(1))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile("1", fhirpath.DeclareVariable("var", tc.typ))
+
+			_, err := path.Eval(context.Background(), nil, fhirpath.WithVariable("var", tc.value))
+
+			if got, want := err, fhirpath.ErrVariableType; !errors.Is(got, want) {
+				t.Errorf("Eval() error = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvalVariable_MissingValue_ReturnsError(t *testing.T) {
+	path := fhirpath.MustCompile("1", fhirpath.DeclareVariable("var", "String"))
+
+	_, err := path.Eval(context.Background(), nil)
+
+	if got, want := err, fhirpath.ErrMissingVariable; !errors.Is(got, want) {
+		t.Errorf("Eval() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalVariable_UnsupportedValue_ReturnsError(t *testing.T) {
+	path := fhirpath.MustCompile("%var", fhirpath.DeclareVariable("var", "System.Any"))
+
+	_, err := path.Eval(context.Background(), nil, fhirpath.WithVariable("var", struct{}{}))
+
+	if err == nil {
+		t.Errorf("Eval() error = nil; want error")
+	}
+}
+
+func TestEvalChoice(t *testing.T) {
+	quantity := &fhir.Quantity{Value: &fhir.Decimal{Value: 185}, Unit: &fhir.String{Value: "lbs"}}
+	effective := &fhir.DateTime{Value: "2020-01-01"}
+	input := &observation.Observation{
+		Value:     quantity,
+		Effective: effective,
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		opts []fhirpath.CompileOption
+		want collection.Collection
+	}{
+		{"Choice element", "Observation.value", nil, collection.Of(quantity)},
+		{"Choice of type", "Observation.value.ofType(Quantity)", nil, collection.Of(quantity)},
+		{"Choice of qualified type", "Observation.value.ofType(FHIR.Quantity)", nil, collection.Of(quantity)},
+		{"Choice of other type", "Observation.value.ofType(String)", nil, collection.Empty},
+		{"Choice of other namespace", "Observation.value.ofType(System.Quantity)", nil, collection.Empty},
+		{"Choice named by type", "Observation.valueQuantity", nil, collection.Empty},
+		{"Lenient choice named by type", "Observation.valueQuantity.unit", []fhirpath.CompileOption{fhirpath.Lenient()}, collection.Of(quantity.Unit)},
+		{"Lenient choice named by other type", "Observation.valueString", []fhirpath.CompileOption{fhirpath.Lenient()}, collection.Empty},
+		{"Lenient choice of primitive", "Observation.effectiveDateTime", []fhirpath.CompileOption{fhirpath.Lenient()}, collection.Of(effective)},
+		{"System type", "'abc'.ofType(String)", nil, collection.Of(system.String("abc"))},
+		{"Long type", "5L.ofType(System.Long)", nil, collection.Of(system.Integer64(5))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr, tc.opts...)
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalTypeOperators(t *testing.T) {
+	quantity := &fhir.Quantity{Value: &fhir.Decimal{Value: 185}, Unit: &fhir.String{Value: "lbs"}}
+	input := &observation.Observation{Value: quantity}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Is type", "Observation.value is Quantity", collection.True},
+		{"Is qualified type", "Observation.value is FHIR.Quantity", collection.True},
+		{"Is base type", "Observation.value is Element", collection.True},
+		{"Is other type", "Observation.value is String", collection.False},
+		{"Is System type", "1 is Integer", collection.True},
+		{"Is of empty", "Observation.effective is DateTime", collection.Empty},
+		{"As type", "(Observation.value as Quantity).unit", collection.Of(quantity.Unit)},
+		{"As other type", "Observation.value as String", collection.Empty},
+		{"Is function", "Observation.value.is(Quantity)", collection.True},
+		{"As function", "Observation.value.as(Quantity).unit", collection.Of(quantity.Unit)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalTypeOperators_NotSingleton_ReturnsError(t *testing.T) {
+	for _, expr := range []string{"(1 | 2) is Integer", "(1 | 2) as Integer"} {
+		_, err := fhirpath.MustCompile(expr).Eval(context.Background(), nil)
+
+		if got, want := err, fhirpath.ErrNotSingleton; !errors.Is(got, want) {
+			t.Errorf("Eval(%q) error = %v; want %v", expr, got, want)
+		}
+	}
+}
+
+func TestCompile_TypeOperatorUnknownType_ReturnsError(t *testing.T) {
+	_, err := fhirpath.Compile("Patient.deceased as dateTiem", fhirpath.WithRootType("Patient"))
+
+	if got, want := err, fhirpath.ErrUnknownType; !errors.Is(got, want) {
+		t.Errorf("Compile() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalOfType_Subtype(t *testing.T) {
+	age := &fhir.Age{Value: &fhir.Decimal{Value: 42}, Unit: &fhir.String{Value: "a"}}
+	input := &patient.Patient{
+		Extension: []*fhir.Extension{{URL: "http://example.com/age", Value: age}},
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Resource", "Patient.ofType(Resource)", collection.Of(input)},
+		{"Domain resource", "Patient.ofType(FHIR.DomainResource)", collection.Of(input)},
+		{"Specialized type", "Patient.extension.value.ofType(Quantity)", collection.Of(age)},
+		{"Derived type", "Patient.extension.value.ofType(Age)", collection.Of(age)},
+		{"Unrelated type", "Patient.extension.value.ofType(Coding)", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalType(t *testing.T) {
+	input := &patient.Patient{
+		Gender:  &fhir.Code{Value: "female"},
+		Contact: []*patient.PatientContact{{Gender: &fhir.Code{Value: "male"}}},
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Resource name", "Patient.type().name", collection.Of(system.String("Patient"))},
+		{"Resource namespace", "Patient.type().namespace", collection.Of(system.String("FHIR"))},
+		{"Resource base type", "Patient.type().baseType", collection.Of(system.String("FHIR.DomainResource"))},
+		{"Primitive name", "Patient.gender.type().name", collection.Of(system.String("code"))},
+		{"Primitive base type", "Patient.gender.type().baseType", collection.Of(system.String("FHIR.string"))},
+		{"Backbone element base type", "Patient.contact.type().baseType", collection.Of(system.String("FHIR.BackboneElement"))},
+		{"Element", "'gender' in Patient.type().element.name", collection.True},
+		{"List element", "'List<FHIR.HumanName>' in Patient.type().element.type", collection.True},
+		{"Inherited element", "'id' in Patient.type().element.name", collection.False},
+		{"System type name", "'abc'.type().name", collection.Of(system.String("String"))},
+		{"System type namespace", "'abc'.type().namespace", collection.Of(system.String("System"))},
+		{"Long type name", "5L.type().name", collection.Of(system.String("Long"))},
+		{"Empty input", "Patient.birthDate.type()", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalResolve(t *testing.T) {
+	contained := &patient.Patient{ID: "p1"}
+	resolved := &practitioner.Practitioner{ID: "123"}
+	input := &observation.Observation{
+		ID:        "obs",
+		Contained: []fhir.Resource{contained},
+		Subject:   &fhir.Reference{Reference: &fhir.String{Value: "#p1"}},
+		Performer: []*fhir.Reference{
+			{Reference: &fhir.String{Value: "Practitioner/123"}},
+			{Display: &fhir.String{Value: "Dr. Nobody"}},
+		},
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		opts []fhirpath.EvalOption
+		want collection.Collection
+	}{
+		{"Contained reference", "Observation.subject.resolve()", nil, collection.Of(contained)},
+		{"Contained reference with resolver", "Observation.subject.resolve()", []fhirpath.EvalOption{fhirpath.WithResolver(resolvertest.Return(resolved))}, collection.Of(contained)},
+		{"Reference to container", "'#'.resolve()", nil, collection.Of(input)},
+		{"Unknown contained reference", "'#p2'.resolve()", nil, collection.Empty},
+		{"Reference with resolver", "Observation.performer.resolve()", []fhirpath.EvalOption{fhirpath.WithResolver(resolvertest.Return(resolved))}, collection.Of(resolved)},
+		{"Reference without resolver", "Observation.performer.resolve()", nil, collection.Empty},
+		{"String reference", "'Practitioner/123'.resolve()", []fhirpath.EvalOption{fhirpath.WithResolver(resolvertest.Return(resolved))}, collection.Of(resolved)},
+		{"Reference not found", "Observation.performer.resolve()", []fhirpath.EvalOption{fhirpath.WithResolver(resolvertest.Return(nil))}, collection.Empty},
+		{"Resolver error", "Observation.performer.resolve()", []fhirpath.EvalOption{fhirpath.WithResolver(resolvertest.Error(errors.New("unavailable")))}, collection.Empty},
+		{"Not a reference", "1.resolve()", []fhirpath.EvalOption{fhirpath.WithResolver(resolvertest.Return(resolved))}, collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input, tc.opts...)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalResolve_BundleEntry(t *testing.T) {
+	org := &organization.Organization{ID: "p", Name: &fhir.String{Value: "Acme"}}
+	input := &bundle.Bundle{
+		Entry: []*bundle.BundleEntry{{
+			Resource: &patient.Patient{
+				Contained:            []fhir.Resource{org},
+				ManagingOrganization: &fhir.Reference{Reference: &fhir.String{Value: "#p"}},
+			},
+		}},
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Contained reference of entry", "Bundle.entry.resource.managingOrganization.resolve()", collection.Of(org)},
+		{"Contained reference string of entry", "Bundle.entry.resource.managingOrganization.reference.resolve()", collection.Of(org)},
+		{"Contained reference to bundle", "'#p'.resolve()", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalResolve_StrictResolverError_ReturnsError(t *testing.T) {
+	want := errors.New("unavailable")
+	path := fhirpath.MustCompile("'Practitioner/123'.resolve()")
+
+	_, err := path.Eval(context.Background(), nil, fhirpath.WithResolver(resolvertest.Error(want)), fhirpath.StrictResolve())
+
+	if got := err; !errors.Is(got, want) {
+		t.Errorf("Eval() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalPrimitiveValue(t *testing.T) {
+	dataAbsent := []*fhir.Extension{{
+		URL:   "http://hl7.org/fhir/StructureDefinition/data-absent-reason",
+		Value: &fhir.Code{Value: "unknown"},
+	}}
+	input := &patient.Patient{
+		ID:        "example",
+		BirthDate: &fhir.Date{Extension: dataAbsent},
+		Gender:    &fhir.Code{Value: "female"},
+		Active:    &fhir.Boolean{Value: false},
+		Deceased:  &fhir.Boolean{Extension: dataAbsent},
+		Text: &fhir.Narrative{
+			Div: &fhir.XHTML{Value: `<div xmlns="http://www.w3.org/1999/xhtml">Jane <b>Doe</b></div>`},
+		},
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Has value", "Patient.gender.hasValue()", collection.True},
+		{"Has only extensions", "Patient.birthDate.hasValue()", collection.False},
+		{"Has false value", "Patient.active.hasValue()", collection.True},
+		{"Has zero value with extensions", "Patient.deceased.hasValue()", collection.False},
+		{"Has value of complex type", "Patient.text.hasValue()", collection.False},
+		{"Has value of empty", "Patient.maritalStatus.hasValue()", collection.False},
+		{"Has value of id", "Patient.id.hasValue()", collection.True},
+		{"Has value of extension url", "Patient.birthDate.extension.url.hasValue()", collection.True},
+		{"Get value", "Patient.gender.getValue()", collection.Of(system.String("female"))},
+		{"Get value of only extensions", "Patient.birthDate.getValue()", collection.Empty},
+		{"Get value of id", "Patient.id.getValue()", collection.Of(system.String("example"))},
+		{"Get value of complex type", "Patient.text.getValue()", collection.Empty},
+		{"Get value of XHTML", "Patient.text.`div`.getValue()", collection.Of(system.String(input.Text.Div.Value))},
+		{"HTML checks", "Patient.text.`div`.htmlChecks()", collection.True},
+		{"HTML checks of script", "'<div xmlns=\"http://www.w3.org/1999/xhtml\"><script/>x</div>'.htmlChecks()", collection.False},
+		{"HTML checks of empty", "Patient.maritalStatus.htmlChecks()", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalConformsTo(t *testing.T) {
+	const profile = "http://example.org/StructureDefinition/active-patient"
+	validator := conformance.ValidatorFunc(func(_ context.Context, resource any, url string) (bool, error) {
+		if url != profile {
+			return false, fmt.Errorf("%w '%v'", fhirpath.ErrUnknownProfile, url)
+		}
+		p, ok := resource.(*patient.Patient)
+		return ok && p.Active != nil && p.Active.Value, nil
+	})
+	opts := []fhirpath.EvalOption{fhirpath.WithProfileValidator(validator)}
+
+	testCases := []struct {
+		name  string
+		expr  string
+		input any
+		want  collection.Collection
+	}{
+		{"Conforming resource", "Patient.conformsTo('" + profile + "')", &patient.Patient{Active: &fhir.Boolean{Value: true}}, collection.True},
+		{"Non-conforming resource", "Patient.conformsTo('" + profile + "')", &patient.Patient{}, collection.False},
+		{"Empty input", "Patient.conformsTo('" + profile + "')", &observation.Observation{}, collection.Empty},
+		{"Empty url", "Patient.conformsTo({})", &patient.Patient{}, collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), tc.input, opts...)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalConformsTo_UnknownProfile_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []fhirpath.EvalOption
+	}{
+		{"Without validator", nil},
+		{"With validator", []fhirpath.EvalOption{fhirpath.WithProfileValidator(conformance.ValidatorFunc(func(_ context.Context, _ any, url string) (bool, error) {
+			return false, fmt.Errorf("%w '%v'", conformance.ErrUnknownProfile, url)
+		}))}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile("Patient.conformsTo('http://example.org/unknown')")
+
+			_, err := path.Eval(context.Background(), &patient.Patient{}, tc.opts...)
+
+			if got, want := err, fhirpath.ErrUnknownProfile; !errors.Is(got, want) {
+				t.Errorf("Eval() error = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvalTerminology(t *testing.T) {
+	const system = "http://example.org/CodeSystem/animals"
+	ts, err := terminology.NewMemory(
+		&codesystem.CodeSystem{
+			URL: &fhir.URI{Value: system},
+			Concept: []*codesystem.CodeSystemConcept{
+				{Code: &fhir.Code{Value: "mammal"}},
+				{Code: &fhir.Code{Value: "dog"}, Property: []*codesystem.CodeSystemConceptProperty{{
+					Code:  &fhir.Code{Value: "parent"},
+					Value: &fhir.Code{Value: "mammal"},
+				}}},
+			},
+		},
+		&valueset.ValueSet{
+			URL: &fhir.URI{Value: "http://example.org/ValueSet/mammals"},
+			Compose: &valueset.ValueSetCompose{
+				Include: []*valueset.ValueSetComposeInclude{{
+					System: &fhir.URI{Value: system},
+					Filter: []*valueset.ValueSetComposeIncludeFilter{{
+						Property: &fhir.Code{Value: "concept"},
+						Op:       &fhir.Code{Value: "is-a"},
+						Value:    &fhir.String{Value: "mammal"},
+					}},
+				}},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+	conceptOf := func(code string) *fhir.CodeableConcept {
+		return &fhir.CodeableConcept{Coding: []*fhir.Coding{{
+			System: &fhir.URI{Value: system},
+			Code:   &fhir.Code{Value: code},
+		}}}
+	}
+	input := &observation.Observation{
+		Code:  conceptOf("mammal"),
+		Value: conceptOf("dog"),
+	}
+	declarations := []fhirpath.CompileOption{
+		fhirpath.DeclareVariable("mammal", "FHIR.CodeableConcept"),
+		fhirpath.DeclareVariable("dog", "FHIR.CodeableConcept"),
+	}
+	options := []fhirpath.EvalOption{
+		fhirpath.WithTerminology(ts),
+		fhirpath.WithVariable("mammal", conceptOf("mammal")),
+		fhirpath.WithVariable("dog", conceptOf("dog")),
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"CodeableConcept member of value set", "Observation.value.memberOf('http://example.org/ValueSet/mammals')", collection.True},
+		{"Coding member of value set", "Observation.value.coding.memberOf('http://example.org/ValueSet/mammals')", collection.True},
+		{"Code member of value set", "'dog'.memberOf('http://example.org/ValueSet/mammals')", collection.True},
+		{"Code not member of value set", "'cat'.memberOf('http://example.org/ValueSet/mammals')", collection.False},
+		{"Empty input to memberOf", "Observation.method.memberOf('http://example.org/ValueSet/mammals')", collection.Empty},
+		{"Subsumes", "Observation.code.subsumes(%dog)", collection.True},
+		{"Does not subsume", "Observation.value.subsumes(%mammal)", collection.False},
+		{"Subsumed by", "Observation.value.coding.subsumedBy(%mammal.coding)", collection.True},
+		{"Equivalent is subsumed by", "Observation.code.subsumedBy(%mammal)", collection.True},
+		{"Empty argument to subsumes", "Observation.code.subsumes(Observation.method)", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr, declarations...)
+
+			got, err := path.Eval(context.Background(), input, options...)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvalTerminology_WithoutTerminology_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		expr string
+		want error
+	}{
+		{"MemberOf", "'dog'.memberOf('http://example.org/ValueSet/mammals')", fhirpath.ErrUnknownValueSet},
+		{"Subsumes", "Observation.code.subsumes(%dog)", fhirpath.ErrUnknownCodeSystem},
+	}
+	input := &observation.Observation{
+		Code: &fhir.CodeableConcept{Coding: []*fhir.Coding{{
+			System: &fhir.URI{Value: "http://example.org/CodeSystem/animals"},
+			Code:   &fhir.Code{Value: "dog"},
+		}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr, fhirpath.DeclareVariable("dog", "FHIR.CodeableConcept"))
+
+			_, err := path.Eval(context.Background(), input, fhirpath.WithVariable("dog", input.Code))
+
+			if got, want := err, tc.want; !errors.Is(got, want) {
+				t.Errorf("Eval(%q) error = %v; want %v", tc.expr, got, want)
+			}
+		})
+	}
+}
+
+func TestEvalTerminologies(t *testing.T) {
+	const system = "http://example.org/CodeSystem/animals"
+	ts, err := terminology.NewMemory(
+		&codesystem.CodeSystem{
+			URL:  &fhir.URI{Value: system},
+			Name: &fhir.String{Value: "Animals"},
+			Concept: []*codesystem.CodeSystemConcept{
+				{Code: &fhir.Code{Value: "mammal"}, Display: &fhir.String{Value: "Mammal"}},
+				{Code: &fhir.Code{Value: "dog"}, Display: &fhir.String{Value: "Dog"}, Property: []*codesystem.CodeSystemConceptProperty{{
+					Code:  &fhir.Code{Value: "parent"},
+					Value: &fhir.Code{Value: "mammal"},
+				}}},
+			},
+		},
+		&valueset.ValueSet{
+			URL: &fhir.URI{Value: "http://example.org/ValueSet/mammals"},
+			Compose: &valueset.ValueSetCompose{
+				Include: []*valueset.ValueSetComposeInclude{{
+					System: &fhir.URI{Value: system},
+				}},
+			},
+		},
+		&conceptmap.ConceptMap{
+			URL: &fhir.URI{Value: "http://example.org/ConceptMap/animals"},
+			Group: []*conceptmap.ConceptMapGroup{{
+				Source: &fhir.URI{Value: system},
+				Target: &fhir.URI{Value: "http://example.org/CodeSystem/pets"},
+				Element: []*conceptmap.ConceptMapGroupElement{{
+					Code: &fhir.Code{Value: "dog"},
+					Target: []*conceptmap.ConceptMapGroupElementTarget{{
+						Code:        &fhir.Code{Value: "canine"},
+						Equivalence: &fhir.Code{Value: "equivalent"},
+					}},
+				}},
+			}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+	input := &observation.Observation{
+		Value: &fhir.CodeableConcept{Coding: []*fhir.Coding{{
+			System: &fhir.URI{Value: system},
+			Code:   &fhir.Code{Value: "dog"},
+		}}},
+	}
+
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Expand", "%terminologies.expand('http://example.org/ValueSet/mammals').expansion.total = 2", collection.True},
+		{"Expand with parameters", "%terminologies.expand('http://example.org/ValueSet/mammals', 'count=1').expansion.contains.code = 'mammal'", collection.True},
+		{"Lookup", "'Dog' in %terminologies.lookup(Observation.value.coding).parameter.value", collection.True},
+		{"Validate value set", "%terminologies.validateVS('http://example.org/ValueSet/mammals', Observation.value).parameter.value", collection.Of(&fhir.Boolean{Value: true}, &fhir.String{Value: "Dog"})},
+		{"Validate code system", "false in %terminologies.validateCS('" + system + "', 'cat').parameter.value", collection.True},
+		{"Subsumes", "%terminologies.subsumes('" + system + "', 'mammal', Observation.value.coding)", collection.Of(&fhir.Code{Value: "subsumes"})},
+		{"Translate", "%terminologies.translate('http://example.org/ConceptMap/animals', Observation.value.coding).parameter.value.code = 'canine'", collection.True},
+		{"Empty argument", "%terminologies.lookup(Observation.code.coding)", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr)
+
+			got, err := path.Eval(context.Background(), input, fhirpath.WithTerminology(ts))
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestCompile_UnknownTerminologiesMethod_ReturnsError(t *testing.T) {
+	_, err := fhirpath.Compile("%terminologies.unknown('http://example.org')")
+
+	if got, want := err, fhirpath.ErrUnknownFunction; !errors.Is(got, want) {
+		t.Errorf("Compile() error = %v; want %v", got, want)
+	}
+}
+
+func TestEvalCustomFunction(t *testing.T) {
+	input := &patient.Patient{
+		Name: []*fhir.HumanName{{
+			Family: &fhir.String{Value: "Doe"},
+			Given:  []*fhir.String{{Value: "Jane"}, {Value: "Mary"}},
+		}},
+		BirthDate: &fhir.Date{Value: "1990-01-01"},
+	}
+	greet := func(_ collection.Collection, name system.String) (collection.Collection, error) {
+		return collection.Of(system.String("Hello, " + name)), nil
+	}
+	half := func(_ collection.Collection, value system.Decimal) (collection.Collection, error) {
+		return collection.Of(system.NewDecimal(value.Float64() / 2)), nil
+	}
+	birthYear := func(_ collection.Collection, date system.DateTime) (collection.Collection, error) {
+		return collection.Of(system.Integer(date.Time().Year())), nil
+	}
+	family := func(_ collection.Collection, name *fhir.HumanName) (collection.Collection, error) {
+		return collection.Of(name.Family), nil
+	}
+	size := func(_ collection.Collection, values collection.Collection) (collection.Collection, error) {
+		return collection.Of(system.Integer(len(values))), nil
+	}
+	itemCount := func(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+		return collection.Of(system.Integer(len(input))), ctx.Err()
+	}
+	filter := func(input collection.Collection, criteria fhirpath.Lambda) (collection.Collection, error) {
+		var result collection.Collection
+		for i, item := range input {
+			matches, err := criteria.Evaluate(item, i)
+			if err != nil {
+				return nil, err
+			}
+			if ok, err := matches.Bool(); err == nil && ok {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	}
+
+	testCases := []struct {
+		name   string
+		fnName string
+		fn     any
+		expr   string
+		want   collection.Collection
+	}{
+		{"System argument", "greet", greet, "greet('World')", collection.Of(system.String("Hello, World"))},
+		{"FHIR primitive argument", "greet", greet, "greet(Patient.name.family)", collection.Of(system.String("Hello, Doe"))},
+		{"Implicit conversion", "half", half, "half(3)", collection.Of(system.NewDecimal(1.5))},
+		{"Date into DateTime", "birthYear", birthYear, "birthYear(Patient.birthDate)", collection.Of(system.Integer(1990))},
+		{"FHIR argument", "family", family, "family(Patient.name)", collection.Of(&fhir.String{Value: "Doe"})},
+		{"Collection argument", "size", size, "size(Patient.name.given)", collection.Of(system.Integer(2))},
+		{"Context", "itemCount", itemCount, "Patient.name.given.itemCount()", collection.Of(system.Integer(2))},
+		{"Lambda argument", "filter", filter, "Patient.name.given.filter($this = 'Mary')", collection.Of(&fhir.String{Value: "Mary"})},
+		{"Empty argument", "greet", greet, "greet({})", collection.Empty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := fhirpath.Compile(tc.expr, fhirpath.AddFunc(tc.fnName, tc.fn))
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tc.expr, err)
+			}
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestCompile_InvalidCustomFunction_ReturnsError(t *testing.T) {
+	valid := func(collection.Collection) (collection.Collection, error) { return nil, nil }
+	testCases := []struct {
+		name    string
+		opts    []fhirpath.CompileOption
+		wantErr error
+	}{
+		{"Not a function", []fhirpath.CompileOption{fhirpath.AddFunc("fn", 42)}, fhirpath.ErrInvalidSignature},
+		{"Missing input", []fhirpath.CompileOption{
+			fhirpath.AddFunc("fn", func(system.String) (collection.Collection, error) { return nil, nil }),
+		}, fhirpath.ErrInvalidSignature},
+		{"Wrong results", []fhirpath.CompileOption{
+			fhirpath.AddFunc("fn", func(collection.Collection) collection.Collection { return nil }),
+		}, fhirpath.ErrInvalidSignature},
+		{"Unsupported parameter", []fhirpath.CompileOption{
+			fhirpath.AddFunc("fn", func(collection.Collection, string) (collection.Collection, error) { return nil, nil }),
+		}, fhirpath.ErrInvalidSignature},
+		{"Variadic input", []fhirpath.CompileOption{
+			fhirpath.AddFunc("fn", func(...collection.Collection) (collection.Collection, error) { return nil, nil }),
+		}, fhirpath.ErrInvalidSignature},
+		{"Invalid name", []fhirpath.CompileOption{fhirpath.AddFunc("my-fn", valid)}, nil},
+		{"FHIRPath function", []fhirpath.CompileOption{fhirpath.AddFunc("extension", valid)}, nil},
+		{"Unimplemented FHIRPath function", []fhirpath.CompileOption{fhirpath.AddFunc("where", valid)}, nil},
+		{"Duplicate function", []fhirpath.CompileOption{
+			fhirpath.AddFuncs(map[string]any{"fn": valid}),
+			fhirpath.AddFunc("fn", valid),
+		}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile("1", tc.opts...)
+
+			if err == nil {
+				t.Fatalf("Compile() error = nil; want error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Compile() error = %v; want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompile_CustomFunctionCall_ReturnsError(t *testing.T) {
+	greet := func(_ collection.Collection, name system.String) (collection.Collection, error) {
+		return collection.Of(system.String("Hello, " + name)), nil
+	}
+	testCases := []struct {
+		name    string
+		expr    string
+		opts    []fhirpath.CompileOption
+		wantErr error
+	}{
+		{"Too few arguments", "greet()", nil, nil},
+		{"Too many arguments", "greet('a', 'b')", nil, nil},
+		{"Literal of wrong type", "greet(1)", nil, fhirpath.ErrTypeMismatch},
+		{"Element of wrong type", "greet(Patient.active)", []fhirpath.CompileOption{fhirpath.WithRootType("Patient")}, fhirpath.ErrTypeMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]fhirpath.CompileOption{fhirpath.AddFunc("greet", greet)}, tc.opts...)
+			_, err := fhirpath.Compile(tc.expr, opts...)
+
+			var compileErr *fhirpath.CompileError
+			if !errors.As(err, &compileErr) {
+				t.Fatalf("Compile(%q) error = %v; want CompileError", tc.expr, err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Compile(%q) error = %v; want %v", tc.expr, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvalCustomFunction_ReturnsError(t *testing.T) {
+	input := &patient.Patient{
+		Name: []*fhir.HumanName{{Family: &fhir.String{Value: "Doe"}}, {Family: &fhir.String{Value: "Roe"}}},
+	}
+	errFailed := errors.New("failed")
+	testCases := []struct {
+		name    string
+		fn      any
+		expr    string
+		wantErr error
+	}{
+		{"Function error", func(collection.Collection) (collection.Collection, error) {
+			return nil, errFailed
+		}, "fn()", errFailed},
+		{"Multiple items", func(collection.Collection, system.String) (collection.Collection, error) {
+			return nil, nil
+		}, "fn(Patient.name.family)", collection.ErrNotSingleton},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fhirpath.MustCompile(tc.expr, fhirpath.AddFunc("fn", tc.fn))
+
+			_, err := path.Eval(context.Background(), input)
+
+			if got, want := err, tc.wantErr; !errors.Is(got, want) {
+				t.Errorf("Eval(%q) error = %v; want %v", tc.expr, got, want)
+			}
+		})
+	}
+}
+
+// newLibrary returns a library of functions for testing, with overloads and a
+// variadic function.
+func newLibrary(t *testing.T) *fhirpath.FunctionLibrary {
+	t.Helper()
+	describe := func(kind string) func(collection.Collection, system.Any) (collection.Collection, error) {
+		return func(_ collection.Collection, value system.Any) (collection.Collection, error) {
+			return collection.Of(system.String(fmt.Sprintf("%v %v", kind, value))), nil
+		}
+	}
+	var lib fhirpath.FunctionLibrary
+	for _, fn := range []fhirpath.Function{
+		{Name: "describe", Func: func(in collection.Collection, value system.String) (collection.Collection, error) {
+			return describe("string")(in, value)
+		}, Params: []string{"value"}, Doc: "Describes the value."},
+		{Name: "describe", Func: func(in collection.Collection, value system.Integer) (collection.Collection, error) {
+			return describe("integer")(in, value)
+		}, Params: []string{"value"}, Doc: "Describes the value."},
+		{Name: "describe", Func: func(in collection.Collection, value system.Decimal) (collection.Collection, error) {
+			return describe("decimal")(in, value)
+		}, Params: []string{"value"}, Doc: "Describes the value."},
+		{Name: "join", Func: func(_ collection.Collection, separator system.String, parts ...system.String) (collection.Collection, error) {
+			var result string
+			for i, part := range parts {
+				if i > 0 {
+					result += string(separator)
+				}
+				result += string(part)
+			}
+			return collection.Of(system.String(result)), nil
+		}, Params: []string{"separator", "parts"}, Doc: "Joins the parts with the separator."},
+	} {
+		if err := lib.Define(fn); err != nil {
+			t.Fatalf("Define(%v) error = %v", fn.Name, err)
+		}
+	}
+	return &lib
+}
+
+func TestEvalFunctionLibrary(t *testing.T) {
+	input := &patient.Patient{
+		Name: []*fhir.HumanName{{Family: &fhir.String{Value: "Doe"}}},
+	}
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Overload by String", "acme_describe('a')", collection.Of(system.String("string a"))},
+		{"Overload by Integer", "acme_describe(1)", collection.Of(system.String("integer 1"))},
+		{"Overload by Decimal", "acme_describe(1.5)", collection.Of(system.String("decimal 1.5"))},
+		{"Overload by FHIR primitive", "acme_describe(Patient.name.family)", collection.Of(system.String("string Doe"))},
+		{"Variadic arguments", "acme_join('-', 'a', 'b', 'c')", collection.Of(system.String("a-b-c"))},
+		{"No variadic arguments", "acme_join('-')", collection.Of(system.String(""))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := fhirpath.Compile(tc.expr, fhirpath.AddLibrary(newLibrary(t), "acme_"))
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tc.expr, err)
+			}
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestCompile_FunctionLibraryCall_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expr    string
+		wantErr error
+	}{
+		{"No overload accepts argument", "acme_describe(true)", fhirpath.ErrTypeMismatch},
+		{"Variadic argument of wrong type", "acme_join('-', 'a', 1)", fhirpath.ErrTypeMismatch},
+		{"Unprefixed name", "describe('a')", fhirpath.ErrUnknownFunction},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile(tc.expr, fhirpath.AddLibrary(newLibrary(t), "acme_"))
+
+			if got, want := err, tc.wantErr; !errors.Is(got, want) {
+				t.Errorf("Compile(%q) error = %v; want %v", tc.expr, got, want)
+			}
+		})
+	}
+}
+
+func TestFunctionLibraryDefine_ReturnsError(t *testing.T) {
+	str := func(collection.Collection, system.String) (collection.Collection, error) { return nil, nil }
+	lambda := func(collection.Collection, fhirpath.Lambda) (collection.Collection, error) { return nil, nil }
+	testCases := []struct {
+		name    string
+		fn      fhirpath.Function
+		wantErr error
+	}{
+		{"Same parameters", fhirpath.Function{Name: "fn", Func: str}, fhirpath.ErrInvalidSignature},
+		{"Expression and value parameters", fhirpath.Function{Name: "fn", Func: lambda}, fhirpath.ErrInvalidSignature},
+		{"Wrong number of parameter names", fhirpath.Function{Name: "fn", Func: str, Params: []string{"a", "b"}}, fhirpath.ErrInvalidSignature},
+		{"Invalid name", fhirpath.Function{Name: "my fn", Func: str}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var lib fhirpath.FunctionLibrary
+			if err := lib.Define(fhirpath.Function{Name: "fn", Func: str}); err != nil {
+				t.Fatalf("Define() error = %v", err)
+			}
+
+			err := lib.Define(tc.fn)
+
+			if err == nil {
+				t.Fatalf("Define() error = nil; want error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Define() error = %v; want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompile_InvalidFunctionLibrary_ReturnsError(t *testing.T) {
+	noop := func(collection.Collection) (collection.Collection, error) {
+		return nil, nil
+	}
+	var builtin, reserved fhirpath.FunctionLibrary
+	if err := builtin.Define(fhirpath.Function{Name: "extension", Func: noop}); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	if err := reserved.Define(fhirpath.Function{Name: "select", Func: noop}); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	testCases := []struct {
+		name string
+		opts []fhirpath.CompileOption
+	}{
+		{"Nil library", []fhirpath.CompileOption{fhirpath.AddLibrary(nil, "acme_")}},
+		{"Invalid prefix", []fhirpath.CompileOption{fhirpath.AddLibrary(newLibrary(t), "acme.")}},
+		{"FHIRPath function", []fhirpath.CompileOption{fhirpath.AddLibrary(&builtin, "")}},
+		{"Unimplemented FHIRPath function", []fhirpath.CompileOption{fhirpath.AddLibrary(&reserved, "")}},
+		{"Same library twice", []fhirpath.CompileOption{
+			fhirpath.AddLibrary(newLibrary(t), "acme_"),
+			fhirpath.AddLibrary(newLibrary(t), "acme_"),
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile("1", tc.opts...)
+
+			if err == nil {
+				t.Errorf("Compile() error = nil; want error")
+			}
+		})
+	}
+}
+
+func TestComplete_FunctionLibrary(t *testing.T) {
+	completions, err := fhirpath.Complete("acme_j", 6, "Patient", fhirpath.AddLibrary(newLibrary(t), "acme_"))
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	want := []fhirpath.Completion{{
+		Label:         "acme_join",
+		Kind:          fhirpath.CompletionFunction,
+		Detail:        "acme_join(separator : System.String, parts : System.String...) : collection",
+		Documentation: "Joins the parts with the separator.",
+	}}
+	if diff := cmp.Diff(want, completions); diff != "" {
+		t.Errorf("Complete() mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestEvalDefinedFunction(t *testing.T) {
+	each := func(in collection.Collection, fn fhirpath.Lambda) (collection.Collection, error) {
+		var result collection.Collection
+		for i, item := range in {
+			got, err := fn.Evaluate(item, i)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, got...)
+		}
+		return result, nil
+	}
+	input := &patient.Patient{
+		Name: []*fhir.HumanName{{
+			Family: &fhir.String{Value: "Doe"},
+			Given:  []*fhir.String{{Value: "Jane"}, {Value: "Mary"}},
+		}},
+	}
+	opts := []fhirpath.CompileOption{
+		fhirpath.DefineFunction("fullName", "family + ', ' + %given",
+			fhirpath.Parameter{Name: "$this", Type: "HumanName"},
+			fhirpath.Parameter{Name: "given", Type: "System.String"},
+		),
+		fhirpath.DefineFunction("displayName", "given.first() & ' ' & family",
+			fhirpath.Parameter{Name: "$this", Type: "HumanName"},
+		),
+		fhirpath.DefineFunction("quadruple", "double().double()"),
+		fhirpath.DefineFunction("double", "$this * 2"),
+		fhirpath.DefineFunction("add", "$this + %n", fhirpath.Parameter{Name: "n"}),
+		fhirpath.DefineFunction("shout", "$this + '!'"),
+		fhirpath.AddFunc("each", each),
+	}
+	testCases := []struct {
+		name string
+		expr string
+		want collection.Collection
+	}{
+		{"Parameter bound as variable", "Patient.name.fullName('John')", collection.Of(system.String("Doe, John"))},
+		{"Invokes function in body", "Patient.name.displayName()", collection.Of(system.String("Jane Doe"))},
+		{"This is the input", "(3).double()", collection.Of(system.Integer(6))},
+		{"Invokes defined function", "(3).quadruple()", collection.Of(system.Integer(12))},
+		{"Parameter of any type", "(1).add(2)", collection.Of(system.Integer(3))},
+		{"Argument evaluated against input", "(1).add($this)", collection.Of(system.Integer(2))},
+		{"This within lambda is the input", "Patient.name.each(family.shout())", collection.Of(system.String("Doe!"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := fhirpath.Compile(tc.expr, opts...)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tc.expr, err)
+			}
+
+			got, err := path.Eval(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Eval(%q) mismatch (-got +want):\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
+
+func TestCompile_DefinedFunction_CompiledPerConfiguration(t *testing.T) {
+	opt := fhirpath.DefineFunction("low", "$this.lowBoundary()")
+
+	if _, err := fhirpath.Compile("(1.5).low()", opt, fhirpath.N2()); err != nil {
+		t.Fatalf("Compile() with N2 error = %v", err)
+	}
+	_, err := fhirpath.Compile("(1.5).low()", opt, fhirpath.N1())
+
+	if got, want := err, fhirpath.ErrUnknownFunction; !errors.Is(got, want) {
+		t.Errorf("Compile() with N1 error = %v; want %v", got, want)
+	}
+}
+
+func TestCompile_DefinedFunction_ReturnsError(t *testing.T) {
+	fullName := fhirpath.DefineFunction("fullName", "family + ', ' + %given",
+		fhirpath.Parameter{Name: "$this", Type: "HumanName"},
+		fhirpath.Parameter{Name: "given", Type: "System.String"},
+	)
+	testCases := []struct {
+		name    string
+		expr    string
+		opts    []fhirpath.CompileOption
+		wantErr error
+	}{
+		{"Invokes itself", "1", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("loop", "loop()"),
+		}, fhirpath.ErrRecursiveFunction},
+		{"Invokes itself indirectly", "1", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("ping", "pong()"),
+			fhirpath.DefineFunction("pong", "ping()"),
+		}, fhirpath.ErrRecursiveFunction},
+		{"Body is invalid", "1", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("broken", "family +"),
+		}, fhirpath.ErrSyntax},
+		{"Body references undeclared variable", "1", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("greet", "'Hello, ' + %name"),
+		}, fhirpath.ErrUndeclaredVariable},
+		{"Body references unknown element", "1", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("surname", "fmaily", fhirpath.Parameter{Name: "$this", Type: "HumanName"}),
+		}, fhirpath.ErrUnknownElement},
+		{"Argument of wrong type", "Patient.name.fullName(1)", []fhirpath.CompileOption{fullName}, fhirpath.ErrTypeMismatch},
+		{"Input of wrong type", "Patient.fullName('John')", []fhirpath.CompileOption{
+			fullName, fhirpath.WithRootType("Patient"),
+		}, fhirpath.ErrTypeMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile(tc.expr, tc.opts...)
+
+			var compileErr *fhirpath.CompileError
+			if !errors.As(err, &compileErr) {
+				t.Fatalf("Compile(%q) error = %v; want CompileError", tc.expr, err)
+			}
+			if got, want := err, tc.wantErr; !errors.Is(got, want) {
+				t.Errorf("Compile(%q) error = %v; want %v", tc.expr, got, want)
+			}
+		})
+	}
+}
+
+func TestCompile_InvalidDefinedFunction_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []fhirpath.CompileOption
+	}{
+		{"Invalid name", []fhirpath.CompileOption{fhirpath.DefineFunction("full name", "family")}},
+		{"FHIRPath function", []fhirpath.CompileOption{fhirpath.DefineFunction("extension", "family")}},
+		{"Unimplemented FHIRPath function", []fhirpath.CompileOption{fhirpath.DefineFunction("exists", "family")}},
+		{"Defined twice", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("surname", "family"),
+			fhirpath.DefineFunction("surname", "family"),
+		}},
+		{"Defined custom function", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("surname", "family"),
+			fhirpath.AddFunc("surname", func(in collection.Collection) (collection.Collection, error) { return in, nil }),
+		}},
+		{"Parameter with invalid name", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("greet", "%name", fhirpath.Parameter{Name: "first-name"}),
+		}},
+		{"Parameter named for FHIR variable", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("greet", "%resource", fhirpath.Parameter{Name: "resource"}),
+		}},
+		{"Parameter declared twice", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("greet", "%name", fhirpath.Parameter{Name: "name"}, fhirpath.Parameter{Name: "name"}),
+		}},
+		{"Parameter of unknown type", []fhirpath.CompileOption{
+			fhirpath.DefineFunction("greet", "%name", fhirpath.Parameter{Name: "name", Type: "Strnig"}),
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fhirpath.Compile("1", tc.opts...)
+
+			if err == nil {
+				t.Errorf("Compile() error = nil; want error")
+			}
+		})
+	}
+}
+
+func TestComplete_DefinedFunction(t *testing.T) {
+	opts := fhirpath.DefineFunction("surname", "family", fhirpath.Parameter{Name: "$this", Type: "HumanName"})
+
+	completions, err := fhirpath.Complete("Patient.name.sur", 16, "Patient", opts)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	want := []fhirpath.Completion{{
+		Label:         "surname",
+		Kind:          fhirpath.CompletionFunction,
+		Detail:        "surname() : List<FHIR.string>",
+		Documentation: "Defined in FHIRPath as 'family'.",
+	}}
+	if diff := cmp.Diff(want, completions); diff != "" {
+		t.Errorf("Complete() mismatch (-want +got):\n%v", diff)
+	}
+}
@@ -2,13 +2,13 @@ module github.com/friendly-fhir/go-fhirpath
 
 go 1.22.3
 
-require github.com/antlr4-go/antlr/v4 v4.13.1
-
 require (
-	github.com/google/go-cmp v0.6.0 // indirect
-	github.com/shopspring/decimal v1.4.0 // indirect
+	github.com/antlr4-go/antlr/v4 v4.13.1
+	github.com/shopspring/decimal v1.4.0
 )
 
+require github.com/google/go-cmp v0.6.0
+
 require (
 	github.com/friendly-fhir/go-fhir v0.0.0-20240627230005-9ef2174c1f29
 	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842 // indirect
@@ -0,0 +1,159 @@
+/*
+Package compile provides the compiler that transforms FHIRPath source text into
+an evaluable expression tree.
+
+Source text is parsed with the generated ANTLR parser, and the resulting parse
+tree is then lowered into the nodes defined in the expr package.
+*/
+package compile
+
+import (
+	"fmt"
+
+	"github.com/antlr4-go/antlr/v4"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/funcs"
+	"github.com/friendly-fhir/go-fhirpath/internal/parser"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+)
+
+// Options are the options that control how an expression is compiled.
+type Options struct {
+	// Functions are the functions that may be invoked by the expression. If
+	// nil, the functions of FHIRPath N1 are used.
+	Functions funcs.Table
+
+	// Lenient allows choice elements to be named by their type, as they are in
+	// the FHIR JSON format -- e.g. 'Observation.valueQuantity'.
+	Lenient bool
+
+	// Variables are the types of the environment variables that may be
+	// referenced by the expression, in addition to those defined by FHIR. If
+	// nil, any environment variable may be referenced.
+	Variables map[string]reflect.TypeSpecifier
+
+	// RootType is the type of the input that the expression is evaluated
+	// against, such as "Patient". If set, the type of every expression is
+	// inferred from it, and expressions that can never match -- such as a
+	// misspelled element -- are an error. If empty, the types are unchecked.
+	RootType reflect.TypeSpecifier
+}
+
+// Compile parses and compiles the FHIRPath source text into an expression
+// tree, and infers the type of its result. If the source is not a valid
+// FHIRPath expression, an *Error is returned.
+func Compile(source string, opts Options) (expr.Expression, types.Type, error) {
+	c, err := newCompiler(opts)
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	tree, err := parse(source)
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	return c.expression(tree.Expression())
+}
+
+// newCompiler returns a compiler that compiles with the options.
+func newCompiler(opts Options) (*compiler, error) {
+	root := types.Unknown.WithList(true)
+	if opts.RootType != "" {
+		typ, ok := types.Parse(opts.RootType)
+		if !ok {
+			return nil, fmt.Errorf("%w '%v'", ErrUnknownType, opts.RootType)
+		}
+		root = typ
+	}
+	if opts.Functions == nil {
+		opts.Functions = funcs.N1
+	}
+	return &compiler{
+		functions: opts.Functions,
+		lenient:   opts.Lenient,
+		variables: opts.Variables,
+		checked:   opts.RootType != "",
+		root:      root,
+		focus:     root,
+	}, nil
+}
+
+// parse parses the FHIRPath source text. If the source is not valid, the first
+// syntax error is returned along with the tree that the parser recovered.
+func parse(source string) (parser.IPathContext, error) {
+	listener := &errorListener{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+
+	lexer := parser.NewfhirpathLexer(antlr.NewInputStream(source))
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(listener)
+
+	p := parser.NewfhirpathParser(antlr.NewCommonTokenStream(&longNumbers{Lexer: lexer}, antlr.TokenDefaultChannel))
+	p.RemoveErrorListeners()
+	p.AddErrorListener(listener)
+
+	tree := p.Path()
+	return tree, listener.err
+}
+
+// longNumbers is a lexer that joins an integer NUMBER token with an 'L' that
+// immediately follows it into a single NUMBER token, such as '123L', which
+// the compiler parses as a Long literal. The generated grammar predates Long
+// literals, so they are recognized here rather than by a token of their own.
+type longNumbers struct {
+	antlr.Lexer
+	next antlr.Token
+}
+
+func (l *longNumbers) NextToken() antlr.Token {
+	token := l.pop()
+	if !isDigits(token.GetText()) {
+		return token
+	}
+	next := l.pop()
+	if next.GetText() != "L" || next.GetStart() != token.GetStop()+1 {
+		l.next = next
+		return token
+	}
+	token.SetText(token.GetText() + "L")
+	return token
+}
+
+// pop returns the token that was read ahead of the last one, if any, or the
+// next token of the underlying lexer.
+func (l *longNumbers) pop() antlr.Token {
+	if token := l.next; token != nil {
+		l.next = nil
+		return token
+	}
+	return l.Lexer.NextToken()
+}
+
+// isDigits returns whether the text is a non-empty sequence of decimal digits.
+func isDigits(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return text != ""
+}
+
+// errorListener is an ANTLR error listener that records the first syntax error
+// reported by the lexer or parser.
+type errorListener struct {
+	*antlr.DefaultErrorListener
+	err error
+}
+
+func (l *errorListener) SyntaxError(_ antlr.Recognizer, _ any, line, column int, msg string, _ antlr.RecognitionException) {
+	if l.err != nil {
+		return
+	}
+	l.err = &Error{
+		Line:   line,
+		Column: column,
+		Err:    syntaxError(msg),
+	}
+}
+
+var _ antlr.ErrorListener = (*errorListener)(nil)
@@ -0,0 +1,630 @@
+package compile
+
+import (
+	"fmt"
+	"math"
+	stdreflect "reflect"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/esc"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/funcs"
+	"github.com/friendly-fhir/go-fhirpath/internal/parser"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/namespace"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// compiler lowers a FHIRPath parse tree into an expression tree, inferring the
+// static type of each expression as it does.
+type compiler struct {
+	functions funcs.Table
+	lenient   bool
+	variables map[string]reflect.TypeSpecifier
+
+	// checked indicates that the type of the root of the expression is known,
+	// so that inferred types are checked against one another.
+	checked bool
+
+	// root is the type of the input of the whole expression.
+	root types.Type
+
+	// focus is the type of the input of the expression being compiled.
+	focus types.Type
+
+	// probe, if set, receives the scope of the identifier named by the marker
+	// when the compiler reaches it. While probing, the compiler recovers from
+	// the parts of the expression that it cannot compile, so that the marker
+	// may be reached in incomplete or invalid source.
+	probe *Scope
+
+	// probed indicates that the marker was reached.
+	probed bool
+
+	// definer, if set, compiles the functions defined by FHIRPath expressions
+	// that the expression invokes, which are not yet in the functions.
+	definer *definer
+}
+
+func (c *compiler) expression(node parser.IExpressionContext) (expr.Expression, types.Type, error) {
+	switch n := node.(type) {
+	case *parser.TermExpressionContext:
+		return c.term(n.Term())
+	case *parser.InvocationExpressionContext:
+		return c.invocationExpression(n)
+	case *parser.PolarityExpressionContext:
+		return c.polarity(n)
+	case *parser.MultiplicativeExpressionContext:
+		return c.arithmetic(n, n.Expression(0), n.Expression(1))
+	case *parser.AdditiveExpressionContext:
+		return c.arithmetic(n, n.Expression(0), n.Expression(1))
+	case *parser.InequalityExpressionContext:
+		return c.inequality(n)
+	case *parser.EqualityExpressionContext:
+		return c.equality(n)
+	case *parser.MembershipExpressionContext:
+		return c.membership(n)
+	case *parser.UnionExpressionContext:
+		return c.union(n)
+	case *parser.IndexerExpressionContext:
+		return c.indexer(n)
+	case *parser.TypeExpressionContext:
+		return c.typeExpression(n)
+	}
+	return c.unimplemented(node)
+}
+
+func (c *compiler) invocationExpression(node *parser.InvocationExpressionContext) (expr.Expression, types.Type, error) {
+	source, typ, err := c.expression(node.Expression())
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	if method, ok := node.Invocation().(*parser.FunctionInvocationContext); ok && isTerminologies(source) {
+		return c.terminologies(method.Function())
+	}
+	if member, ok := node.Invocation().(*parser.MemberInvocationContext); ok && isTerminologies(source) && c.probing(member.Identifier()) {
+		c.reach(Scope{Terminologies: true})
+		return source, types.Unknown, nil
+	}
+	focus := c.focus
+	c.focus = typ
+	invocation, typ, err := c.invocation(node.Invocation(), false)
+	c.focus = focus
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	return &expr.Invocation{
+		Source:     source,
+		Invocation: invocation,
+	}, typ, nil
+}
+
+func (c *compiler) invocation(node parser.IInvocationContext, root bool) (expr.Expression, types.Type, error) {
+	switch n := node.(type) {
+	case *parser.MemberInvocationContext:
+		return c.member(n.Identifier(), root)
+	case *parser.FunctionInvocationContext:
+		return c.function(n.Function())
+	case *parser.ThisInvocationContext:
+		return expr.This{}, c.focus, nil
+	case *parser.IndexInvocationContext:
+		return expr.Index{}, types.Integer, nil
+	}
+	return c.unimplemented(node)
+}
+
+// member compiles the navigation into the named element of the focus. As the
+// first identifier of a path, the name may instead be that of the type of the
+// focus, which selects the focus itself -- e.g. 'Patient' in 'Patient.name'.
+func (c *compiler) member(node parser.IIdentifierContext, root bool) (expr.Expression, types.Type, error) {
+	name, err := identifier(node)
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	member := &expr.Member{Name: name, Root: root, Lenient: c.lenient}
+	if c.probing(node) {
+		c.reach(Scope{Focus: c.focus, Root: root})
+		return member, types.Unknown, nil
+	}
+	if root && c.focus.Name == reflect.TypeSpecifier("FHIR."+name) {
+		return member, c.focus, nil
+	}
+	typ, ok := c.focus.Element(name)
+	if !ok && c.lenient {
+		typ, ok = c.focus.ChoiceElement(name)
+	}
+	if !ok && c.checked {
+		if _, isType := types.Resolve(name); root && isType {
+			return nil, types.Unknown, errorfAt(node, "%w: type '%v' does not match the input type %v", ErrTypeMismatch, name, c.focus.Item())
+		}
+		return nil, types.Unknown, errorfAt(node, "%w '%v' on %v", ErrUnknownElement, name, c.focus.Item())
+	}
+	return member, typ, nil
+}
+
+// isTerminologies returns whether the expression is the FHIR '%terminologies'
+// object, whose methods are compiled from a separate table of functions.
+func isTerminologies(e expr.Expression) bool {
+	constant, ok := e.(*expr.ExternalConstant)
+	return ok && constant.Name == envcontext.Terminologies
+}
+
+// terminologies compiles the invocation of a method of '%terminologies'. The
+// method is evaluated against the input of the invocation, since the object
+// itself carries no state beyond the configured terminology.
+func (c *compiler) terminologies(node parser.IFunctionContext) (expr.Expression, types.Type, error) {
+	name, err := identifier(node.Identifier())
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	fn, ok := funcs.Terminologies[name]
+	if !ok {
+		return nil, types.Unknown, errorfAt(node, "%w '%%terminologies.%v'", ErrUnknownFunction, name)
+	}
+	return c.call(node, name, fn)
+}
+
+func (c *compiler) function(node parser.IFunctionContext) (expr.Expression, types.Type, error) {
+	name, err := identifier(node.Identifier())
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	fn, ok := c.functions[name]
+	if _, defined := c.definitions()[name]; !ok && defined {
+		if fn, err = c.definer.function(name); err != nil {
+			return nil, types.Unknown, errorAt(node, err)
+		}
+		ok = true
+	}
+	if !ok && c.probe != nil {
+		return c.skip(node, c.focus.Item())
+	}
+	if !ok {
+		if _, ok := funcs.N2[name]; ok {
+			return nil, types.Unknown, errorfAt(node, "%w '%v': requires FHIRPath N2", ErrUnknownFunction, name)
+		}
+		if _, ok := funcs.Spec[name]; ok {
+			return nil, types.Unknown, errorfAt(node, "%w: function '%v'", ErrUnimplemented, name)
+		}
+		return nil, types.Unknown, errorfAt(node, "%w '%v'", ErrUnknownFunction, name)
+	}
+	return c.call(node, name, fn)
+}
+
+// definitions returns the definitions of the functions that the compiler may
+// compile as they are invoked.
+func (c *compiler) definitions() map[string]Definition {
+	if c.definer == nil {
+		return nil
+	}
+	return c.definer.definitions
+}
+
+// call compiles the invocation of the function with its arguments, on an input
+// of the type of the focus.
+func (c *compiler) call(node parser.IFunctionContext, name string, fn *funcs.Function) (expr.Expression, types.Type, error) {
+	var params []parser.IExpressionContext
+	if list := node.ParamList(); list != nil {
+		params = list.AllExpression()
+	}
+	if (len(params) < fn.MinArgs || len(params) > fn.MaxArgs) && c.probe == nil {
+		return nil, types.Unknown, errorfAt(node, "function '%v' takes %v arguments, got %d", name, arity(fn), len(params))
+	}
+	input := c.focus
+	if c.checked && !fn.Accepts(input) {
+		return nil, types.Unknown, errorfAt(node, "%w: function '%v' cannot be invoked on %v", ErrTypeMismatch, name, input.Item())
+	}
+
+	args, argTypes, err := c.arguments(name, params, fn)
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	if !fn.AcceptsArgs(argTypes) && c.probe == nil {
+		return nil, types.Unknown, errorfAt(node, "%w: no overload of function '%v' accepts arguments (%v)", ErrTypeMismatch, name, typeList(argTypes))
+	}
+	result := types.Unknown
+	if fn.Result != nil {
+		result = fn.Result(input, argTypes)
+	}
+	if fn.Lambda != nil {
+		return &expr.LambdaCall{
+			Name: name,
+			Func: fn.Lambda,
+			Args: args,
+		}, result, nil
+	}
+	return &expr.Call{
+		Name: name,
+		Func: fn.Func,
+		Args: args,
+	}, result, nil
+}
+
+// arguments compiles the arguments of a function. The arguments of a function
+// are evaluated against its input, or against each item of its input if they
+// are passed to the function unevaluated.
+func (c *compiler) arguments(name string, params []parser.IExpressionContext, fn *funcs.Function) ([]expr.Expression, []types.Type, error) {
+	focus := c.focus
+	defer func() { c.focus = focus }()
+
+	args := make([]expr.Expression, 0, len(params))
+	argTypes := make([]types.Type, 0, len(params))
+	for i, param := range params {
+		switch {
+		case fn.TypeArg:
+			c.focus = types.Unknown
+		case fn.IsLambda(i):
+			c.focus = focus.Item()
+		default:
+			c.focus = focus
+		}
+		arg, typ, err := c.expression(param)
+		if err != nil {
+			return nil, nil, err
+		}
+		if p, ok := fn.Param(i); ok && !typ.Item().AssignableTo(p.Type) && c.probe == nil {
+			return nil, nil, errorfAt(param, "%w: argument %d of function '%v' expects %v, got %v", ErrTypeMismatch, i+1, name, p.Type, typ.Item())
+		}
+		if fn.TypeArg {
+			typ, err = c.typeSpecifier(param, arg)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		args = append(args, arg)
+		argTypes = append(argTypes, typ)
+	}
+	return args, argTypes, nil
+}
+
+// typeSpecifier returns the type named by a type specifier argument, such as
+// the 'Quantity' of 'ofType(Quantity)'.
+func (c *compiler) typeSpecifier(node parser.IExpressionContext, arg expr.Expression) (types.Type, error) {
+	specifier, ok := expr.TypeSpecifier(arg)
+	if !ok {
+		return types.Unknown, nil
+	}
+	name, ok := types.Resolve(specifier)
+	if !ok && c.checked {
+		return types.Unknown, errorfAt(node, "%w '%v'", ErrUnknownType, specifier)
+	}
+	return types.Type{Name: name}, nil
+}
+
+// typeList formats the types of the items of the arguments of a function.
+func typeList(args []types.Type) string {
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		names = append(names, arg.Item().String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// arity formats the number of arguments that a function accepts.
+func arity(fn *funcs.Function) string {
+	if fn.MinArgs == fn.MaxArgs {
+		return fmt.Sprint(fn.MinArgs)
+	}
+	if fn.MaxArgs == math.MaxInt {
+		return fmt.Sprintf("at least %d", fn.MinArgs)
+	}
+	return fmt.Sprintf("%d to %d", fn.MinArgs, fn.MaxArgs)
+}
+
+func (c *compiler) inequality(node *parser.InequalityExpressionContext) (expr.Expression, types.Type, error) {
+	left, right, err := c.comparands(node, node.Expression(0), node.Expression(1))
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	return &expr.Inequality{
+		Operator: operator(node),
+		Left:     left,
+		Right:    right,
+	}, types.Boolean, nil
+}
+
+func (c *compiler) polarity(node *parser.PolarityExpressionContext) (expr.Expression, types.Type, error) {
+	operand, typ, err := c.expression(node.Expression())
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	return &expr.Polarity{
+		Operator:   node.GetChild(0).(antlr.ParseTree).GetText(),
+		Expression: operand,
+	}, typ, nil
+}
+
+func (c *compiler) arithmetic(node antlr.ParserRuleContext, lhs, rhs parser.IExpressionContext) (expr.Expression, types.Type, error) {
+	left, leftType, err := c.expression(lhs)
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	right, rightType, err := c.expression(rhs)
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	op := operator(node)
+	typ := types.Arithmetic(op, leftType.Item(), rightType.Item())
+	if c.checked && leftType.IsKnown() && rightType.IsKnown() && !typ.IsKnown() {
+		return nil, types.Unknown, errorfAt(node, "%w: operator '%v' cannot be applied to %v and %v", ErrTypeMismatch, op, leftType.Item(), rightType.Item())
+	}
+	return &expr.Arithmetic{
+		Operator: op,
+		Left:     left,
+		Right:    right,
+	}, typ, nil
+}
+
+func (c *compiler) equality(node *parser.EqualityExpressionContext) (expr.Expression, types.Type, error) {
+	op := operator(node)
+	if op != "=" && op != "!=" {
+		return c.unimplemented(node)
+	}
+	left, right, err := c.comparands(node, node.Expression(0), node.Expression(1))
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	return &expr.Equality{
+		Operator: op,
+		Left:     left,
+		Right:    right,
+	}, types.Boolean, nil
+}
+
+func (c *compiler) membership(node *parser.MembershipExpressionContext) (expr.Expression, types.Type, error) {
+	left, right, err := c.comparands(node, node.Expression(0), node.Expression(1))
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	return &expr.Membership{
+		Operator: operator(node),
+		Left:     left,
+		Right:    right,
+	}, types.Boolean, nil
+}
+
+// union compiles the merge of two collections. The result is of the type of
+// the items of both operands, if they agree, or of an unknown type otherwise.
+func (c *compiler) union(node *parser.UnionExpressionContext) (expr.Expression, types.Type, error) {
+	left, leftType, err := c.expression(node.Expression(0))
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	right, rightType, err := c.expression(node.Expression(1))
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	typ := types.Unknown
+	if leftType.Item() == rightType.Item() {
+		typ = leftType.Item()
+	}
+	return &expr.Union{
+		Left:  left,
+		Right: right,
+	}, typ.WithList(true), nil
+}
+
+// typeExpression compiles the test or cast of the single item of an operand
+// to a type, with the 'is' or 'as' operator -- e.g. 'value as Quantity'.
+func (c *compiler) typeExpression(node *parser.TypeExpressionContext) (expr.Expression, types.Type, error) {
+	source, _, err := c.expression(node.Expression())
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	specifier := node.TypeSpecifier().GetText()
+	typ := types.Unknown
+	if name, ok := types.Resolve(specifier); ok {
+		typ = types.Type{Name: name}
+	} else if c.checked && c.probe == nil {
+		return nil, types.Unknown, errorfAt(node.TypeSpecifier(), "%w '%v'", ErrUnknownType, specifier)
+	}
+	op := operator(node)
+	if op == "is" {
+		typ = types.Boolean
+	}
+	return &expr.TypeExpression{
+		Operator: op,
+		Source:   source,
+		Type:     specifier,
+	}, typ, nil
+}
+
+// indexer compiles the selection of a single item of a collection by its
+// index, which must be an Integer -- e.g. 'name[0]'.
+func (c *compiler) indexer(node *parser.IndexerExpressionContext) (expr.Expression, types.Type, error) {
+	source, sourceType, err := c.expression(node.Expression(0))
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	index, indexType, err := c.expression(node.Expression(1))
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	if c.checked && indexType.IsKnown() && indexType.Item() != types.Integer {
+		return nil, types.Unknown, errorfAt(node, "%w: cannot index with %v", ErrTypeMismatch, indexType.Item())
+	}
+	return &expr.Indexer{
+		Source: source,
+		Index:  index,
+	}, sourceType.Item(), nil
+}
+
+// comparands compiles the operands of an operator that compares the items of
+// its operands, such as '=' or 'in'. Operands of types that can never compare
+// equal to one another, such as 'gender = 1', are an error.
+func (c *compiler) comparands(node antlr.ParserRuleContext, lhs, rhs parser.IExpressionContext) (left, right expr.Expression, err error) {
+	left, leftType, err := c.expression(lhs)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, rightType, err := c.expression(rhs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.checked && !leftType.Item().ComparableTo(rightType.Item()) {
+		return nil, nil, errorfAt(node, "%w: cannot compare %v with %v", ErrTypeMismatch, leftType.Item(), rightType.Item())
+	}
+	return left, right, nil
+}
+
+func (c *compiler) term(node parser.ITermContext) (expr.Expression, types.Type, error) {
+	switch n := node.(type) {
+	case *parser.LiteralTermContext:
+		return c.literal(n.Literal())
+	case *parser.ParenthesizedTermContext:
+		return c.expression(n.Expression())
+	case *parser.InvocationTermContext:
+		return c.invocation(n.Invocation(), true)
+	case *parser.ExternalConstantTermContext:
+		return c.externalConstant(n.ExternalConstant())
+	}
+	return c.unimplemented(node)
+}
+
+func (c *compiler) externalConstant(node parser.IExternalConstantContext) (expr.Expression, types.Type, error) {
+	name, err := constantName(node)
+	if err != nil {
+		return nil, types.Unknown, err
+	}
+	if c.variables != nil && !envcontext.IsStandard(name) {
+		if _, ok := c.variables[name]; !ok {
+			return nil, types.Unknown, errorfAt(node, "%w '%%%v'", ErrUndeclaredVariable, name)
+		}
+	}
+	return &expr.ExternalConstant{Name: name}, c.constantType(name), nil
+}
+
+// constantType returns the type of the named environment variable. Declared
+// variables are collections of their declared type.
+func (c *compiler) constantType(name string) types.Type {
+	if specifier, ok := c.variables[name]; ok {
+		typ, _ := types.Parse(specifier)
+		return typ.WithList(true)
+	}
+	switch name {
+	case envcontext.Context:
+		return c.root
+	case envcontext.Resource, envcontext.RootResource:
+		if resource := (types.Type{Name: "FHIR.Resource"}); c.root.Is(resource) {
+			return c.root
+		}
+		return types.Type{Name: "FHIR.Resource"}
+	case envcontext.Terminologies:
+		return types.Unknown
+	}
+	if _, ok := envcontext.Standard(name); ok {
+		return types.String
+	}
+	return types.Unknown.WithList(true)
+}
+
+// constantName returns the name of the external constant, which is either an
+// identifier or a string -- e.g. '%resource' or "%'vs-name'".
+func constantName(node parser.IExternalConstantContext) (string, error) {
+	if str := node.STRING(); str != nil {
+		name, err := system.ParseString(str.GetText())
+		if err != nil {
+			return "", errorAt(node, err)
+		}
+		return string(name), nil
+	}
+	return identifier(node.Identifier())
+}
+
+func (c *compiler) literal(node parser.ILiteralContext) (expr.Expression, types.Type, error) {
+	value, err := c.literalValue(node)
+	if err != nil {
+		return nil, types.Unknown, errorAt(node, err)
+	}
+	if value == nil {
+		return &expr.Literal{Value: collection.Empty}, types.Unknown, nil
+	}
+	return &expr.Literal{Value: collection.Of(value)}, literalType(value), nil
+}
+
+// literalType returns the type of the value of a literal.
+func literalType(value system.Any) types.Type {
+	return types.Type{Name: namespace.System.QualifiedName(stdreflect.TypeOf(value))}
+}
+
+func (c *compiler) literalValue(node parser.ILiteralContext) (system.Any, error) {
+	text := node.GetText()
+	switch n := node.(type) {
+	case *parser.NullLiteralContext:
+		return nil, nil
+	case *parser.BooleanLiteralContext:
+		return system.ParseBoolean(text)
+	case *parser.StringLiteralContext:
+		return system.ParseString(text)
+	case *parser.NumberLiteralContext:
+		if long, ok := strings.CutSuffix(text, "L"); ok {
+			return system.ParseInteger64(long)
+		}
+		if strings.Contains(text, ".") {
+			return system.ParseDecimal(text)
+		}
+		return system.ParseInteger(text)
+	case *parser.DateLiteralContext:
+		return system.ParseDate(strings.TrimPrefix(text, "@"))
+	case *parser.DateTimeLiteralContext:
+		return system.ParseDateTime(strings.TrimPrefix(text, "@"))
+	case *parser.TimeLiteralContext:
+		return system.ParseTime(strings.TrimPrefix(text, "@T"))
+	case *parser.QuantityLiteralContext:
+		return c.quantity(n.Quantity())
+	}
+	return nil, fmt.Errorf("%w: literal '%v'", ErrUnimplemented, text)
+}
+
+func (c *compiler) quantity(node parser.IQuantityContext) (system.Quantity, error) {
+	value, err := system.ParseDecimal(node.NUMBER().GetText())
+	if err != nil {
+		return system.Quantity{}, err
+	}
+	unit := node.Unit()
+	if unit == nil {
+		return system.NewQuantity(value, ""), nil
+	}
+	if str := unit.STRING(); str != nil {
+		ucum, err := system.ParseString(str.GetText())
+		if err != nil {
+			return system.Quantity{}, err
+		}
+		return system.NewQuantity(value, string(ucum)), nil
+	}
+	return system.NewQuantity(value, unit.GetText()), nil
+}
+
+// identifier returns the name of an identifier, removing the backticks and
+// escapes of delimited identifiers.
+func identifier(node parser.IIdentifierContext) (string, error) {
+	text := node.GetText()
+	if node.DELIMITEDIDENTIFIER() == nil {
+		return text, nil
+	}
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "`"), "`")
+	name, err := esc.Parse(text)
+	if err != nil {
+		return "", errorAt(node, err)
+	}
+	return name, nil
+}
+
+// unimplemented returns an error for a parse-tree node whose semantics are not
+// yet supported by the compiler.
+func (c *compiler) unimplemented(node antlr.ParserRuleContext) (expr.Expression, types.Type, error) {
+	if c.probe != nil {
+		return c.skip(node, c.focus)
+	}
+	return nil, types.Unknown, errorfAt(node, "%w: expression '%v'", ErrUnimplemented, node.GetText())
+}
+
+// operator returns the text of the operator token of a binary expression,
+// which is always the second child of the node.
+func operator(node antlr.ParserRuleContext) string {
+	return node.GetChild(1).(antlr.ParseTree).GetText()
+}
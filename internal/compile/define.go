@@ -0,0 +1,185 @@
+package compile
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/funcs"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+)
+
+// Definition is a function that is defined by a FHIRPath expression, its body,
+// which is evaluated against the input of the function.
+type Definition struct {
+	// Body is the FHIRPath source text of the function. Within it, '$this'
+	// refers to the input of the function, and each parameter to the value of
+	// its argument, as an environment variable -- e.g. '%prefix'.
+	Body string
+
+	// Params are the parameters of the function, in order.
+	Params []Parameter
+
+	// Input is the type of the items of the input of the function. If set, the
+	// body is type-checked against it, and the function may only be invoked
+	// on inputs of the type. If empty, the input is of an unknown type.
+	Input reflect.TypeSpecifier
+}
+
+// Parameter is a parameter of a function defined by a FHIRPath expression.
+type Parameter struct {
+	// Name is the name of the environment variable that the argument is bound
+	// to within the body of the function.
+	Name string
+
+	// Type is the type of the items of the argument. If empty, the argument
+	// may be of any type.
+	Type reflect.TypeSpecifier
+}
+
+// definer compiles the definitions of functions, in the order that they are
+// invoked by one another, so that recursion may be detected.
+type definer struct {
+	opts        Options
+	definitions map[string]Definition
+
+	// functions are the functions that may be invoked by a body, including
+	// those that have been defined so far.
+	functions funcs.Table
+
+	// stack are the names of the definitions being compiled, with the
+	// outermost first.
+	stack []string
+}
+
+// Define compiles each of the definitions, with the functions and leniency of
+// the options, and returns the table of functions of the options with the
+// defined functions added. The table may be used as the Functions of Options
+// to compile any number of expressions without compiling the definitions
+// again.
+func Define(opts Options, definitions map[string]Definition) (funcs.Table, error) {
+	if opts.Functions == nil {
+		opts.Functions = funcs.N1
+	}
+	if len(definitions) == 0 {
+		return opts.Functions, nil
+	}
+	d := &definer{opts: opts, definitions: definitions, functions: opts.Functions.Clone()}
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := d.function(name); err != nil {
+			return nil, err
+		}
+	}
+	return d.functions, nil
+}
+
+// function returns the defined function named name, compiling its definition
+// if it has not been compiled yet. A definition that invokes itself, directly
+// or through another definition, is an error.
+func (d *definer) function(name string) (*funcs.Function, error) {
+	if fn, ok := d.functions[name]; ok {
+		return fn, nil
+	}
+	if i := slices.Index(d.stack, name); i >= 0 {
+		cycle := append(slices.Clone(d.stack[i:]), name)
+		return nil, fmt.Errorf("%w '%v': %v", ErrRecursion, name, strings.Join(cycle, " -> "))
+	}
+	d.stack = append(d.stack, name)
+	defer func() { d.stack = d.stack[:len(d.stack)-1] }()
+
+	fn, err := d.compile(name, d.definitions[name])
+	if err != nil {
+		return nil, fmt.Errorf("function '%v': %w", name, err)
+	}
+	d.functions[name] = fn
+	return fn, nil
+}
+
+// compile compiles the definition of the function named name.
+func (d *definer) compile(name string, def Definition) (*funcs.Function, error) {
+	input := types.Unknown.WithList(true)
+	if def.Input != "" {
+		typ, ok := types.Parse(def.Input)
+		if !ok {
+			return nil, fmt.Errorf("%w '%v'", ErrUnknownType, def.Input)
+		}
+		input = typ.WithList(true)
+	}
+	variables := make(map[string]reflect.TypeSpecifier, len(def.Params))
+	params := make([]funcs.Param, 0, len(def.Params))
+	signature := make([]string, 0, len(def.Params))
+	for _, param := range def.Params {
+		typ := types.Unknown
+		if param.Type != "" {
+			var ok bool
+			if typ, ok = types.Parse(param.Type); !ok {
+				return nil, fmt.Errorf("%w '%v'", ErrUnknownType, param.Type)
+			}
+		}
+		variables[param.Name] = typ.Item().Specifier()
+		params = append(params, funcs.Param{Type: typ.Item()})
+		signature = append(signature, fmt.Sprintf("%v : %v", param.Name, typ.Item()))
+	}
+
+	c := &compiler{
+		functions: d.functions,
+		lenient:   d.opts.Lenient,
+		variables: variables,
+		checked:   def.Input != "",
+		root:      input,
+		focus:     input,
+		definer:   d,
+	}
+	tree, err := parse(def.Body)
+	if err != nil {
+		return nil, err
+	}
+	body, result, err := c.expression(tree.Expression())
+	if err != nil {
+		return nil, err
+	}
+
+	fn := &funcs.Function{
+		Func:    (&defined{body: body, params: def.Params}).call,
+		MinArgs: len(params),
+		MaxArgs: len(params),
+		Params:  params,
+		Result: func(types.Type, []types.Type) types.Type {
+			return result
+		},
+		Signature: fmt.Sprintf("%v(%v) : %v", name, strings.Join(signature, ", "), result),
+		Doc:       fmt.Sprintf("Defined in FHIRPath as '%v'.", def.Body),
+	}
+	if def.Input != "" {
+		fn.Input = []types.Type{input.Item()}
+	}
+	return fn, nil
+}
+
+// defined is the implementation of a function defined by a FHIRPath
+// expression.
+type defined struct {
+	body   expr.Expression
+	params []Parameter
+}
+
+// call evaluates the body of the function against its input, with each
+// argument bound to the environment variable of its parameter.
+func (d *defined) call(ctx context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	values := make(map[string]any, len(d.params))
+	for i, param := range d.params {
+		values[param.Name] = args[i]
+	}
+	return expr.EvaluateBody(envcontext.WithEntries(ctx, values), d.body, input)
+}
@@ -0,0 +1,84 @@
+package compile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+var (
+	// ErrUnimplemented is returned when an expression uses a feature of the
+	// FHIRPath language that is not yet implemented.
+	ErrUnimplemented = errors.New("unimplemented")
+
+	// ErrSyntax is returned when the source text is not a syntactically valid
+	// FHIRPath expression.
+	ErrSyntax = errors.New("syntax error")
+
+	// ErrUnknownFunction is returned when an expression invokes a function that
+	// is not defined for the version of FHIRPath being compiled.
+	ErrUnknownFunction = errors.New("unknown function")
+
+	// ErrUndeclaredVariable is returned when an expression references an
+	// environment variable that has not been declared.
+	ErrUndeclaredVariable = errors.New("undeclared variable")
+
+	// ErrUnknownElement is returned when an expression navigates into an
+	// element that is not defined by the type of its input, such as
+	// 'Patient.nmae'.
+	ErrUnknownElement = errors.New("unknown element")
+
+	// ErrUnknownType is returned when an expression names a type that is not
+	// defined in either the FHIR or System namespace.
+	ErrUnknownType = errors.New("unknown type")
+
+	// ErrTypeMismatch is returned when an expression uses a value of a type
+	// that it can never be applied to, such as comparing a code to an Integer.
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrRecursion is returned when a function defined by a FHIRPath
+	// expression invokes itself, either directly or through other functions.
+	ErrRecursion = errors.New("recursive function")
+)
+
+// Error is an error that occurred while compiling a FHIRPath expression,
+// annotated with the position in the source text that caused it.
+type Error struct {
+	// Line is the 1-based line of the source text where the error occurred.
+	Line int
+
+	// Column is the 0-based column of the source text where the error occurred.
+	Column int
+
+	// Err is the underlying reason for the error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func syntaxError(msg string) error {
+	return fmt.Errorf("%w: %v", ErrSyntax, msg)
+}
+
+// errorAt creates a compile error located at the start of the parse-tree node.
+func errorAt(node antlr.ParserRuleContext, err error) error {
+	token := node.GetStart()
+	return &Error{
+		Line:   token.GetLine(),
+		Column: token.GetColumn(),
+		Err:    err,
+	}
+}
+
+// errorfAt creates a formatted compile error located at the start of the
+// parse-tree node.
+func errorfAt(node antlr.ParserRuleContext, format string, args ...any) error {
+	return errorAt(node, fmt.Errorf(format, args...))
+}
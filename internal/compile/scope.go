@@ -0,0 +1,98 @@
+package compile
+
+import (
+	"github.com/antlr4-go/antlr/v4"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/parser"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+)
+
+// marker is the identifier that is appended to source text to find the scope
+// at its end. It is not a name that a FHIR type defines.
+const marker = "__scope__"
+
+// Scope is the scope of the identifier at a position of an expression: what
+// the identifier may name, as inferred from the expression before it.
+type Scope struct {
+	// Focus is the type of the input that the identifier is evaluated against.
+	Focus types.Type
+
+	// Root indicates that the identifier begins a path, so that it may also
+	// name the type of the focus -- e.g. 'Patient' in 'Patient.name'.
+	Root bool
+
+	// Terminologies indicates that the identifier names a method of
+	// '%terminologies', rather than an element or function of the focus.
+	Terminologies bool
+}
+
+// ScopeAt returns the scope of an identifier that follows the source text,
+// such as the scope of 'given' in 'Patient.name.' + 'given'. The source is
+// typically incomplete, so syntax errors are ignored, and the parts of the
+// expression that cannot be compiled are skipped. If the end of the source is
+// not where an identifier may be, ok is false.
+func ScopeAt(source string, opts Options) (scope Scope, ok bool, err error) {
+	c, err := newCompiler(opts)
+	if err != nil {
+		return Scope{}, false, err
+	}
+	c.checked = false
+	c.probe = &scope
+
+	tree, _ := parse(source + marker)
+	if tree.Expression() != nil {
+		_, _, _ = c.expression(tree.Expression())
+	}
+	return scope, c.probed, nil
+}
+
+// VariableTypes returns the types of the environment variables that may be
+// referenced by name with the options: the variables defined by FHIR, and any
+// declared variables.
+func VariableTypes(opts Options) (map[string]types.Type, error) {
+	c, err := newCompiler(opts)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]types.Type{}
+	for _, name := range envcontext.Names() {
+		result[name] = c.constantType(name)
+	}
+	for name := range opts.Variables {
+		result[name] = c.constantType(name)
+	}
+	return result, nil
+}
+
+// probing returns whether the identifier is the marker of the scope that the
+// compiler is probing for.
+func (c *compiler) probing(node parser.IIdentifierContext) bool {
+	return c.probe != nil && !c.probed && node != nil && node.GetText() == marker
+}
+
+// reach records the scope of the marker.
+func (c *compiler) reach(scope Scope) {
+	*c.probe = scope
+	c.probed = true
+}
+
+// skip compiles the expressions nested in a node that cannot itself be
+// compiled while probing, against the focus, so that the marker may be found
+// within them. The node is of an unknown type.
+func (c *compiler) skip(node antlr.Tree, focus types.Type) (expr.Expression, types.Type, error) {
+	outer := c.focus
+	c.focus = focus
+	defer func() { c.focus = outer }()
+
+	if node != nil {
+		for _, child := range node.GetChildren() {
+			if e, ok := child.(parser.IExpressionContext); ok {
+				_, _, _ = c.expression(e)
+			} else if _, ok := child.(antlr.ParserRuleContext); ok {
+				_, _, _ = c.skip(child, focus)
+			}
+		}
+	}
+	return nil, types.Unknown.WithList(true), nil
+}
@@ -4,27 +4,28 @@ environment variables, as defined in FHIRPath.
 */
 package envcontext
 
-import "context"
+import (
+	"context"
+	"maps"
+)
 
 type exprKey struct{}
 type exprEntries map[string]any
 
-// Lookup retrieves a value from the context by name.
+// Lookup retrieves a value from the context by name. If the context does not
+// define the value, the [Standard] value of the name is returned, if any.
 func Lookup(ctx context.Context, name string) (any, bool) {
-	if ctx == nil {
-		return nil, false
+	if result, ok := lookup(ctx, name); ok {
+		return result, true
 	}
-	value := ctx.Value(exprKey{})
-	if value == nil {
-		return nil, false
+	if result, ok := Standard(name); ok {
+		return result, true
 	}
+	return nil, false
+}
 
-	// This case should never logically occur, but is added as a precaution.
-	entries := value.(exprEntries)
-	if entries == nil {
-		return nil, false
-	}
-	result, ok := entries[name]
+func lookup(ctx context.Context, name string) (any, bool) {
+	result, ok := entriesOf(ctx)[name]
 	return result, ok
 }
 
@@ -43,36 +44,37 @@ func GetOr(ctx context.Context, name string, def any) any {
 	return value
 }
 
-// WithEntry adds a single environment value by name to the context.
+// WithEntry adds a single environment value by name to the context. The
+// entries of the parent context are left unchanged.
 func WithEntry(ctx context.Context, name string, value any) context.Context {
+	return withEntries(ctx, entriesOf(ctx), map[string]any{name: value})
+}
+
+// WithEntries adds multiple environment values by name to the context. The
+// entries of the parent context are left unchanged.
+func WithEntries(ctx context.Context, values map[string]any) context.Context {
+	return withEntries(ctx, entriesOf(ctx), values)
+}
+
+// entriesOf returns the entries of the context, which may be nil.
+func entriesOf(ctx context.Context) exprEntries {
 	if ctx == nil {
-		ctx = context.Background()
+		return nil
 	}
-	exprCtx := ctx.Value(exprKey{})
-	if exprCtx == nil {
-		exprCtx = exprEntries{}
-	}
-	entries := exprCtx.(exprEntries)
-	entries[name] = value
-	return context.WithValue(ctx, exprKey{}, entries)
+	entries, _ := ctx.Value(exprKey{}).(exprEntries)
+	return entries
 }
 
-// WithEntries adds multiple environment values by name to the context.
-func WithEntries(ctx context.Context, values map[string]any) context.Context {
+// withEntries returns a context with a copy of the entries along with the
+// values, leaving the entries of the parent context unchanged.
+func withEntries(ctx context.Context, entries exprEntries, values map[string]any) context.Context {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	exprCtx := ctx.Value(exprKey{})
-	if exprCtx == nil {
-		exprCtx = exprEntries{}
-	}
-	entries := exprCtx.(exprEntries)
-	if entries == nil {
-		entries = exprEntries{}
-	}
-
-	for key, value := range values {
-		entries[key] = value
+	result := maps.Clone(entries)
+	if result == nil {
+		result = exprEntries{}
 	}
-	return context.WithValue(ctx, exprKey{}, entries)
+	maps.Copy(result, values)
+	return context.WithValue(ctx, exprKey{}, result)
 }
@@ -223,3 +223,17 @@ func TestWithEntries(t *testing.T) {
 		})
 	}
 }
+
+func TestWithEntry_DoesNotModifyParent(t *testing.T) {
+	parent := envcontext.WithEntry(context.Background(), "key", "parent")
+
+	_ = envcontext.WithEntry(parent, "key", "child")
+	_ = envcontext.WithEntries(parent, map[string]any{"other-key": "child"})
+
+	if got, want := envcontext.Get(parent, "key"), "parent"; got != want {
+		t.Errorf("Get(parent, key) = %v; want %v", got, want)
+	}
+	if _, ok := envcontext.Lookup(parent, "other-key"); ok {
+		t.Errorf("Lookup(parent, other-key) ok = true; want false")
+	}
+}
@@ -0,0 +1,118 @@
+package envcontext
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// The names of the environment variables that describe the evaluation, as
+// defined by FHIR.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#vars
+const (
+	// Context is the name of the variable for the original node that is passed
+	// to the evaluation engine.
+	Context = "context"
+
+	// Resource is the name of the variable for the resource that contains the
+	// original node in '%context'.
+	Resource = "resource"
+
+	// RootResource is the name of the variable for the container of the
+	// resource in '%resource'. This is the same as '%resource', unless that is
+	// a contained resource.
+	RootResource = "rootResource"
+
+	// Terminologies is the name of the variable for the terminology service
+	// API, whose methods are invoked as '%terminologies.expand(...)'.
+	Terminologies = "terminologies"
+)
+
+// constants are the FHIR environment variables with fixed values.
+var constants = map[string]system.String{
+	"ucum":  "http://unitsofmeasure.org",
+	"sct":   "http://snomed.info/sct",
+	"loinc": "http://loinc.org",
+}
+
+// prefixes are the prefixes of the FHIR shorthand environment variables, and
+// the base URL of the canonical resources that they expand to.
+var prefixes = map[string]string{
+	"vs-":  "http://hl7.org/fhir/ValueSet/",
+	"ext-": "http://hl7.org/fhir/StructureDefinition/",
+}
+
+// Standard returns the value of the FHIR environment variable with a fixed
+// value, such as '%ucum', or the canonical URL that a '%vs-[name]' or
+// '%ext-[name]' variable expands to.
+func Standard(name string) (system.String, bool) {
+	if value, ok := constants[name]; ok {
+		return value, true
+	}
+	for prefix, base := range prefixes {
+		if suffix, ok := strings.CutPrefix(name, prefix); ok && suffix != "" {
+			return system.String(base + suffix), true
+		}
+	}
+	return "", false
+}
+
+// IsStandard returns whether the name is of an environment variable that is
+// defined by FHIR, and so need not be declared to be referenced.
+func IsStandard(name string) bool {
+	switch name {
+	case Context, Resource, RootResource, Terminologies:
+		return true
+	}
+	_, ok := Standard(name)
+	return ok
+}
+
+// Names returns the names of the environment variables that FHIR defines,
+// other than the '%vs-[name]' and '%ext-[name]' shorthands, in sorted order.
+func Names() []string {
+	names := []string{Context, Resource, RootResource, Terminologies}
+	for name := range constants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithFocus returns a context that defines '%context' as the focus of the
+// evaluation. If the focus is a resource, it also defines '%resource' and
+// '%rootResource' as the focus.
+//
+// Variables that are already defined in the context are not replaced, so that
+// callers may override them.
+func WithFocus(ctx context.Context, focus any) context.Context {
+	values := map[string]any{Context: focus}
+	if _, ok := focus.(fhir.Resource); ok {
+		values[Resource] = focus
+		values[RootResource] = focus
+	}
+	entries := entriesOf(ctx)
+	for name := range entries {
+		delete(values, name)
+	}
+	if len(values) == 0 {
+		return ctx
+	}
+	return withEntries(ctx, entries, values)
+}
+
+// WithNode returns a context that defines '%context' as the node, '%resource'
+// as the resource that contains the node, and '%rootResource' as the resource
+// that contains that resource. A resource in a Bundle entry is not contained,
+// and so is its own root.
+func WithNode(ctx context.Context, node, resource, rootResource any) context.Context {
+	return withEntries(ctx, entriesOf(ctx), map[string]any{
+		Context:      node,
+		Resource:     resource,
+		RootResource: rootResource,
+	})
+}
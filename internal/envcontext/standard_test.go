@@ -0,0 +1,99 @@
+package envcontext_test
+
+import (
+	"context"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestStandard(t *testing.T) {
+	testCases := []struct {
+		name   string
+		key    string
+		want   system.String
+		wantOK bool
+	}{
+		{"UCUM", "ucum", "http://unitsofmeasure.org", true},
+		{"SNOMED CT", "sct", "http://snomed.info/sct", true},
+		{"LOINC", "loinc", "http://loinc.org", true},
+		{"Value set", "vs-administrative-gender", "http://hl7.org/fhir/ValueSet/administrative-gender", true},
+		{"Extension", "ext-patient-birthTime", "http://hl7.org/fhir/StructureDefinition/patient-birthTime", true},
+		{"Prefix without name", "vs-", "", false},
+		{"Unknown name", "unknown", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := envcontext.Standard(tc.key)
+
+			if got, want := ok, tc.wantOK; got != want {
+				t.Fatalf("Standard(%q) ok = %v; want %v", tc.key, got, want)
+			}
+			if got, want := got, tc.want; got != want {
+				t.Errorf("Standard(%q) = %v; want %v", tc.key, got, want)
+			}
+		})
+	}
+}
+
+func TestWithFocus(t *testing.T) {
+	resource := &patient.Patient{}
+	element := &fhir.HumanName{}
+	other := &patient.Patient{}
+
+	testCases := []struct {
+		name string
+		ctx  context.Context
+		node any
+		want map[string]any
+	}{
+		{
+			name: "Resource defines resource variables",
+			ctx:  context.Background(),
+			node: resource,
+			want: map[string]any{"context": resource, "resource": resource, "rootResource": resource},
+		}, {
+			name: "Element defines only context",
+			ctx:  context.Background(),
+			node: element,
+			want: map[string]any{"context": element, "resource": nil, "rootResource": nil},
+		}, {
+			name: "Defined variables are not replaced",
+			ctx:  envcontext.WithEntry(context.Background(), "resource", other),
+			node: resource,
+			want: map[string]any{"context": resource, "resource": other, "rootResource": resource},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := envcontext.WithFocus(tc.ctx, tc.node)
+
+			for key, want := range tc.want {
+				if got := envcontext.Get(ctx, key); got != want {
+					t.Errorf("WithFocus() %v = %v; want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithNode(t *testing.T) {
+	parent := envcontext.WithEntry(context.Background(), "resource", "parent")
+
+	ctx := envcontext.WithNode(parent, "node", "resource", "root")
+
+	want := map[string]any{"context": "node", "resource": "resource", "rootResource": "root"}
+	for key, want := range want {
+		if got := envcontext.Get(ctx, key); got != want {
+			t.Errorf("WithNode() %v = %v; want %v", key, got, want)
+		}
+	}
+	if got, want := envcontext.Get(parent, "resource"), "parent"; got != want {
+		t.Errorf("WithNode() parent resource = %v; want %v", got, want)
+	}
+}
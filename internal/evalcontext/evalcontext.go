@@ -0,0 +1,60 @@
+/*
+Package evalcontext provides definitions for Context objects that carry the
+configuration of a single FHIRPath evaluation, such as the resolver used by the
+'resolve()' function.
+*/
+package evalcontext
+
+import (
+	"context"
+
+	"github.com/friendly-fhir/go-fhirpath/conformance"
+	"github.com/friendly-fhir/go-fhirpath/resolver"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
+)
+
+// Config is the configuration of a single FHIRPath evaluation.
+type Config struct {
+	// Root is the resource that the expression is evaluated against. Contained
+	// references, such as '#id', are resolved against this resource.
+	Root any
+
+	// Resolver is the resolver used to resolve references. If nil, only
+	// contained references may be resolved.
+	Resolver resolver.Resolver
+
+	// StrictResolve indicates that a failure to resolve a reference is an
+	// error. Otherwise, references that fail to resolve are omitted.
+	StrictResolve bool
+
+	// ProfileValidator is the validator used by the 'conformsTo' function. If
+	// nil, no profiles may be validated.
+	ProfileValidator conformance.Validator
+
+	// Terminology is the terminology service used by the 'memberOf',
+	// 'subsumes', and 'subsumedBy' functions. If nil, no terminology operations
+	// may be evaluated.
+	Terminology terminology.Terminology
+}
+
+type configKey struct{}
+
+// With returns a context that carries the evaluation configuration.
+func With(ctx context.Context, cfg *Config) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, configKey{}, cfg)
+}
+
+// From retrieves the evaluation configuration from the context. If the
+// context does not carry a configuration, an empty configuration is returned.
+func From(ctx context.Context) *Config {
+	if ctx == nil {
+		return &Config{}
+	}
+	if cfg, ok := ctx.Value(configKey{}).(*Config); ok {
+		return cfg
+	}
+	return &Config{}
+}
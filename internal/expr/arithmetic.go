@@ -0,0 +1,130 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// Arithmetic is an expression for the FHIRPath arithmetic operators: '+', '-',
+// '*', '/', 'div', 'mod', and the string concatenation operator '&'.
+//
+// See: https://hl7.org/fhirpath/N1/#math
+type Arithmetic struct {
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+// arithmeticOperators are the implementations of each arithmetic operator.
+var arithmeticOperators = map[string]func(lhs, rhs any) (system.Any, bool, error){
+	"+":   system.Add,
+	"-":   system.Subtract,
+	"*":   system.Multiply,
+	"/":   system.Divide,
+	"div": system.Div,
+	"mod": system.Mod,
+}
+
+// Evaluate applies the operator to both operands. If either operand is empty,
+// or the result of the operation is undefined (such as division by zero), the
+// result is empty.
+//
+// The '&' operator instead treats an empty operand as an empty string.
+func (a *Arithmetic) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	lhs, rhs, err := evaluateOperands(ctx, input, a.Left, a.Right)
+	if err != nil {
+		return nil, err
+	}
+	if a.Operator == "&" {
+		return a.concatenate(lhs, rhs)
+	}
+	if lhs.IsEmpty() || rhs.IsEmpty() {
+		return collection.Empty, nil
+	}
+
+	fn, ok := arithmeticOperators[a.Operator]
+	if !ok {
+		return nil, fmt.Errorf("unknown arithmetic operator '%v'", a.Operator)
+	}
+	l, err := lhs.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': left operand: %w", a.Operator, err)
+	}
+	r, err := rhs.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': right operand: %w", a.Operator, err)
+	}
+
+	result, ok, err := fn(l, r)
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': %w", a.Operator, err)
+	}
+	if !ok {
+		return collection.Empty, nil
+	}
+	return collection.Of(result), nil
+}
+
+func (a *Arithmetic) concatenate(lhs, rhs collection.Collection) (collection.Collection, error) {
+	var result system.String
+	for _, operand := range []collection.Collection{lhs, rhs} {
+		if operand.IsEmpty() {
+			continue
+		}
+		str, err := operand.String()
+		if err != nil {
+			return nil, fmt.Errorf("operator '&': %w", err)
+		}
+		result += system.String(str)
+	}
+	return collection.Of(result), nil
+}
+
+var _ Expression = (*Arithmetic)(nil)
+
+// Polarity is an expression for the FHIRPath unary polarity operators: '+'
+// and '-'.
+//
+// See: https://hl7.org/fhirpath/N1/#math
+type Polarity struct {
+	Operator   string
+	Expression Expression
+}
+
+// Evaluate applies the polarity to the operand. If the operand is empty, the
+// result is empty.
+func (p *Polarity) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	operand, err := p.Expression.Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if operand.IsEmpty() {
+		return collection.Empty, nil
+	}
+	value, err := operand.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': %w", p.Operator, err)
+	}
+
+	var result system.Any
+	switch p.Operator {
+	case "+":
+		result, err = system.Negate(value)
+		if err == nil {
+			result, err = system.Negate(result)
+		}
+	case "-":
+		result, err = system.Negate(value)
+	default:
+		return nil, fmt.Errorf("unknown polarity operator '%v'", p.Operator)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': %w", p.Operator, err)
+	}
+	return collection.Of(result), nil
+}
+
+var _ Expression = (*Polarity)(nil)
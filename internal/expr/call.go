@@ -0,0 +1,70 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+)
+
+// Function is the implementation of a FHIRPath function. It receives the input
+// collection that the function was invoked on, along with the evaluated
+// results of each of its arguments.
+type Function func(ctx context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error)
+
+// Call is an expression that invokes a function on the input collection, e.g.
+// 'lowBoundary(6)'.
+//
+// See: https://hl7.org/fhirpath/N1/#functions
+type Call struct {
+	Name string
+	Func Function
+	Args []Expression
+}
+
+// Evaluate evaluates each argument against the input collection, and then
+// invokes the function with the results.
+func (c *Call) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	args := make([]collection.Collection, 0, len(c.Args))
+	for _, arg := range c.Args {
+		result, err := arg.Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, result)
+	}
+
+	result, err := c.Func(ctx, input, args...)
+	if err != nil {
+		return nil, fmt.Errorf("function '%v': %w", c.Name, err)
+	}
+	return result, nil
+}
+
+var _ Expression = (*Call)(nil)
+
+// LambdaFunction is the implementation of a FHIRPath function whose arguments
+// are left unevaluated, so that the function may evaluate them itself --
+// typically against each item of its input with [EvaluateItem], as with the
+// criteria of 'where(criteria)'.
+type LambdaFunction func(ctx context.Context, input collection.Collection, args ...Expression) (collection.Collection, error)
+
+// LambdaCall is an expression that invokes a function with unevaluated
+// arguments on the input collection, e.g. 'sort($this)'.
+type LambdaCall struct {
+	Name string
+	Func LambdaFunction
+	Args []Expression
+}
+
+// Evaluate invokes the function with the input collection and the argument
+// expressions.
+func (c *LambdaCall) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	result, err := c.Func(ctx, input, c.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("function '%v': %w", c.Name, err)
+	}
+	return result, nil
+}
+
+var _ Expression = (*LambdaCall)(nil)
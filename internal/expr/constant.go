@@ -0,0 +1,46 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/envcontext"
+)
+
+// ExternalConstant is an expression that evaluates to the value of an
+// environment variable, e.g. '%resource'.
+//
+// See: https://hl7.org/fhirpath/N1/#environment-variables
+type ExternalConstant struct {
+	Name string
+}
+
+// Evaluate returns the value of the environment variable. Referencing an
+// environment variable that is not defined is an error.
+//
+// Unless otherwise defined, the FHIR '%ucum', '%sct', '%loinc', '%vs-[name]',
+// and '%ext-[name]' variables evaluate to the URLs defined by the FHIR
+// specification, e.g. '%`ext-patient-birthTime`'.
+func (c *ExternalConstant) Evaluate(ctx context.Context, _ collection.Collection) (collection.Collection, error) {
+	value, ok := envcontext.Lookup(ctx, c.Name)
+	if !ok {
+		return nil, fmt.Errorf("undefined environment variable '%%%v'", c.Name)
+	}
+	return toCollection(value), nil
+}
+
+var _ Expression = (*ExternalConstant)(nil)
+
+// toCollection converts an arbitrary value into a collection. Collections are
+// returned unchanged, nil becomes the empty collection, and any other value
+// becomes a singleton.
+func toCollection(value any) collection.Collection {
+	switch v := value.(type) {
+	case nil:
+		return collection.Empty
+	case collection.Collection:
+		return v
+	}
+	return collection.Collection{value}
+}
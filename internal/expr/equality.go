@@ -0,0 +1,103 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// Equality is an expression for the FHIRPath equality operators: '=' and
+// '!='.
+//
+// See: https://hl7.org/fhirpath/N1/#equality
+type Equality struct {
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+// Evaluate compares both operands for equality. If either operand is empty, or
+// the equality of any pair of items cannot be determined, the result is empty.
+func (e *Equality) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	lhs, rhs, err := evaluateOperands(ctx, input, e.Left, e.Right)
+	if err != nil {
+		return nil, err
+	}
+	if lhs.IsEmpty() || rhs.IsEmpty() {
+		return collection.Empty, nil
+	}
+
+	equal, ok := tryEqual(lhs, rhs)
+	if !ok {
+		return collection.Empty, nil
+	}
+	switch e.Operator {
+	case "=":
+		return collection.Of(system.Boolean(equal)), nil
+	case "!=":
+		return collection.Of(system.Boolean(!equal)), nil
+	}
+	return nil, fmt.Errorf("unknown equality operator '%v'", e.Operator)
+}
+
+var _ Expression = (*Equality)(nil)
+
+// tryEqual compares two collections item-by-item for equality.
+func tryEqual(lhs, rhs collection.Collection) (equal, ok bool) {
+	if len(lhs) != len(rhs) {
+		return false, true
+	}
+	for i := range lhs {
+		equal, ok := system.TryEqual(lhs[i], rhs[i])
+		if !ok || !equal {
+			return equal, ok
+		}
+	}
+	return true, true
+}
+
+// Membership is an expression for the FHIRPath membership operators: 'in' and
+// 'contains'.
+//
+// See: https://hl7.org/fhirpath/N1/#collections-2
+type Membership struct {
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+// Evaluate tests whether the single item of one operand is equal to any item
+// of the other operand. For 'in', the left operand is the item and the right
+// operand is the collection; 'contains' is the converse.
+//
+// If the item operand is empty, the result is empty. If it contains more than
+// one item, this is an error.
+func (m *Membership) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	lhs, rhs, err := evaluateOperands(ctx, input, m.Left, m.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	var item, items collection.Collection
+	switch m.Operator {
+	case "in":
+		item, items = lhs, rhs
+	case "contains":
+		item, items = rhs, lhs
+	default:
+		return nil, fmt.Errorf("unknown membership operator '%v'", m.Operator)
+	}
+
+	if item.IsEmpty() {
+		return collection.Empty, nil
+	}
+	value, err := item.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': %w", m.Operator, err)
+	}
+	return collection.Of(system.Boolean(items.Contains(value))), nil
+}
+
+var _ Expression = (*Membership)(nil)
@@ -0,0 +1,36 @@
+/*
+Package expr provides the evaluable expression tree that FHIRPath expressions
+are compiled into.
+
+Each node of the tree is an Expression, which evaluates an input collection
+into an output collection, following the semantics of the FHIRPath
+specification.
+*/
+package expr
+
+import (
+	"context"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+)
+
+// Expression is a single node of a compiled FHIRPath expression.
+type Expression interface {
+	// Evaluate evaluates this expression against the input collection, and
+	// returns the resulting collection.
+	Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error)
+}
+
+// evaluateOperands evaluates the left and right operands of a binary operator
+// against the same input collection.
+func evaluateOperands(ctx context.Context, input collection.Collection, left, right Expression) (lhs, rhs collection.Collection, err error) {
+	lhs, err = left.Evaluate(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	rhs, err = right.Evaluate(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lhs, rhs, nil
+}
@@ -0,0 +1,43 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// Indexer is an expression for the FHIRPath indexer: 'collection[index]'.
+//
+// See: https://hl7.org/fhirpath/N1/#index-integer-collection
+type Indexer struct {
+	Source Expression
+	Index  Expression
+}
+
+// Evaluate returns the item of the source at the zero-based index. If the
+// index is empty, or is beyond the bounds of the source, the result is empty.
+func (i *Indexer) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	source, index, err := evaluateOperands(ctx, input, i.Source, i.Index)
+	if err != nil {
+		return nil, err
+	}
+	if index.IsEmpty() {
+		return collection.Empty, nil
+	}
+	item, err := index.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("indexer: %w", err)
+	}
+	n, ok := system.Normalize(item).(system.Integer)
+	if !ok {
+		return nil, fmt.Errorf("indexer: expected Integer, got %T", item)
+	}
+	if n < 0 || int(n) >= len(source) {
+		return collection.Empty, nil
+	}
+	return collection.Of(source[n]), nil
+}
+
+var _ Expression = (*Indexer)(nil)
@@ -0,0 +1,63 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// Inequality is an expression for the FHIRPath comparison operators: '<',
+// '<=', '>', and '>='.
+//
+// See: https://hl7.org/fhirpath/N1/#comparison
+type Inequality struct {
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+// Evaluate compares the singleton results of both operands. If either operand
+// is empty, or the result of the comparison cannot be determined (such as when
+// comparing dates of different precisions), the result is empty.
+func (i *Inequality) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	lhs, rhs, err := evaluateOperands(ctx, input, i.Left, i.Right)
+	if err != nil {
+		return nil, err
+	}
+	if lhs.IsEmpty() || rhs.IsEmpty() {
+		return collection.Empty, nil
+	}
+
+	l, err := lhs.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': left operand: %w", i.Operator, err)
+	}
+	r, err := rhs.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': right operand: %w", i.Operator, err)
+	}
+
+	result, ok, err := system.TryCompare(l, r)
+	if err != nil {
+		return nil, fmt.Errorf("operator '%v': %w", i.Operator, err)
+	}
+	if !ok {
+		return collection.Empty, nil
+	}
+
+	switch i.Operator {
+	case "<":
+		return collection.Of(system.Boolean(result < 0)), nil
+	case "<=":
+		return collection.Of(system.Boolean(result <= 0)), nil
+	case ">":
+		return collection.Of(system.Boolean(result > 0)), nil
+	case ">=":
+		return collection.Of(system.Boolean(result >= 0)), nil
+	}
+	return nil, fmt.Errorf("unknown comparison operator '%v'", i.Operator)
+}
+
+var _ Expression = (*Inequality)(nil)
@@ -0,0 +1,20 @@
+package expr
+
+import (
+	"context"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+)
+
+// Literal is an expression that always evaluates to a fixed value, regardless
+// of its input.
+type Literal struct {
+	Value collection.Collection
+}
+
+// Evaluate returns the literal value.
+func (l *Literal) Evaluate(context.Context, collection.Collection) (collection.Collection, error) {
+	return l.Value, nil
+}
+
+var _ Expression = (*Literal)(nil)
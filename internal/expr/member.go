@@ -0,0 +1,90 @@
+package expr
+
+import (
+	"context"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+)
+
+// Member is an expression that navigates into the named element of every item
+// in the input collection.
+//
+// See: https://hl7.org/fhirpath/N1/#path-selection
+type Member struct {
+	Name string
+
+	// Root indicates that this member is the first identifier of a path. In
+	// this position, the identifier may also name the type of the input, in
+	// which case the input item itself is selected -- e.g. 'Patient' in
+	// 'Patient.name'.
+	Root bool
+
+	// Lenient indicates that a choice element may also be named by its type,
+	// as it is in the FHIR JSON format -- e.g. 'valueQuantity' for a 'value'
+	// element holding a Quantity. This is not valid FHIRPath.
+	Lenient bool
+}
+
+// Evaluate returns all the values of the named element from the input items,
+// in order.
+func (m *Member) Evaluate(_ context.Context, input collection.Collection) (collection.Collection, error) {
+	var result collection.Collection
+	for _, item := range input {
+		if m.Root && model.TypeName(item) == m.Name {
+			result = append(result, item)
+			continue
+		}
+		children := model.Children(item, m.Name)
+		if children == nil && m.Lenient {
+			children = model.ChoiceChildren(item, m.Name)
+		}
+		result = append(result, children...)
+	}
+	return result, nil
+}
+
+// TypeSpecifier returns the type that the expression names when it is used as
+// a type specifier, such as the argument of 'ofType(FHIR.Quantity)'. The name
+// is qualified only if the expression qualifies it.
+//
+// If the expression is not an identifier or a qualified identifier, ok is
+// false.
+func TypeSpecifier(e Expression) (name string, ok bool) {
+	switch e := e.(type) {
+	case *Member:
+		return e.Name, e.Root
+	case *Invocation:
+		namespace, ok := e.Source.(*Member)
+		if !ok || !namespace.Root {
+			return "", false
+		}
+		member, ok := e.Invocation.(*Member)
+		if !ok {
+			return "", false
+		}
+		return namespace.Name + "." + member.Name, true
+	}
+	return "", false
+}
+
+var _ Expression = (*Member)(nil)
+
+// Invocation is an expression that evaluates an invocation (such as a member
+// or function) against the result of another expression, e.g. 'name.given'.
+type Invocation struct {
+	Source     Expression
+	Invocation Expression
+}
+
+// Evaluate evaluates the source expression, and then evaluates the invocation
+// with that result as its input.
+func (i *Invocation) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	source, err := i.Source.Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return i.Invocation.Evaluate(ctx, source)
+}
+
+var _ Expression = (*Invocation)(nil)
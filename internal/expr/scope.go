@@ -0,0 +1,72 @@
+package expr
+
+import (
+	"context"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// scope is the iteration scope of a function that evaluates an expression
+// against each item of its input, such as 'where' or 'sort'. This defines the
+// values of the '$this' and '$index' variables.
+type scope struct {
+	this  any
+	index int
+}
+
+type scopeKey struct{}
+
+// EvaluateItem evaluates the expression against a single item of a
+// collection, with '$this' referring to the item and '$index' referring to its
+// index within the collection.
+func EvaluateItem(ctx context.Context, e Expression, item any, index int) (collection.Collection, error) {
+	ctx = context.WithValue(ctx, scopeKey{}, &scope{this: item, index: index})
+	return e.Evaluate(ctx, collection.Collection{item})
+}
+
+// EvaluateBody evaluates the body of a function that is defined by a FHIRPath
+// expression against the input of the function. The body is evaluated outside
+// of any iteration scope that the function is invoked within, so that '$this'
+// refers to the input, and '$index' is empty.
+func EvaluateBody(ctx context.Context, e Expression, input collection.Collection) (collection.Collection, error) {
+	ctx = context.WithValue(ctx, scopeKey{}, (*scope)(nil))
+	return e.Evaluate(ctx, input)
+}
+
+func scopeOf(ctx context.Context) (*scope, bool) {
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	return s, s != nil
+}
+
+// This is an expression for the FHIRPath '$this' variable. Outside of an
+// iteration scope, '$this' refers to the input collection.
+//
+// See: https://hl7.org/fhirpath/N1/#functions
+type This struct{}
+
+// Evaluate returns the item currently being iterated, or the input if there
+// is no iteration.
+func (This) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	if s, ok := scopeOf(ctx); ok {
+		return collection.Collection{s.this}, nil
+	}
+	return input, nil
+}
+
+// Index is an expression for the FHIRPath '$index' variable. Outside of an
+// iteration scope, '$index' is empty.
+type Index struct{}
+
+// Evaluate returns the index of the item currently being iterated.
+func (Index) Evaluate(ctx context.Context, _ collection.Collection) (collection.Collection, error) {
+	if s, ok := scopeOf(ctx); ok {
+		return collection.Of(system.Integer(s.index)), nil
+	}
+	return collection.Empty, nil
+}
+
+var (
+	_ Expression = (*This)(nil)
+	_ Expression = (*Index)(nil)
+)
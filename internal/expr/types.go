@@ -0,0 +1,68 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// TypeExpression is an expression for the FHIRPath type operators: 'is' and
+// 'as'.
+//
+// See: https://hl7.org/fhirpath/N1/#types-2
+type TypeExpression struct {
+	Operator string
+	Source   Expression
+	Type     string
+}
+
+// Evaluate tests the single item of the source against the type. If the
+// source is empty, the result is empty.
+func (e *TypeExpression) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	source, err := e.Source.Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Operator {
+	case "is":
+		return Is(source, e.Type)
+	case "as":
+		return As(source, e.Type)
+	}
+	return nil, fmt.Errorf("unknown type operator '%v'", e.Operator)
+}
+
+// Is returns whether the single item of the input is of the type named by the
+// specifier, or of a type derived from it. If the input is empty, the result
+// is empty.
+func Is(input collection.Collection, specifier string) (collection.Collection, error) {
+	if input.IsEmpty() {
+		return collection.Empty, nil
+	}
+	item, err := input.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("is: %w", err)
+	}
+	return collection.Of(system.Boolean(model.IsType(item, specifier))), nil
+}
+
+// As returns the single item of the input if it is of the type named by the
+// specifier, or of a type derived from it. Otherwise, the result is empty.
+func As(input collection.Collection, specifier string) (collection.Collection, error) {
+	if input.IsEmpty() {
+		return collection.Empty, nil
+	}
+	item, err := input.Singleton()
+	if err != nil {
+		return nil, fmt.Errorf("as: %w", err)
+	}
+	if !model.IsType(item, specifier) {
+		return collection.Empty, nil
+	}
+	return input, nil
+}
+
+var _ Expression = (*TypeExpression)(nil)
@@ -0,0 +1,34 @@
+package expr
+
+import (
+	"context"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+)
+
+// Union is an expression for the FHIRPath union operator: '|'.
+//
+// See: https://hl7.org/fhirpath/N1/#union-collections
+type Union struct {
+	Left  Expression
+	Right Expression
+}
+
+// Evaluate merges both operands into a single collection, eliminating any
+// duplicate items, as determined by FHIRPath equality. The items of the left
+// operand precede those of the right.
+func (u *Union) Evaluate(ctx context.Context, input collection.Collection) (collection.Collection, error) {
+	lhs, rhs, err := evaluateOperands(ctx, input, u.Left, u.Right)
+	if err != nil {
+		return nil, err
+	}
+	var result collection.Collection
+	for _, item := range append(lhs, rhs...) {
+		if !result.Contains(item) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+var _ Expression = (*Union)(nil)
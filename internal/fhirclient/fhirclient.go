@@ -0,0 +1,121 @@
+/*
+Package fhirclient provides a minimal client for reading from FHIR servers with
+the FHIR REST API, as shared by the HTTP resolver and terminology packages.
+*/
+package fhirclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/operationoutcome"
+	"github.com/friendly-fhir/go-fhirpath/internal/resources"
+)
+
+// ContentType is the media type of the FHIR JSON format, which is requested
+// from the server.
+const ContentType = "application/fhir+json"
+
+// Client sends requests to a FHIR server.
+type Client struct {
+	// Base is the base URL of the server, without a trailing slash.
+	Base string
+
+	// HTTP is the client used to send requests. If nil, [http.DefaultClient]
+	// is used.
+	HTTP *http.Client
+
+	// Auth returns the value of the Authorization header of each request. If
+	// nil, no Authorization header is sent.
+	Auth func(ctx context.Context) (string, error)
+}
+
+// Get sends a GET request for the path relative to the base URL, and returns
+// the body of a successful response. If the server does not have the
+// resource, nil is returned without an error. A successful response whose
+// body is an OperationOutcome, rather than the requested resource, is an
+// error.
+func (c *Client) Get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Base+"/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", ContentType)
+	if c.Auth != nil {
+		auth, err := c.Auth(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("authorization: %w", err)
+		}
+		req.Header.Set("Authorization", auth)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+		return nil, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299, isOutcome(body):
+		return nil, errorOf(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// isOutcome returns whether the body is an OperationOutcome.
+func isOutcome(body []byte) bool {
+	var header struct {
+		ResourceType string `json:"resourceType"`
+	}
+	return json.Unmarshal(body, &header) == nil && header.ResourceType == "OperationOutcome"
+}
+
+// Error is an error returned by the server when it fails a request.
+type Error struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Outcome is the OperationOutcome that describes the failure, if the
+	// server returned one.
+	Outcome *operationoutcome.OperationOutcome
+}
+
+func errorOf(statusCode int, body []byte) *Error {
+	err := &Error{StatusCode: statusCode}
+	if resource, decodeErr := resources.Decode(body); decodeErr == nil {
+		err.Outcome, _ = resource.(*operationoutcome.OperationOutcome)
+	}
+	return err
+}
+
+// Error returns the status of the response, along with the diagnostics of each
+// issue of the OperationOutcome.
+func (e *Error) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "fhir server: %d %v", e.StatusCode, http.StatusText(e.StatusCode))
+	for _, issue := range e.Outcome.GetIssue() {
+		switch {
+		case issue.GetDiagnostics() != nil:
+			fmt.Fprintf(&sb, ": %v", issue.GetDiagnostics().Value)
+		case issue.GetDetails().GetText() != nil:
+			fmt.Fprintf(&sb, ": %v", issue.GetDetails().GetText().Value)
+		case issue.GetCode() != nil:
+			fmt.Fprintf(&sb, ": %v", issue.GetCode().Value)
+		}
+	}
+	return sb.String()
+}
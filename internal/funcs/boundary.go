@@ -0,0 +1,139 @@
+package funcs
+
+import (
+	"context"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// Default digits of precision used by the boundary functions when no precision
+// argument is given.
+const (
+	defaultDecimalDigits  = 8
+	defaultDateDigits     = 8
+	defaultDateTimeDigits = 17
+	defaultTimeDigits     = 9
+)
+
+// precision implements the FHIRPath precision() function, which returns the
+// number of digits of precision of a Decimal, Date, DateTime, or Time.
+//
+// See: https://build.fhir.org/ig/HL7/FHIRPath/#precision--integer
+func precision(_ context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	value, ok, err := singleton(input)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+
+	var digits int
+	switch v := value.(type) {
+	case system.Integer:
+		digits = 0
+	case system.Decimal:
+		digits = v.Digits()
+	case system.Date:
+		digits = v.Digits()
+	case system.DateTime:
+		digits = v.Digits()
+	case system.Time:
+		digits = v.Digits()
+	default:
+		return collection.Empty, nil
+	}
+	return collection.Of(system.Integer(digits)), nil
+}
+
+// lowBoundary implements the FHIRPath lowBoundary([precision]) function, which
+// returns the least possible value of the input to the specified precision.
+//
+// See: https://build.fhir.org/ig/HL7/FHIRPath/#lowboundaryprecision-integer-decimal--date--datetime--time
+func lowBoundary(_ context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	return boundary(input, args, boundaries{
+		decimal:  system.Decimal.LowBoundary,
+		date:     system.Date.LowBoundary,
+		dateTime: system.DateTime.LowBoundary,
+		time:     system.Time.LowBoundary,
+	})
+}
+
+// highBoundary implements the FHIRPath highBoundary([precision]) function,
+// which returns the greatest possible value of the input to the specified
+// precision.
+//
+// See: https://build.fhir.org/ig/HL7/FHIRPath/#highboundaryprecision-integer-decimal--date--datetime--time
+func highBoundary(_ context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	return boundary(input, args, boundaries{
+		decimal:  system.Decimal.HighBoundary,
+		date:     system.Date.HighBoundary,
+		dateTime: system.DateTime.HighBoundary,
+		time:     system.Time.HighBoundary,
+	})
+}
+
+// boundaryResult infers the result of lowBoundary() and highBoundary() as the
+// System type of the input, where the boundaries of an Integer are Decimals.
+func boundaryResult(input types.Type, _ []types.Type) types.Type {
+	if t := input.Item().System(); t != types.Integer {
+		return t
+	}
+	return types.Decimal
+}
+
+// boundaries are the boundary methods of each type, for either the low or the
+// high boundary.
+type boundaries struct {
+	decimal  func(system.Decimal, int) (system.Decimal, bool)
+	date     func(system.Date, int) (system.Date, bool)
+	dateTime func(system.DateTime, int) (system.DateTime, bool)
+	time     func(system.Time, int) (system.Time, bool)
+}
+
+// boundary computes the boundary of the input using the method for its type.
+// If the precision is not valid for the type of the input, or the input is not
+// of a type that has boundaries, the result is empty.
+//
+// Dates that are requested with more than 8 digits of precision are treated as
+// DateTime values, so that a partial date such as @2020-03 may be expanded into
+// a concrete range of datetimes.
+func boundary(input collection.Collection, args []collection.Collection, b boundaries) (collection.Collection, error) {
+	value, ok, err := singleton(input)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+
+	var result any
+	switch v := value.(type) {
+	case system.Integer:
+		result, ok, err = boundaryOf(b.decimal, v.Decimal(), args, defaultDecimalDigits)
+	case system.Decimal:
+		result, ok, err = boundaryOf(b.decimal, v, args, defaultDecimalDigits)
+	case system.Date:
+		if digits, present, _ := integerArg(args, 0, 0); present && digits > defaultDateDigits {
+			result, ok, err = boundaryOf(b.dateTime, v.DateTime(), args, defaultDateTimeDigits)
+			break
+		}
+		result, ok, err = boundaryOf(b.date, v, args, defaultDateDigits)
+	case system.DateTime:
+		result, ok, err = boundaryOf(b.dateTime, v, args, defaultDateTimeDigits)
+	case system.Time:
+		result, ok, err = boundaryOf(b.time, v, args, defaultTimeDigits)
+	default:
+		return collection.Empty, nil
+	}
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	return collection.Of(result), nil
+}
+
+func boundaryOf[T any](fn func(T, int) (T, bool), value T, args []collection.Collection, def int) (T, bool, error) {
+	var zero T
+	digits, ok, err := integerArg(args, 0, def)
+	if err != nil || !ok {
+		return zero, false, err
+	}
+	result, ok := fn(value, digits)
+	return result, ok, nil
+}
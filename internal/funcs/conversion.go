@@ -0,0 +1,64 @@
+package funcs
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// longPattern matches strings that may be converted into a Long.
+var longPattern = regexp.MustCompile(`^[+-]?[0-9]+$`)
+
+// toLong implements the FHIRPath toLong() function, which converts the input
+// into a Long. If the input is not convertible, the result is empty.
+//
+// See: https://build.fhir.org/ig/HL7/FHIRPath/#tolong--long
+func toLong(_ context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	value, ok, err := singleton(input)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	long, ok := longOf(value)
+	if !ok {
+		return collection.Empty, nil
+	}
+	return collection.Of(long), nil
+}
+
+// convertsToLong implements the FHIRPath convertsToLong() function, which
+// returns whether the input is convertible into a Long.
+//
+// See: https://build.fhir.org/ig/HL7/FHIRPath/#convertstolong--boolean
+func convertsToLong(_ context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	value, ok, err := singleton(input)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	_, ok = longOf(value)
+	return collection.Of(system.Boolean(ok)), nil
+}
+
+// longOf converts a normalized value into a Long, following the conversion
+// rules of toLong().
+func longOf(value any) (system.Integer64, bool) {
+	switch v := value.(type) {
+	case system.Integer64:
+		return v, true
+	case system.Integer:
+		return system.Integer64(v), true
+	case system.Boolean:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case system.String:
+		if !longPattern.MatchString(string(v)) {
+			return 0, false
+		}
+		long, err := system.ParseInteger64(string(v))
+		return long, err == nil
+	}
+	return 0, false
+}
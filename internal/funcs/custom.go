@@ -0,0 +1,506 @@
+package funcs
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/namespace"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// ErrInvalidSignature is an error raised when the Go function that implements
+// a custom function does not have a signature that FHIRPath can invoke.
+var ErrInvalidSignature = errors.New("invalid function signature")
+
+// Param is a declared parameter of a function.
+type Param struct {
+	// Type is the type of the items that the argument may hold, which is
+	// checked at compile time. If unknown, the argument is unchecked.
+	Type types.Type
+
+	// Lambda indicates that the argument is passed to the function
+	// unevaluated, and so is evaluated against each item of the input.
+	Lambda bool
+}
+
+// Lambda is an argument of a custom function that is passed to it
+// unevaluated, so that the function may evaluate it against each item of its
+// input -- as with the criteria of 'where(criteria)'.
+type Lambda struct {
+	ctx  context.Context
+	expr expr.Expression
+}
+
+// Evaluate evaluates the expression against a single item of the input, with
+// '$this' referring to the item and '$index' referring to its index within the
+// input. The expression is evaluated in the context of the invocation of the
+// function, with the same environment variables.
+func (l Lambda) Evaluate(item any, index int) (collection.Collection, error) {
+	return expr.EvaluateItem(l.ctx, l.expr, item, index)
+}
+
+var (
+	contextType    = reflect.TypeOf((*context.Context)(nil)).Elem()
+	collectionType = reflect.TypeOf(collection.Collection(nil))
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	lambdaType     = reflect.TypeOf(Lambda{})
+)
+
+// Reflect returns the definition of the function named name, as implemented
+// by the Go function fn. The function must have the signature:
+//
+//	func([context.Context,] collection.Collection, params...) (collection.Collection, error)
+//
+// where the collection is the input of the function, and each parameter
+// receives an argument:
+//
+//   - A collection.Collection receives the evaluated argument as is.
+//   - A Lambda receives the argument unevaluated.
+//   - A System type, such as system.String, or a FHIR type, such as
+//     *fhir.Coding, receives the single item of the evaluated argument,
+//     converted into the type. If the argument is empty, the function is not
+//     invoked, and its result is empty.
+//
+// If fn is variadic, its last parameter receives any number of arguments.
+// The parameters may be named for the signature of the function, in which
+// case there must be a name for each.
+//
+// If fn does not have such a signature, an error wrapping ErrInvalidSignature
+// is returned.
+func Reflect(name string, fn any, paramNames ...string) (*Function, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("%w: expected a function, got %T", ErrInvalidSignature, fn)
+	}
+	t := v.Type()
+	if t.NumOut() != 2 || t.Out(0) != collectionType || t.Out(1) != errorType {
+		return nil, fmt.Errorf("%w: expected results (collection.Collection, error), got %v", ErrInvalidSignature, t)
+	}
+
+	r := &reflected{fn: v, variadic: t.IsVariadic()}
+	first := 0
+	if t.NumIn() > 0 && t.In(0) == contextType {
+		r.context = true
+		first = 1
+	}
+	if t.NumIn() <= first || t.In(first) != collectionType || r.variadic && t.NumIn() == first+1 {
+		return nil, fmt.Errorf("%w: expected an input collection.Collection parameter, got %v", ErrInvalidSignature, t)
+	}
+	if len(paramNames) > 0 && len(paramNames) != t.NumIn()-first-1 {
+		return nil, fmt.Errorf("%w: expected %d parameter names, got %d", ErrInvalidSignature, t.NumIn()-first-1, len(paramNames))
+	}
+
+	var lambda bool
+	var signature []string
+	params := make([]Param, 0, t.NumIn()-first-1)
+	for i := first + 1; i < t.NumIn(); i++ {
+		typ := t.In(i)
+		if r.variadic && i == t.NumIn()-1 {
+			typ = typ.Elem()
+		}
+		param, ok := paramOf(typ)
+		if !ok {
+			return nil, fmt.Errorf("%w: parameter %d has unsupported type %v", ErrInvalidSignature, len(params)+1, typ)
+		}
+		lambda = lambda || param.Lambda
+		r.params = append(r.params, typ)
+		params = append(params, param)
+
+		name := paramName(param, typ)
+		if r.variadic && i == t.NumIn()-1 {
+			name += "..."
+		}
+		if len(paramNames) > 0 {
+			name = paramNames[len(params)-1] + " : " + name
+		}
+		signature = append(signature, name)
+	}
+
+	result := &Function{
+		MinArgs:   len(params),
+		MaxArgs:   len(params),
+		Variadic:  r.variadic,
+		Params:    params,
+		Result:    returns(types.Unknown.WithList(true)),
+		Signature: fmt.Sprintf("%v(%v) : collection", name, strings.Join(signature, ", ")),
+		reflected: r,
+	}
+	if r.variadic {
+		result.MinArgs, result.MaxArgs = len(params)-1, math.MaxInt
+	}
+	if lambda {
+		result.Lambda = r.lambda
+	} else {
+		result.Func = r.call
+	}
+	return result, nil
+}
+
+// Overload returns the definition of the function named name that has each
+// of the overloads, as returned by Reflect. The overload that is invoked is
+// selected by the values of the arguments, as the one that accepts them with
+// the fewest implicit conversions; the first such overload is preferred.
+//
+// The overloads must differ in their parameters, and must agree on which of
+// their parameters are Lambdas, since those arguments are compiled
+// differently; otherwise an error wrapping ErrInvalidSignature is returned.
+func Overload(name string, overloads ...*Function) (*Function, error) {
+	if len(overloads) == 1 {
+		return overloads[0], nil
+	}
+	o := &overloaded{}
+	result := &Function{
+		MinArgs:   math.MaxInt,
+		Result:    returns(types.Unknown.WithList(true)),
+		Lambda:    o.lambda,
+		Overloads: overloads,
+	}
+	var signatures, docs []string
+	for i, overload := range overloads {
+		if overload.reflected == nil {
+			return nil, fmt.Errorf("%w: overload %d of function '%v' is not a custom function", ErrInvalidSignature, i+1, name)
+		}
+		for _, other := range overloads[:i] {
+			if slices.Equal(overload.reflected.params, other.reflected.params) && overload.Variadic == other.Variadic {
+				return nil, fmt.Errorf("%w: overloads of function '%v' have the same parameters", ErrInvalidSignature, name)
+			}
+		}
+		result.MinArgs = min(result.MinArgs, overload.MinArgs)
+		result.MaxArgs = max(result.MaxArgs, overload.MaxArgs)
+		o.overloads = append(o.overloads, overload.reflected)
+		signatures = append(signatures, overload.Signature)
+		if overload.Doc != "" && !slices.Contains(docs, overload.Doc) {
+			docs = append(docs, overload.Doc)
+		}
+	}
+	// The Lambdas of the function itself are those of its overloads, up to
+	// the last parameter of the longest overload and one past it, in case it
+	// is variadic.
+	var n int
+	for _, overload := range overloads {
+		n = max(n, len(overload.Params)+1)
+	}
+	for i := range n {
+		var lambdas []bool
+		for _, overload := range overloads {
+			if param, ok := overload.Param(i); ok {
+				lambdas = append(lambdas, param.Lambda)
+			}
+		}
+		if len(lambdas) == 0 {
+			break
+		}
+		if slices.Contains(lambdas, !lambdas[0]) {
+			return nil, fmt.Errorf("%w: overloads of function '%v' disagree on whether argument %d is an expression", ErrInvalidSignature, name, i+1)
+		}
+		result.Params = append(result.Params, Param{Lambda: lambdas[0]})
+	}
+	result.Variadic = slices.ContainsFunc(overloads, func(overload *Function) bool {
+		return overload.Variadic
+	})
+	result.Signature = strings.Join(signatures, "\n")
+	result.Doc = strings.Join(docs, "\n")
+	return result, nil
+}
+
+// Rename returns a copy of the function f, as returned by Reflect or Overload,
+// whose signature documents it as named name, so that a function may be
+// reflected once and added under several names.
+func Rename(f *Function, name string) *Function {
+	result := *f
+	lines := strings.Split(f.Signature, "\n")
+	for i, line := range lines {
+		if _, params, ok := strings.Cut(line, "("); ok {
+			lines[i] = name + "(" + params
+		}
+	}
+	result.Signature = strings.Join(lines, "\n")
+	if len(f.Overloads) > 0 {
+		result.Overloads = make([]*Function, 0, len(f.Overloads))
+		for _, overload := range f.Overloads {
+			result.Overloads = append(result.Overloads, Rename(overload, name))
+		}
+	}
+	return &result
+}
+
+// paramOf returns the declaration of a parameter of the Go type t, if it is a
+// type that an argument may be converted into.
+func paramOf(t reflect.Type) (Param, bool) {
+	switch {
+	case t == collectionType:
+		return Param{Type: types.Unknown}, true
+	case t == lambdaType:
+		return Param{Type: types.Unknown, Lambda: true}, true
+	case t.Kind() == reflect.Interface && t.NumMethod() == 0:
+		return Param{}, false
+	case namespace.System.Contains(t) && t.Kind() != reflect.Pointer:
+		typ, ok := types.Parse(namespace.System.QualifiedName(t))
+		return Param{Type: typ}, ok
+	case namespace.R4.Contains(t) && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface):
+		typ, ok := types.Parse(namespace.R4.QualifiedName(t))
+		return Param{Type: typ}, ok
+	}
+	return Param{}, false
+}
+
+// paramName returns the name of the type of a parameter in a signature.
+func paramName(param Param, t reflect.Type) string {
+	switch {
+	case param.Lambda:
+		return "expression"
+	case t == collectionType:
+		return "collection"
+	}
+	return param.Type.String()
+}
+
+// reflected is a custom function that is implemented by a Go function.
+type reflected struct {
+	fn reflect.Value
+
+	// context indicates that fn takes a context.Context before its input.
+	context bool
+
+	// params are the types of the parameters of fn that follow its input. The
+	// last parameter is of the type of each of its arguments if fn is
+	// variadic.
+	params []reflect.Type
+
+	// variadic indicates that the last parameter of fn is variadic.
+	variadic bool
+}
+
+// argument is an argument of a custom function: either the expression of a
+// Lambda, or the evaluated collection.
+type argument struct {
+	expr  expr.Expression
+	value collection.Collection
+}
+
+// binding is the arguments of a custom function, converted into the values of
+// the parameters of the Go function.
+type binding struct {
+	in []reflect.Value
+
+	// conversions is the number of arguments that were implicitly converted.
+	conversions int
+
+	// empty indicates that an argument that is converted into a single value
+	// is empty, so that the function is not invoked.
+	empty bool
+}
+
+// paramType returns the Go type of the parameter that receives the i'th
+// argument, or nil if there is no such parameter.
+func (r *reflected) paramType(i int) reflect.Type {
+	if r.variadic && i >= len(r.params)-1 {
+		return r.params[len(r.params)-1]
+	}
+	if i >= len(r.params) {
+		return nil
+	}
+	return r.params[i]
+}
+
+// call invokes the function with evaluated arguments.
+func (r *reflected) call(ctx context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	arguments := make([]argument, 0, len(args))
+	for _, arg := range args {
+		arguments = append(arguments, argument{value: arg})
+	}
+	return r.invoke(ctx, input, arguments)
+}
+
+// lambda invokes the function with unevaluated arguments, which are evaluated
+// against the input unless their parameter is a Lambda.
+func (r *reflected) lambda(ctx context.Context, input collection.Collection, args ...expr.Expression) (collection.Collection, error) {
+	arguments, err := evaluate(ctx, input, args, func(i int) bool {
+		return r.paramType(i) == lambdaType
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.invoke(ctx, input, arguments)
+}
+
+// invoke invokes the function with the arguments.
+func (r *reflected) invoke(ctx context.Context, input collection.Collection, args []argument) (collection.Collection, error) {
+	b, err := r.bind(ctx, input, args)
+	if err != nil {
+		return nil, err
+	}
+	return r.invokeWith(b)
+}
+
+// invokeWith invokes the function with its bound arguments.
+func (r *reflected) invokeWith(b *binding) (collection.Collection, error) {
+	if b.empty {
+		return collection.Empty, nil
+	}
+	out := r.fn.Call(b.in)
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, err
+	}
+	return out[0].Interface().(collection.Collection), nil
+}
+
+// bind converts the arguments into the parameters of the function. If an
+// argument cannot be converted, an error is returned.
+func (r *reflected) bind(ctx context.Context, input collection.Collection, args []argument) (*binding, error) {
+	b := &binding{in: make([]reflect.Value, 0, len(args)+2)}
+	if r.context {
+		b.in = append(b.in, reflect.ValueOf(&ctx).Elem())
+	}
+	b.in = append(b.in, reflect.ValueOf(input))
+	for i, arg := range args {
+		t := r.paramType(i)
+		switch {
+		case t == lambdaType:
+			b.in = append(b.in, reflect.ValueOf(Lambda{ctx: ctx, expr: arg.expr}))
+			continue
+		case t == collectionType:
+			b.in = append(b.in, reflect.ValueOf(arg.value))
+			continue
+		case arg.value.IsEmpty():
+			b.in = append(b.in, reflect.Zero(t))
+			b.empty = true
+			continue
+		}
+		item, err := arg.value.Singleton()
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		value, conversions, ok := convert(item, t)
+		if !ok {
+			return nil, fmt.Errorf("argument %d: expected %v, got %T", i+1, t, item)
+		}
+		b.in = append(b.in, value)
+		b.conversions += conversions
+	}
+	return b, nil
+}
+
+// evaluate evaluates the arguments of a custom function against its input,
+// except for those that are Lambdas.
+func evaluate(ctx context.Context, input collection.Collection, args []expr.Expression, lambda func(int) bool) ([]argument, error) {
+	result := make([]argument, 0, len(args))
+	for i, arg := range args {
+		if lambda(i) {
+			result = append(result, argument{expr: arg})
+			continue
+		}
+		value, err := arg.Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, argument{expr: arg, value: value})
+	}
+	return result, nil
+}
+
+// overloaded is a custom function with several overloads.
+type overloaded struct {
+	overloads []*reflected
+}
+
+// lambda invokes the overload that accepts the arguments with the fewest
+// implicit conversions. Arguments are evaluated once, before the overload is
+// selected.
+func (o *overloaded) lambda(ctx context.Context, input collection.Collection, args ...expr.Expression) (collection.Collection, error) {
+	arguments, err := evaluate(ctx, input, args, func(i int) bool {
+		for _, r := range o.overloads {
+			if t := r.paramType(i); t != nil {
+				return t == lambdaType
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var best *binding
+	var selected *reflected
+	var firstErr error
+	for _, r := range o.overloads {
+		if len(args) < len(r.params)-btoi(r.variadic) || len(args) > len(r.params) && !r.variadic {
+			continue
+		}
+		b, err := r.bind(ctx, input, arguments)
+		if err != nil {
+			firstErr = cmp.Or(firstErr, err)
+			continue
+		}
+		if best == nil || b.conversions < best.conversions {
+			best, selected = b, r
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("no overload accepts the arguments: %w", firstErr)
+	}
+	return selected.invokeWith(best)
+}
+
+// btoi returns 1 if b is true, and 0 otherwise.
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// convert converts the item of an argument into the Go type t of a parameter.
+// FHIR primitives are converted into System types, and System types into FHIR
+// primitives, as are the System types that implicitly convert into one
+// another -- such as an Integer into a Decimal.
+func convert(item any, t reflect.Type) (value reflect.Value, conversions int, ok bool) {
+	if item == nil {
+		return reflect.Value{}, 0, false
+	}
+	normalized := system.Normalize(item)
+	for _, candidate := range []any{item, normalized} {
+		if v := reflect.ValueOf(candidate); v.Type().AssignableTo(t) {
+			return v, 0, true
+		}
+	}
+	var implicit []any
+	switch normalized := normalized.(type) {
+	case system.Integer:
+		implicit = append(implicit, system.Integer64(normalized), normalized.Decimal())
+	case system.Integer64:
+		implicit = append(implicit, normalized.Decimal())
+	case system.Date:
+		implicit = append(implicit, normalized.DateTime())
+	}
+	for _, candidate := range implicit {
+		if v := reflect.ValueOf(candidate); v.Type().AssignableTo(t) {
+			return v, 1, true
+		}
+	}
+	value, ok = toR4(normalized, t)
+	return value, 1, ok
+}
+
+// toR4 converts a System value into the FHIR type t, if the value has a FHIR
+// representation of that type.
+func toR4(value any, t reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(value)
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	method := ptr.MethodByName("R4")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return reflect.Value{}, false
+	}
+	result := method.Call(nil)[0]
+	return result, result.Type().AssignableTo(t)
+}
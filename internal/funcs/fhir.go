@@ -0,0 +1,231 @@
+package funcs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/conformance"
+	"github.com/friendly-fhir/go-fhirpath/internal/evalcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/internal/xhtml"
+	"github.com/friendly-fhir/go-fhirpath/resolver"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// extension implements the FHIR extension(url) function, which returns the
+// extensions of each input item that are identified by the url. This applies
+// equally to the extensions of primitive elements, such as the '_birthDate'
+// extensions of a Patient.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func extension(_ context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	arg, ok, err := singleton(args[0])
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	url, isString := arg.(system.String)
+	if !isString {
+		return nil, fmt.Errorf("argument 1: expected String, got %T", arg)
+	}
+
+	var result collection.Collection
+	for _, item := range input {
+		for _, ext := range model.Children(item, "extension") {
+			for _, value := range model.Children(ext, "url") {
+				if value == url {
+					result = append(result, ext)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// resolve implements the FHIR resolve() function, which returns the resources
+// referred to by each input item. Items may be References, or the String, uri,
+// or canonical values of references. Items that are not references are
+// ignored.
+//
+// Contained references, such as '#id', are resolved against the contained
+// resources of the root resource of the reference, such as the resource of
+// the Bundle entry that holds it. All other references are resolved with the
+// configured resolver, with the item as the referrer of the reference. A
+// reference that is not found is omitted; a reference that the resolver fails
+// to resolve is also omitted, unless resolution is strict.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func resolve(ctx context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	cfg := evalcontext.From(ctx)
+
+	var result collection.Collection
+	for _, item := range input {
+		reference, ok := referenceOf(item)
+		if !ok {
+			continue
+		}
+		if id, ok := strings.CutPrefix(reference, "#"); ok {
+			result = append(result, containedOf(containerOf(cfg.Root, item), id)...)
+			continue
+		}
+		if cfg.Resolver == nil {
+			continue
+		}
+		resource, err := cfg.Resolver.Resolve(resolver.WithReferrer(ctx, item), reference)
+		if err != nil {
+			if cfg.StrictResolve || ctx.Err() != nil {
+				return nil, fmt.Errorf("reference '%v': %w", reference, err)
+			}
+			continue
+		}
+		if resource != nil {
+			result = append(result, resource)
+		}
+	}
+	return result, nil
+}
+
+// referenceOf returns the reference that an item refers to.
+func referenceOf(item any) (string, bool) {
+	if model.TypeName(item) == "Reference" {
+		for _, value := range model.Children(item, "reference") {
+			return referenceOf(value)
+		}
+		return "", false
+	}
+	if str, ok := system.Normalize(item).(system.String); ok && str != "" {
+		return string(str), true
+	}
+	return "", false
+}
+
+// containerOf returns the resource whose contained resources a contained
+// reference within root refers to: the root resource of the item, as found
+// within root. If the item cannot be found, such as a String that is not an
+// element of root, root itself is the container.
+func containerOf(root, item any) any {
+	if _, rootResource, ok := model.Locate(root, item); ok {
+		return rootResource
+	}
+	return root
+}
+
+// containedOf returns the resource contained in root with the specified id.
+// An empty id refers to the root resource itself.
+func containedOf(root any, id string) []any {
+	if root == nil {
+		return nil
+	}
+	if id == "" {
+		return []any{root}
+	}
+	for _, resource := range model.Children(root, "contained") {
+		for _, value := range model.Children(resource, "id") {
+			if value == system.String(id) {
+				return []any{resource}
+			}
+		}
+	}
+	return nil
+}
+
+// hasValue implements the FHIR hasValue() function, which returns whether the
+// input is a single FHIR primitive that has a value, as opposed to one that
+// only has extensions.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func hasValue(_ context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	if !input.IsSingleton() {
+		return collection.False, nil
+	}
+	return collection.Of(system.Boolean(primitiveValue(input[0]) != nil)), nil
+}
+
+// primitiveValue returns the value of a FHIR primitive, or nil if it has no
+// value. Primitives that the model holds as native Go values, such as 'id' or
+// 'Extension.url', are already System values, and only present if non-empty.
+func primitiveValue(item any) any {
+	if value, ok := model.PrimitiveValue(item); ok {
+		return value
+	}
+	if value, ok := item.(system.Any); ok {
+		return value
+	}
+	return nil
+}
+
+// getValue implements the FHIR getValue() function, which returns the System
+// value of the input if it is a single FHIR primitive that has a value.
+// Otherwise, the result is empty.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func getValue(_ context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	if !input.IsSingleton() {
+		return collection.Empty, nil
+	}
+	value := primitiveValue(input[0])
+	if value == nil {
+		return collection.Empty, nil
+	}
+	if normalized, ok := system.Normalize(input[0]).(system.Any); ok {
+		value = normalized
+	}
+	return collection.Of(value), nil
+}
+
+// htmlChecks implements the FHIR htmlChecks() function, which returns whether
+// the input is XHTML that conforms to the FHIR rules for narratives. If the
+// input is empty, the result is empty.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func htmlChecks(_ context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	if input.IsEmpty() {
+		return collection.Empty, nil
+	}
+	if !input.IsSingleton() {
+		return nil, fmt.Errorf("input: %w", collection.ErrNotSingleton)
+	}
+	value, ok := model.PrimitiveValue(input[0])
+	if !ok {
+		value = system.Normalize(input[0])
+	}
+	source, ok := value.(system.String)
+	if !ok {
+		return collection.False, nil
+	}
+	return collection.Of(system.Boolean(xhtml.Validate(string(source)) == nil)), nil
+}
+
+// conformsTo implements the FHIR conformsTo(url) function, which returns
+// whether the input resource conforms to the profile identified by the url,
+// as determined by the configured validator. If the input is empty, the result
+// is empty.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func conformsTo(ctx context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	if input.IsEmpty() {
+		return collection.Empty, nil
+	}
+	if !input.IsSingleton() {
+		return nil, fmt.Errorf("input: %w", collection.ErrNotSingleton)
+	}
+	arg, ok, err := singleton(args[0])
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	url, isString := system.Normalize(arg).(system.String)
+	if !isString {
+		return nil, fmt.Errorf("argument 1: expected String, got %T", arg)
+	}
+
+	var validator conformance.Validator = conformance.BaseValidator{}
+	if cfg := evalcontext.From(ctx); cfg.ProfileValidator != nil {
+		validator = cfg.ProfileValidator
+	}
+	ok, err = validator.ConformsTo(ctx, input[0], string(url))
+	if err != nil {
+		return nil, err
+	}
+	return collection.Of(system.Boolean(ok)), nil
+}
@@ -0,0 +1,375 @@
+/*
+Package funcs provides the implementations of the FHIRPath built-in functions,
+organized into tables for each version of the FHIRPath language.
+*/
+package funcs
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// Function is the definition of a FHIRPath function. Exactly one of Func or
+// Lambda is set.
+type Function struct {
+	// Func is the implementation of a function whose arguments are evaluated
+	// against the input before the function is invoked.
+	Func expr.Function
+
+	// Lambda is the implementation of a function whose arguments are passed to
+	// it unevaluated, such as the criteria of 'where'.
+	Lambda expr.LambdaFunction
+
+	// MinArgs is the least number of arguments the function accepts.
+	MinArgs int
+
+	// MaxArgs is the greatest number of arguments the function accepts.
+	MaxArgs int
+
+	// TypeArg indicates that the argument of the function is a type specifier
+	// rather than an expression, such as the 'Quantity' of 'ofType(Quantity)'.
+	TypeArg bool
+
+	// Input are the types of the input items that the function may be
+	// invoked on, which are checked at compile time when the type of the input
+	// is known. If empty, the function may be invoked on any input.
+	Input []types.Type
+
+	// Params are the declared parameters of the function, whose arguments are
+	// checked against them at compile time. If nil, the arguments are
+	// unchecked, and are all passed unevaluated if Lambda is set.
+	Params []Param
+
+	// Variadic indicates that the last of the Params receives any number of
+	// arguments, including none.
+	Variadic bool
+
+	// Overloads are the overloads of a function that has more than one, which
+	// the arguments must be accepted by one of at compile time. The Params of
+	// the function itself only declare which arguments are Lambdas.
+	Overloads []*Function
+
+	// Result infers the type of the result of the function from the types of
+	// its input and arguments. The argument of a function with a TypeArg is of
+	// the type that it names. If nil, the result is of an unknown type.
+	Result func(input types.Type, args []types.Type) types.Type
+
+	// Signature is the signature of the function as it is documented, such as
+	// 'memberOf(valueset : String) : Boolean'.
+	Signature string
+
+	// Doc is a short description of the function.
+	Doc string
+
+	// reflected is the Go implementation of a custom function.
+	reflected *reflected
+}
+
+// Accepts returns whether the function may be invoked on an input of the type.
+func (f *Function) Accepts(input types.Type) bool {
+	if len(f.Input) == 0 {
+		return true
+	}
+	for _, t := range f.Input {
+		if input.Item().AssignableTo(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLambda returns whether the i'th argument of the function is passed to it
+// unevaluated.
+func (f *Function) IsLambda(i int) bool {
+	if f.Lambda == nil {
+		return false
+	}
+	if param, ok := f.Param(i); ok {
+		return param.Lambda
+	}
+	return len(f.Params) == 0
+}
+
+// Param returns the declared parameter that receives the i'th argument. If the
+// parameters are not declared, or there is no such parameter, ok is false.
+func (f *Function) Param(i int) (param Param, ok bool) {
+	if f.Variadic && i >= len(f.Params)-1 && len(f.Params) > 0 {
+		return f.Params[len(f.Params)-1], true
+	}
+	if i >= len(f.Params) {
+		return Param{}, false
+	}
+	return f.Params[i], true
+}
+
+// AcceptsArgs returns whether the function, or one of its overloads, accepts
+// arguments of the types.
+func (f *Function) AcceptsArgs(args []types.Type) bool {
+	if len(f.Overloads) > 0 {
+		return slices.ContainsFunc(f.Overloads, func(overload *Function) bool {
+			return overload.AcceptsArgs(args)
+		})
+	}
+	if len(args) < f.MinArgs || len(args) > f.MaxArgs {
+		return false
+	}
+	for i, arg := range args {
+		if param, ok := f.Param(i); ok && !arg.Item().AssignableTo(param.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// returns infers the result of a function as always being of type t.
+func returns(t types.Type) func(types.Type, []types.Type) types.Type {
+	return func(types.Type, []types.Type) types.Type {
+		return t
+	}
+}
+
+// sameAsInput infers the result of a function as being of the same type as its
+// input.
+func sameAsInput(input types.Type, _ []types.Type) types.Type {
+	return input
+}
+
+// systemOfInput infers the result of a function as being a single value of the
+// System type of its input.
+func systemOfInput(input types.Type, _ []types.Type) types.Type {
+	return input.Item().System()
+}
+
+var (
+	// precise are the types of values that have a precision, which the
+	// precision and boundary functions may be invoked on.
+	precise = []types.Type{types.Decimal, types.Date, types.DateTime, types.Time}
+
+	// coded are the types of the inputs of the terminology functions.
+	coded = []types.Type{types.String, {Name: "FHIR.Coding"}, {Name: "FHIR.CodeableConcept"}}
+)
+
+// Table is a collection of FHIRPath functions, indexed by name.
+type Table map[string]*Function
+
+// Clone returns a shallow copy of this table, so that functions may be added
+// without modifying the original.
+func (t Table) Clone() Table {
+	return maps.Clone(t)
+}
+
+var (
+	// N1 is the table of functions defined in the normative FHIRPath N1
+	// release, along with the functions that FHIR adds to every version.
+	N1 = Table{
+		"ofType": {
+			Lambda: ofType, MinArgs: 1, MaxArgs: 1, TypeArg: true,
+			Result:    ofTypeResult,
+			Signature: "ofType(type : type specifier) : collection",
+			Doc:       "Returns the items of the input that are of the specified type.",
+		},
+		"is": {
+			Lambda: is, MinArgs: 1, MaxArgs: 1, TypeArg: true,
+			Result:    returns(types.Boolean),
+			Signature: "is(type : type specifier) : Boolean",
+			Doc:       "Returns whether the single item of the input is of the specified type.",
+		},
+		"as": {
+			Lambda: as, MinArgs: 1, MaxArgs: 1, TypeArg: true,
+			Result:    asResult,
+			Signature: "as(type : type specifier) : collection",
+			Doc:       "Returns the single item of the input if it is of the specified type.",
+		},
+		"type": {
+			Func: typeOf, MinArgs: 0, MaxArgs: 0,
+			Result:    typeOfResult,
+			Signature: "type() : collection",
+			Doc:       "Returns the reflected type information of each item of the input.",
+		},
+
+		"first": {
+			Func: first, MinArgs: 0, MaxArgs: 0,
+			Result:    itemOfInput,
+			Signature: "first() : collection",
+			Doc:       "Returns the first item of the input, or empty if the input is empty.",
+		},
+
+		"extension": {
+			Func: extension, MinArgs: 1, MaxArgs: 1,
+			Result:    returns(types.Type{Name: "FHIR.Extension", List: true}),
+			Signature: "extension(url : String) : collection",
+			Doc:       "Returns the extensions of the input that are identified by the url.",
+		},
+		"resolve": {
+			Func: resolve, MinArgs: 0, MaxArgs: 0,
+			Input:     []types.Type{{Name: "FHIR.Reference"}, types.String},
+			Result:    returns(types.Type{Name: "FHIR.Resource", List: true}),
+			Signature: "resolve() : collection",
+			Doc:       "Returns the resources that the input references refer to.",
+		},
+		"hasValue": {
+			Func: hasValue, MinArgs: 0, MaxArgs: 0,
+			Result:    returns(types.Boolean),
+			Signature: "hasValue() : Boolean",
+			Doc:       "Returns whether the input is a single FHIR primitive that has a value.",
+		},
+		"getValue": {
+			Func: getValue, MinArgs: 0, MaxArgs: 0,
+			Result:    systemOfInput,
+			Signature: "getValue() : System.[type]",
+			Doc:       "Returns the System value of the input, if it is a single FHIR primitive that has a value.",
+		},
+		"htmlChecks": {
+			Func: htmlChecks, MinArgs: 0, MaxArgs: 0,
+			Input:     []types.Type{types.String},
+			Result:    returns(types.Boolean),
+			Signature: "htmlChecks() : Boolean",
+			Doc:       "Returns whether the input is XHTML that conforms to the FHIR rules for narratives.",
+		},
+		"conformsTo": {
+			Func: conformsTo, MinArgs: 1, MaxArgs: 1,
+			Result:    returns(types.Boolean),
+			Signature: "conformsTo(structure : String) : Boolean",
+			Doc:       "Returns whether the input resource conforms to the profile identified by the url.",
+		},
+		"memberOf": {
+			Func: memberOf, MinArgs: 1, MaxArgs: 1,
+			Input:     coded,
+			Result:    returns(types.Boolean),
+			Signature: "memberOf(valueset : String) : Boolean",
+			Doc:       "Returns whether the input code, Coding, or CodeableConcept is a member of the value set.",
+		},
+		"subsumes": {
+			Func: subsumes, MinArgs: 1, MaxArgs: 1,
+			Input:     coded,
+			Result:    returns(types.Boolean),
+			Signature: "subsumes(code : Coding | CodeableConcept) : Boolean",
+			Doc:       "Returns whether the input code subsumes the code of the argument.",
+		},
+		"subsumedBy": {
+			Func: subsumedBy, MinArgs: 1, MaxArgs: 1,
+			Input:     coded,
+			Result:    returns(types.Boolean),
+			Signature: "subsumedBy(code : Coding | CodeableConcept) : Boolean",
+			Doc:       "Returns whether the input code is subsumed by the code of the argument.",
+		},
+
+		"toLong": {
+			Func: toLong, MinArgs: 0, MaxArgs: 0,
+			Result:    returns(types.Integer64),
+			Signature: "toLong() : Long",
+			Doc:       "Converts the input into a Long, if it is convertible.",
+		},
+		"convertsToLong": {
+			Func: convertsToLong, MinArgs: 0, MaxArgs: 0,
+			Result:    returns(types.Boolean),
+			Signature: "convertsToLong() : Boolean",
+			Doc:       "Returns whether the input is convertible into a Long.",
+		},
+
+		"sort": {
+			Lambda: sort, MinArgs: 0, MaxArgs: math.MaxInt,
+			Result:    sameAsInput,
+			Signature: "sort([key : expression, ...]) : collection",
+			Doc:       "Returns the input sorted by the keys; a key prefixed with '-' sorts in descending order.",
+		},
+	}
+
+	// N2 is the table of functions defined in the FHIRPath N2 release. This
+	// includes every function of N1.
+	N2 Table
+)
+
+// Spec is the set of names of the functions that the FHIRPath specification,
+// and FHIR's additions to it, define -- including those that are not yet
+// implemented by any table.
+var Spec = map[string]struct{}{}
+
+func init() {
+	for _, name := range strings.Fields(`
+		empty exists all allTrue anyTrue allFalse anyFalse subsetOf supersetOf
+		count distinct isDistinct where select repeat ofType single first last
+		tail skip take intersect exclude union combine iif
+		toBoolean convertsToBoolean toInteger convertsToInteger toLong
+		convertsToLong toDate convertsToDate toDateTime convertsToDateTime
+		toDecimal convertsToDecimal toQuantity convertsToQuantity toString
+		convertsToString toTime convertsToTime
+		indexOf lastIndexOf substring startsWith endsWith contains upper lower
+		replace matches matchesFull replaceMatches length toChars trim split join
+		encode decode escape unescape
+		abs ceiling exp floor ln log power round sqrt truncate
+		children descendants trace aggregate now timeOfDay today not is as type
+		precision lowBoundary highBoundary sort
+		extension hasValue getValue resolve elementDefinition slice
+		checkModifiers conformsTo memberOf subsumes subsumedBy htmlChecks
+		comparable weight
+	`) {
+		Spec[name] = struct{}{}
+	}
+}
+
+func init() {
+	N2 = N1.Clone()
+	maps.Copy(N2, Table{
+		"precision": {
+			Func: precision, MinArgs: 0, MaxArgs: 0,
+			Input:     precise,
+			Result:    returns(types.Integer),
+			Signature: "precision() : Integer",
+			Doc:       "Returns the number of digits of precision of the input.",
+		},
+		"lowBoundary": {
+			Func: lowBoundary, MinArgs: 0, MaxArgs: 1,
+			Input:     precise,
+			Result:    boundaryResult,
+			Signature: "lowBoundary([precision : Integer]) : Decimal | Date | DateTime | Time",
+			Doc:       "Returns the least possible value of the input to the specified precision.",
+		},
+		"highBoundary": {
+			Func: highBoundary, MinArgs: 0, MaxArgs: 1,
+			Input:     precise,
+			Result:    boundaryResult,
+			Signature: "highBoundary([precision : Integer]) : Decimal | Date | DateTime | Time",
+			Doc:       "Returns the greatest possible value of the input to the specified precision.",
+		},
+	})
+}
+
+// singleton returns the single value of the input, normalized into its System
+// type. If the input is empty, ok is false.
+func singleton(input collection.Collection) (value any, ok bool, err error) {
+	if input.IsEmpty() {
+		return nil, false, nil
+	}
+	value, err = input.Singleton()
+	if err != nil {
+		return nil, false, fmt.Errorf("input: %w", err)
+	}
+	return system.Normalize(value), true, nil
+}
+
+// integerArg returns the value of an optional Integer argument. If the argument
+// is absent, def is returned. If the argument is present but empty, ok is
+// false.
+func integerArg(args []collection.Collection, i int, def int) (value int, ok bool, err error) {
+	if i >= len(args) {
+		return def, true, nil
+	}
+	arg, ok, err := singleton(args[i])
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	integer, isInteger := arg.(system.Integer)
+	if !isInteger {
+		return 0, false, fmt.Errorf("argument %d: expected Integer, got %T", i+1, arg)
+	}
+	return int(integer), true, nil
+}
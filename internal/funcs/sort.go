@@ -0,0 +1,117 @@
+package funcs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// sortKey is a single key of the sort() function, along with its direction.
+type sortKey struct {
+	expr       expr.Expression
+	descending bool
+}
+
+// sortKeysOf converts the arguments of sort() into sort keys. A key that is
+// negated with the unary '-' operator sorts in descending order.
+func sortKeysOf(args []expr.Expression) []sortKey {
+	keys := make([]sortKey, 0, len(args))
+	for _, arg := range args {
+		if p, ok := arg.(*expr.Polarity); ok && p.Operator == "-" {
+			keys = append(keys, sortKey{expr: p.Expression, descending: true})
+			continue
+		}
+		keys = append(keys, sortKey{expr: arg})
+	}
+	return keys
+}
+
+// sort implements the FHIRPath sort() function, which returns the input sorted
+// by each of the key expressions, in order. Without any keys, the items are
+// sorted by their own values. The sort is stable, and items with an empty key
+// sort before all others. Strings are sorted by the collation of the FHIRPath
+// comparison operators, which orders them by the Unicode code points of their
+// characters, as described by system.String.Compare.
+//
+// If any two keys cannot be compared, an error wrapping
+// system.ErrNotComparable is returned.
+//
+// See: https://build.fhir.org/ig/HL7/FHIRPath/#sortkey--expression--collection
+func sort(ctx context.Context, input collection.Collection, args ...expr.Expression) (collection.Collection, error) {
+	keys := sortKeysOf(args)
+	if len(keys) == 0 {
+		keys = []sortKey{{expr: expr.This{}}}
+	}
+
+	type item struct {
+		value any
+		keys  []any
+	}
+	items := make([]item, 0, len(input))
+	for i, value := range input {
+		values := make([]any, 0, len(keys))
+		for j, key := range keys {
+			result, err := expr.EvaluateItem(ctx, key.expr, value, i)
+			if err != nil {
+				return nil, err
+			}
+			if result.IsEmpty() {
+				values = append(values, nil)
+				continue
+			}
+			v, err := result.Singleton()
+			if err != nil {
+				return nil, fmt.Errorf("key %d: %w", j+1, err)
+			}
+			values = append(values, v)
+		}
+		items = append(items, item{value: value, keys: values})
+	}
+
+	var err error
+	slices.SortStableFunc(items, func(lhs, rhs item) int {
+		for i, key := range keys {
+			result, cmpErr := compareKeys(lhs.keys[i], rhs.keys[i])
+			if cmpErr != nil {
+				if err == nil {
+					err = fmt.Errorf("key %d: %w", i+1, cmpErr)
+				}
+				return 0
+			}
+			if key.descending {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(collection.Collection, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.value)
+	}
+	return result, nil
+}
+
+// compareKeys compares two sort keys, where a nil key is empty and sorts
+// before any other value.
+func compareKeys(lhs, rhs any) (int, error) {
+	switch {
+	case lhs == nil && rhs == nil:
+		return 0, nil
+	case lhs == nil:
+		return -1, nil
+	case rhs == nil:
+		return 1, nil
+	}
+	return system.Compare(lhs, rhs)
+}
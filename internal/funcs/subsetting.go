@@ -0,0 +1,25 @@
+package funcs
+
+import (
+	"context"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+)
+
+// first implements the FHIRPath first() function, which returns the first
+// item of the input, or an empty collection if the input is empty.
+//
+// See: https://hl7.org/fhirpath/N1/#first-collection
+func first(_ context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	if input.IsEmpty() {
+		return collection.Empty, nil
+	}
+	return input[:1], nil
+}
+
+// itemOfInput infers the result of a function as being a single item of the
+// type of the items of its input.
+func itemOfInput(input types.Type, _ []types.Type) types.Type {
+	return input.Item()
+}
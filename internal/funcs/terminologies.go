@@ -0,0 +1,260 @@
+package funcs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/parameters"
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/system"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
+)
+
+// Terminologies is the table of the methods of the FHIR '%terminologies'
+// object, which are invoked as '%terminologies.expand(...)'. The arguments of
+// each method are evaluated against the input of the invocation, and the final
+// argument of each is an optional set of parameters in URL query syntax -- e.g.
+// 'count=10&offset=20'.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#txapi
+var Terminologies = Table{
+	"expand": {
+		Func: txExpand, MinArgs: 1, MaxArgs: 2,
+		Result:    returns(types.Type{Name: "FHIR.ValueSet"}),
+		Signature: "expand(valueSet, params) : ValueSet",
+		Doc:       "Returns the expansion of the value set.",
+	},
+	"lookup": {
+		Func: txLookup, MinArgs: 1, MaxArgs: 2,
+		Result:    returns(parametersType),
+		Signature: "lookup(coded, params) : Parameters",
+		Doc:       "Returns the details of the coded value.",
+	},
+	"validateVS": {
+		Func: txValidateVS, MinArgs: 2, MaxArgs: 3,
+		Result:    returns(parametersType),
+		Signature: "validateVS(valueSet, coded, params) : Parameters",
+		Doc:       "Validates the coded value against the value set.",
+	},
+	"validateCS": {
+		Func: txValidateCS, MinArgs: 2, MaxArgs: 3,
+		Result:    returns(parametersType),
+		Signature: "validateCS(codeSystem, coded, params) : Parameters",
+		Doc:       "Validates the coded value against the code system.",
+	},
+	"subsumes": {
+		Func: txSubsumes, MinArgs: 3, MaxArgs: 4,
+		Result:    returns(types.Type{Name: "FHIR.code"}),
+		Signature: "subsumes(system, coded1, coded2, params) : code",
+		Doc:       "Returns the subsumption relationship between the coded values of the code system.",
+	},
+	"translate": {
+		Func: txTranslate, MinArgs: 2, MaxArgs: 3,
+		Result:    returns(parametersType),
+		Signature: "translate(conceptMap, coded, params) : Parameters",
+		Doc:       "Returns the translations of the coded value by the concept map.",
+	},
+}
+
+// parametersType is the type of the Parameters resource that most methods of
+// '%terminologies' return.
+var parametersType = types.Type{Name: "FHIR.Parameters"}
+
+// txExpand implements '%terminologies.expand(valueSet, params)', which returns
+// the expansion of the value set as a ValueSet resource.
+func txExpand(ctx context.Context, _ collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	valueSet, ok, err := canonicalArg(args, 0)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	params, err := paramsArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	result, err := terminologyOf(ctx).Expand(ctx, valueSet, params)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Of(result), nil
+}
+
+// txLookup implements '%terminologies.lookup(coded, params)', which returns the
+// details of the coded value as a Parameters resource.
+func txLookup(ctx context.Context, _ collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	coding, ok, err := codingArg(args, 0)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	params, err := paramsArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	result, err := terminologyOf(ctx).Lookup(ctx, coding, params)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Of(result), nil
+}
+
+// txValidateVS implements '%terminologies.validateVS(valueSet, coded, params)',
+// which validates the coded value against the value set, and returns the
+// outcome as a Parameters resource. A CodeableConcept is valid if any of its
+// codings are valid.
+func txValidateVS(ctx context.Context, _ collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	return validate(ctx, args, terminologyOf(ctx).ValidateVS)
+}
+
+// txValidateCS implements '%terminologies.validateCS(codeSystem, coded,
+// params)', which validates the coded value against the code system, and
+// returns the outcome as a Parameters resource. A CodeableConcept is valid if
+// any of its codings are valid.
+func txValidateCS(ctx context.Context, _ collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	return validate(ctx, args, terminologyOf(ctx).ValidateCS)
+}
+
+type validateFunc func(ctx context.Context, canonical string, coding terminology.Coding, params url.Values) (*parameters.Parameters, error)
+
+// validate validates each coding of the coded argument, and returns the first
+// outcome whose result is true, or otherwise the last outcome.
+func validate(ctx context.Context, args []collection.Collection, fn validateFunc) (collection.Collection, error) {
+	canonical, ok, err := canonicalArg(args, 0)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	arg, ok, err := singleton(args[1])
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	codings := codingsOf(arg)
+	if len(codings) == 0 {
+		return nil, fmt.Errorf("argument 2: expected code, Coding, or CodeableConcept, got %T", arg)
+	}
+	params, err := paramsArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *parameters.Parameters
+	for _, coding := range codings {
+		result, err = fn(ctx, canonical, coding, params)
+		if err != nil {
+			return nil, err
+		}
+		if isValid(result) {
+			break
+		}
+	}
+	return collection.Of(result), nil
+}
+
+// isValid returns whether the 'result' parameter of a validation is true.
+func isValid(result *parameters.Parameters) bool {
+	for _, param := range result.GetParameter() {
+		if param.GetName().GetValue() == "result" {
+			value, _ := param.GetValue().(*fhir.Boolean)
+			return value.GetValue()
+		}
+	}
+	return false
+}
+
+// txSubsumes implements '%terminologies.subsumes(system, coded1, coded2,
+// params)', which returns the code of the subsumption relationship between
+// the coded values of the code system. The parameters are accepted, but are
+// not passed to the terminology.
+func txSubsumes(ctx context.Context, _ collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	system, ok, err := canonicalArg(args, 0)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	codingA, ok, err := codingArg(args, 1)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	codingB, ok, err := codingArg(args, 2)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	outcome, err := terminologyOf(ctx).Subsumes(ctx, system, codingA.Code, codingB.Code)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Of(&fhir.Code{Value: string(outcome)}), nil
+}
+
+// txTranslate implements '%terminologies.translate(conceptMap, coded, params)',
+// which returns the translations of the coded value by the concept map as a
+// Parameters resource.
+func txTranslate(ctx context.Context, _ collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	conceptMap, ok, err := canonicalArg(args, 0)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	coding, ok, err := codingArg(args, 1)
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	params, err := paramsArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	result, err := terminologyOf(ctx).Translate(ctx, conceptMap, coding, params)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Of(result), nil
+}
+
+// canonicalArg returns the canonical url of the argument, which may be a
+// String or a resource with a 'url' element.
+func canonicalArg(args []collection.Collection, i int) (string, bool, error) {
+	arg, ok, err := singleton(args[i])
+	if err != nil || !ok {
+		return "", false, err
+	}
+	if url, ok := arg.(system.String); ok {
+		return string(url), true, nil
+	}
+	if url := stringChild(arg, "url"); url != "" {
+		return url, true, nil
+	}
+	return "", false, fmt.Errorf("argument %d: expected String or canonical resource, got %T", i+1, arg)
+}
+
+// codingArg returns the coding of the argument, which may be a code or a
+// Coding.
+func codingArg(args []collection.Collection, i int) (terminology.Coding, bool, error) {
+	arg, ok, err := singleton(args[i])
+	if err != nil || !ok {
+		return terminology.Coding{}, false, err
+	}
+	if codings := codingsOf(arg); len(codings) == 1 && model.TypeName(arg) != "CodeableConcept" {
+		return codings[0], true, nil
+	}
+	return terminology.Coding{}, false, fmt.Errorf("argument %d: expected code or Coding, got %T", i+1, arg)
+}
+
+// paramsArg returns the parameters of the optional argument, which is a String
+// in URL query syntax.
+func paramsArg(args []collection.Collection, i int) (url.Values, error) {
+	if i >= len(args) {
+		return url.Values{}, nil
+	}
+	arg, ok, err := singleton(args[i])
+	if err != nil || !ok {
+		return url.Values{}, err
+	}
+	str, isString := arg.(system.String)
+	if !isString {
+		return nil, fmt.Errorf("argument %d: expected String, got %T", i+1, arg)
+	}
+	params, err := url.ParseQuery(string(str))
+	if err != nil {
+		return nil, fmt.Errorf("argument %d: %w", i+1, err)
+	}
+	return params, nil
+}
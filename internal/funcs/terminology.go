@@ -0,0 +1,146 @@
+package funcs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/evalcontext"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/system"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
+)
+
+// terminologyOf returns the terminology configured for the evaluation.
+func terminologyOf(ctx context.Context) terminology.Terminology {
+	if cfg := evalcontext.From(ctx); cfg.Terminology != nil {
+		return cfg.Terminology
+	}
+	return terminology.BaseTerminology{}
+}
+
+// codingsOf returns the codings that an item represents. A Coding represents
+// itself, a CodeableConcept represents each of its codings, and a code or
+// String represents a code without a system.
+func codingsOf(item any) []terminology.Coding {
+	switch model.TypeName(item) {
+	case "Coding":
+		return []terminology.Coding{{
+			System:  stringChild(item, "system"),
+			Version: stringChild(item, "version"),
+			Code:    stringChild(item, "code"),
+			Display: stringChild(item, "display"),
+		}}
+	case "CodeableConcept":
+		var result []terminology.Coding
+		for _, coding := range model.Children(item, "coding") {
+			result = append(result, codingsOf(coding)...)
+		}
+		return result
+	}
+	if code, ok := system.Normalize(item).(system.String); ok {
+		return []terminology.Coding{{Code: string(code)}}
+	}
+	return nil
+}
+
+// stringChild returns the value of a String child element of the item, or an
+// empty string if it is absent.
+func stringChild(item any, name string) string {
+	for _, child := range model.Children(item, name) {
+		if value, ok := system.Normalize(child).(system.String); ok {
+			return string(value)
+		}
+	}
+	return ""
+}
+
+// memberOf implements the FHIR memberOf(valueset) function, which returns
+// whether the input code, Coding, or CodeableConcept is a member of the value
+// set identified by the url. A CodeableConcept is a member if any of its
+// codings is a member. If the input is empty, the result is empty.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func memberOf(ctx context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	if input.IsEmpty() {
+		return collection.Empty, nil
+	}
+	if !input.IsSingleton() {
+		return nil, fmt.Errorf("input: %w", collection.ErrNotSingleton)
+	}
+	arg, ok, err := singleton(args[0])
+	if err != nil || !ok {
+		return collection.Empty, err
+	}
+	url, isString := arg.(system.String)
+	if !isString {
+		return nil, fmt.Errorf("argument 1: expected String, got %T", arg)
+	}
+
+	codings := codingsOf(input[0])
+	if len(codings) == 0 {
+		return collection.Empty, nil
+	}
+	ts := terminologyOf(ctx)
+	for _, coding := range codings {
+		ok, err := ts.MemberOf(ctx, coding, string(url))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return collection.True, nil
+		}
+	}
+	return collection.False, nil
+}
+
+// subsumes implements the FHIR subsumes(code) function, which returns whether
+// the input Coding or CodeableConcept is equivalent to, or subsumes, the Coding
+// or CodeableConcept argument. Codings are only compared to codings of the same
+// code system. If the input or argument is empty, the result is empty.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func subsumes(ctx context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	return subsumption(ctx, input, args[0], terminology.Subsumes)
+}
+
+// subsumedBy implements the FHIR subsumedBy(code) function, which returns
+// whether the input Coding or CodeableConcept is equivalent to, or subsumed by,
+// the Coding or CodeableConcept argument. If the input or argument is empty,
+// the result is empty.
+//
+// See: https://hl7.org/fhir/R4/fhirpath.html#functions
+func subsumedBy(ctx context.Context, input collection.Collection, args ...collection.Collection) (collection.Collection, error) {
+	return subsumption(ctx, input, args[0], terminology.SubsumedBy)
+}
+
+// subsumption returns whether any coding of the input has either an equivalent
+// relationship or the wanted relationship to any coding of the argument.
+func subsumption(ctx context.Context, input, arg collection.Collection, want terminology.Outcome) (collection.Collection, error) {
+	if input.IsEmpty() || arg.IsEmpty() {
+		return collection.Empty, nil
+	}
+	if !input.IsSingleton() {
+		return nil, fmt.Errorf("input: %w", collection.ErrNotSingleton)
+	}
+	if !arg.IsSingleton() {
+		return nil, fmt.Errorf("argument 1: %w", collection.ErrNotSingleton)
+	}
+
+	ts := terminologyOf(ctx)
+	for _, a := range codingsOf(input[0]) {
+		for _, b := range codingsOf(arg[0]) {
+			if a.System == "" || a.System != b.System {
+				continue
+			}
+			outcome, err := ts.Subsumes(ctx, a.System, a.Code, b.Code)
+			if err != nil {
+				return nil, err
+			}
+			if outcome == terminology.Equivalent || outcome == want {
+				return collection.True, nil
+			}
+		}
+	}
+	return collection.False, nil
+}
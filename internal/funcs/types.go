@@ -0,0 +1,95 @@
+package funcs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/friendly-fhir/go-fhirpath/collection"
+	"github.com/friendly-fhir/go-fhirpath/internal/expr"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+)
+
+// errNotTypeSpecifier is an error raised when the argument of a function that
+// expects a type is not a type specifier.
+var errNotTypeSpecifier = errors.New("argument 1: expected a type specifier")
+
+// ofType implements the FHIRPath ofType(type) function, which returns the
+// items of the input that are of the specified type. A choice element holds a
+// value of its concrete type, so 'Observation.value.ofType(Quantity)' selects
+// the value only if it is a Quantity.
+//
+// See: https://hl7.org/fhirpath/N1/#oftypetype-type-specifier-collection
+func ofType(_ context.Context, input collection.Collection, args ...expr.Expression) (collection.Collection, error) {
+	specifier, ok := expr.TypeSpecifier(args[0])
+	if !ok {
+		return nil, errNotTypeSpecifier
+	}
+	var result collection.Collection
+	for _, item := range input {
+		if model.IsType(item, specifier) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// ofTypeResult infers the result of ofType(type) as the items of the input
+// that are of the specified type.
+func ofTypeResult(input types.Type, args []types.Type) types.Type {
+	return args[0].WithList(input.List)
+}
+
+// is implements the FHIRPath is(type) function, the function form of the 'is'
+// operator, which returns whether the single item of the input is of the
+// specified type.
+//
+// See: https://hl7.org/fhirpath/N1/#istype-type-specifier
+func is(_ context.Context, input collection.Collection, args ...expr.Expression) (collection.Collection, error) {
+	specifier, ok := expr.TypeSpecifier(args[0])
+	if !ok {
+		return nil, errNotTypeSpecifier
+	}
+	return expr.Is(input, specifier)
+}
+
+// as implements the FHIRPath as(type) function, the function form of the 'as'
+// operator, which returns the single item of the input if it is of the
+// specified type.
+//
+// See: https://hl7.org/fhirpath/N1/#astype-type-specifier
+func as(_ context.Context, input collection.Collection, args ...expr.Expression) (collection.Collection, error) {
+	specifier, ok := expr.TypeSpecifier(args[0])
+	if !ok {
+		return nil, errNotTypeSpecifier
+	}
+	return expr.As(input, specifier)
+}
+
+// asResult infers the result of as(type) as a single item of the specified
+// type.
+func asResult(_ types.Type, args []types.Type) types.Type {
+	return args[0].Item()
+}
+
+// typeOf implements the FHIRPath type() function, which returns the reflected
+// type information of each item of the input: a ClassInfo for FHIR types, and
+// a SimpleTypeInfo for System types.
+//
+// See: https://hl7.org/fhirpath/N1/#reflection
+func typeOf(_ context.Context, input collection.Collection, _ ...collection.Collection) (collection.Collection, error) {
+	var result collection.Collection
+	for _, item := range input {
+		if info := model.InfoOf(item); info != nil {
+			result = append(result, info)
+		}
+	}
+	return result, nil
+}
+
+// typeOfResult infers the result of type() as one reflected type for each
+// item of the input. The reflection types are not FHIR or System types, so
+// their type is unknown.
+func typeOfResult(input types.Type, _ []types.Type) types.Type {
+	return types.Unknown.WithList(input.List)
+}
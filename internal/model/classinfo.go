@@ -0,0 +1,334 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhirpath/internal/resources"
+	"github.com/friendly-fhir/go-fhirpath/namespace"
+	fpreflect "github.com/friendly-fhir/go-fhirpath/reflect"
+)
+
+// datatypes are the FHIR R4 data types, from which every other data type is
+// reachable through its elements. Resources are taken from the resource
+// registry.
+var datatypes = []any{
+	&fhir.Address{}, &fhir.Age{}, &fhir.Annotation{}, &fhir.Attachment{},
+	&fhir.Base64Binary{}, &fhir.Boolean{}, &fhir.Canonical{}, &fhir.Code{},
+	&fhir.CodeableConcept{}, &fhir.Coding{}, &fhir.ContactDetail{},
+	&fhir.ContactPoint{}, &fhir.Contributor{}, &fhir.Count{},
+	&fhir.DataRequirement{}, &fhir.Date{}, &fhir.DateTime{}, &fhir.Decimal{},
+	&fhir.Distance{}, &fhir.Dosage{}, &fhir.Duration{},
+	&fhir.ElementDefinition{}, &fhir.Expression{}, &fhir.Extension{},
+	&fhir.HumanName{}, &fhir.ID{}, &fhir.Identifier{}, &fhir.Instant{},
+	&fhir.Integer{}, &fhir.Markdown{}, &fhir.MarketingStatus{}, &fhir.Meta{},
+	&fhir.Money{}, &fhir.Narrative{}, &fhir.OID{}, &fhir.ParameterDefinition{},
+	&fhir.Period{}, &fhir.Population{}, &fhir.PositiveInt{},
+	&fhir.ProdCharacteristic{}, &fhir.ProductShelfLife{}, &fhir.Quantity{},
+	&fhir.Range{}, &fhir.Ratio{}, &fhir.Reference{}, &fhir.RelatedArtifact{},
+	&fhir.SampledData{}, &fhir.Signature{}, &fhir.String{},
+	&fhir.SubstanceAmount{}, &fhir.Time{}, &fhir.Timing{},
+	&fhir.TriggerDefinition{}, &fhir.UnsignedInt{}, &fhir.URI{}, &fhir.URL{},
+	&fhir.UsageContext{}, &fhir.UUID{}, &fhir.XHTML{},
+}
+
+var (
+	resourceType        = reflect.TypeOf((*fhir.Resource)(nil)).Elem()
+	domainResourceType  = reflect.TypeOf((*fhir.DomainResource)(nil)).Elem()
+	backboneElementType = reflect.TypeOf((*fhir.BackboneElement)(nil)).Elem()
+)
+
+// abstract are the class information of the abstract FHIR types, which are
+// represented by interfaces and so do not define any fields of their own.
+var abstract = map[reflect.Type]*fpreflect.ClassInfo{
+	elementType: {
+		Namespace: "FHIR",
+		Name:      "Element",
+		Element: []fpreflect.ClassInfoElement{
+			{Name: "extension", Type: "List<FHIR.Extension>"},
+			{Name: "id", Type: "System.String"},
+		},
+	},
+	backboneElementType: {
+		Namespace: "FHIR",
+		Name:      "BackboneElement",
+		BaseType:  "FHIR.Element",
+		Element: []fpreflect.ClassInfoElement{
+			{Name: "modifierExtension", Type: "List<FHIR.Extension>"},
+		},
+	},
+	resourceType: {
+		Namespace: "FHIR",
+		Name:      "Resource",
+		Element: []fpreflect.ClassInfoElement{
+			{Name: "id", Type: "System.String"},
+			{Name: "implicitRules", Type: "FHIR.uri"},
+			{Name: "language", Type: "FHIR.code"},
+			{Name: "meta", Type: "FHIR.Meta"},
+		},
+	},
+	domainResourceType: {
+		Namespace: "FHIR",
+		Name:      "DomainResource",
+		BaseType:  "FHIR.Resource",
+		Element: []fpreflect.ClassInfoElement{
+			{Name: "contained", Type: "List<FHIR.Resource>"},
+			{Name: "extension", Type: "List<FHIR.Extension>"},
+			{Name: "modifierExtension", Type: "List<FHIR.Extension>"},
+			{Name: "text", Type: "FHIR.Narrative"},
+		},
+	},
+}
+
+// primitiveValues are the System types of the values of the FHIR primitive
+// types that do not hold a System.String.
+var primitiveValues = map[string]fpreflect.TypeSpecifier{
+	"boolean":     "System.Boolean",
+	"integer":     "System.Integer",
+	"positiveInt": "System.Integer",
+	"unsignedInt": "System.Integer",
+	"decimal":     "System.Decimal",
+	"date":        "System.Date",
+	"dateTime":    "System.DateTime",
+	"instant":     "System.DateTime",
+	"time":        "System.Time",
+}
+
+// registry is the index of every FHIR R4 type, by name.
+type registry struct {
+	// types are the Go types, indexed by FHIR name.
+	types map[string]reflect.Type
+
+	// goTypes are the Go types, indexed by Go name.
+	goTypes map[string]reflect.Type
+
+	// infos are the generated class information, indexed by Go type.
+	infos map[reflect.Type]*fpreflect.ClassInfo
+
+	// named are the FHIR names of the types that FHIR itself names: the
+	// abstract types, data types, and resources. The other types are the
+	// backbone elements of resources and data types, which the model names
+	// after their path -- e.g. 'AccountCoverage' for 'Account.coverage'.
+	named map[string]bool
+}
+
+var (
+	registryOnce sync.Once
+	r4           *registry
+)
+
+// types returns the registry of the FHIR R4 types, building it on first use.
+func types() *registry {
+	registryOnce.Do(func() {
+		r4 = &registry{
+			types:   map[string]reflect.Type{},
+			goTypes: map[string]reflect.Type{},
+			infos:   map[reflect.Type]*fpreflect.ClassInfo{},
+			named:   map[string]bool{},
+		}
+		for t, info := range abstract {
+			r4.types[info.Name] = t
+			r4.goTypes[t.Name()] = t
+			r4.infos[t] = info
+			r4.named[info.Name] = true
+		}
+		for _, v := range datatypes {
+			r4.add(reflect.TypeOf(v))
+			r4.named[string(namespace.R4.Name(reflect.TypeOf(v)))] = true
+		}
+		for _, name := range resources.Names() {
+			resource, _ := resources.New(name)
+			r4.add(reflect.TypeOf(resource))
+			r4.named[name] = true
+		}
+		for _, t := range r4.types {
+			r4.classInfo(t)
+		}
+	})
+	return r4
+}
+
+// add adds the struct type t, and every struct type reachable through its
+// elements, to the registry.
+func (r *registry) add(t reflect.Type) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	name := string(namespace.R4.Name(t))
+	if _, ok := r.types[name]; ok {
+		return
+	}
+	r.types[name] = t
+	r.goTypes[t.Name()] = t
+	for _, field := range Fields(t) {
+		r.add(field.Type)
+	}
+}
+
+// classInfo generates the class information of the struct type t. Only the
+// elements that t defines itself are included; the elements inherited from its
+// base types are described by the class information of the base types.
+func (r *registry) classInfo(t reflect.Type) *fpreflect.ClassInfo {
+	if info, ok := r.infos[t]; ok {
+		return info
+	}
+	name := string(namespace.R4.Name(t))
+	info := &fpreflect.ClassInfo{
+		Namespace: namespace.R4.String(),
+		Name:      name,
+	}
+	r.infos[t] = info
+
+	inherited := map[string]bool{}
+	if base, ok := r.baseOf(t); ok {
+		info.BaseType = namespace.R4.QualifiedName(base)
+		for base := r.classInfo(base); base != nil; base = r.infoOf(base.BaseType) {
+			for _, element := range base.Element {
+				inherited[element.Name] = true
+			}
+		}
+	}
+	for _, field := range Fields(t) {
+		if inherited[field.Name] {
+			continue
+		}
+		info.Element = append(info.Element, fpreflect.ClassInfoElement{
+			Name: field.Name,
+			Type: elementTypeOf(name, &field),
+		})
+	}
+	return info
+}
+
+// infoOf returns the class information of the type named by the qualified
+// specifier, or nil if it is not a FHIR type.
+func (r *registry) infoOf(specifier fpreflect.TypeSpecifier) *fpreflect.ClassInfo {
+	name, ok := strings.CutPrefix(string(specifier), namespace.R4.String()+".")
+	if !ok {
+		return nil
+	}
+	t, ok := r.types[name]
+	if !ok {
+		return nil
+	}
+	return r.classInfo(t)
+}
+
+// baseOf returns the base type of the struct type t. The go-fhir model embeds
+// a marker struct for every profile of a type, from the most specific to the
+// least -- and sometimes including the type itself -- so the base type is the
+// first profile that names another type.
+func (r *registry) baseOf(t reflect.Type) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.Anonymous {
+			continue
+		}
+		name, ok := strings.CutPrefix(field.Type.Name(), "Base")
+		if !ok || name == t.Name() {
+			continue
+		}
+		if base, ok := r.goTypes[name]; ok {
+			return base, true
+		}
+	}
+	return nil, false
+}
+
+// elementTypeOf returns the type specifier of the field of the named type.
+// Fields that may hold multiple values are specified as a List of their
+// element type.
+func elementTypeOf(owner string, field *Field) fpreflect.TypeSpecifier {
+	t := field.Type
+	if field.IsList() {
+		t = t.Elem()
+	}
+	var specifier fpreflect.TypeSpecifier
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		specifier = namespace.R4.QualifiedName(t)
+	case reflect.Bool:
+		specifier = "System.Boolean"
+	case reflect.Int32:
+		specifier = "System.Integer"
+	case reflect.Int64:
+		specifier = "System.Long"
+	case reflect.Float64:
+		specifier = "System.Decimal"
+	default:
+		specifier = "System.String"
+	}
+	if value, ok := primitiveValues[owner]; ok && field.Name == "value" {
+		specifier = value
+	}
+	if field.IsList() {
+		return "List<" + specifier + ">"
+	}
+	return specifier
+}
+
+// TypeOf returns the Go type of the FHIR R4 type with the specified name, such
+// as "Patient" or "dateTime". Abstract types, such as "Element", are returned
+// as the interface that their implementations satisfy. If no FHIR type has the
+// name, ok is false.
+func TypeOf(name string) (t reflect.Type, ok bool) {
+	t, ok = types().types[name]
+	return t, ok
+}
+
+// TypeNames returns the names of every FHIR R4 type that FHIR names, including
+// the abstract types, in sorted order. The types of backbone elements, such as
+// 'AccountCoverage', are omitted, since FHIR does not name them.
+func TypeNames() []string {
+	names := make([]string, 0, len(types().named))
+	for name := range types().named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ClassInfo returns the reflected class information of the FHIR R4 type with
+// the specified name, which may be qualified with the "FHIR" namespace. The
+// result is shared, and must not be modified. If no FHIR type has the name, ok
+// is false.
+func ClassInfo(name string) (info *fpreflect.ClassInfo, ok bool) {
+	name = strings.TrimPrefix(name, namespace.R4.String()+".")
+	t, ok := TypeOf(name)
+	if !ok {
+		return nil, false
+	}
+	return types().infos[t], true
+}
+
+// InfoOf returns the reflected type information of v, as returned by the
+// FHIRPath type() function. FHIR types are described by their class
+// information, and System types by their simple type information. If v is not
+// a FHIR or System type, this returns nil.
+func InfoOf(v any) fpreflect.Info {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+	switch namespace.Select(t, namespace.R4, namespace.System) {
+	case namespace.R4:
+		info, ok := ClassInfo(string(namespace.R4.Name(t)))
+		if !ok {
+			return nil
+		}
+		return info
+	case namespace.System:
+		return &fpreflect.SimpleTypeInfo{
+			Namespace: namespace.System.String(),
+			Name:      string(namespace.System.Name(t)),
+			BaseType:  "System.Any",
+		}
+	}
+	return nil
+}
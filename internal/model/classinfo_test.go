@@ -0,0 +1,132 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClassInfo(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  *reflect.ClassInfo
+	}{
+		{
+			name:  "Primitive type",
+			input: "string",
+			want: &reflect.ClassInfo{
+				Namespace: "FHIR",
+				Name:      "string",
+				BaseType:  "FHIR.Element",
+				Element: []reflect.ClassInfoElement{
+					{Name: "value", Type: "System.String"},
+				},
+			},
+		}, {
+			name:  "Primitive type of other System type",
+			input: "FHIR.dateTime",
+			want: &reflect.ClassInfo{
+				Namespace: "FHIR",
+				Name:      "dateTime",
+				BaseType:  "FHIR.Element",
+				Element: []reflect.ClassInfoElement{
+					{Name: "value", Type: "System.DateTime"},
+				},
+			},
+		}, {
+			name:  "Specialized primitive type",
+			input: "code",
+			want: &reflect.ClassInfo{
+				Namespace: "FHIR",
+				Name:      "code",
+				BaseType:  "FHIR.string",
+			},
+		}, {
+			name:  "Complex type",
+			input: "Extension",
+			want: &reflect.ClassInfo{
+				Namespace: "FHIR",
+				Name:      "Extension",
+				BaseType:  "FHIR.Element",
+				Element: []reflect.ClassInfoElement{
+					{Name: "url", Type: "System.String"},
+					{Name: "value", Type: "FHIR.Element"},
+				},
+			},
+		}, {
+			name:  "Backbone element",
+			input: "PatientLink",
+			want: &reflect.ClassInfo{
+				Namespace: "FHIR",
+				Name:      "PatientLink",
+				BaseType:  "FHIR.BackboneElement",
+				Element: []reflect.ClassInfoElement{
+					{Name: "other", Type: "FHIR.Reference"},
+					{Name: "type", Type: "FHIR.code"},
+				},
+			},
+		}, {
+			name:  "Resource without DomainResource",
+			input: "Binary",
+			want: &reflect.ClassInfo{
+				Namespace: "FHIR",
+				Name:      "Binary",
+				BaseType:  "FHIR.Resource",
+				Element: []reflect.ClassInfoElement{
+					{Name: "contentType", Type: "FHIR.code"},
+					{Name: "data", Type: "FHIR.base64Binary"},
+					{Name: "securityContext", Type: "FHIR.Reference"},
+				},
+			},
+		}, {
+			name:  "Abstract type",
+			input: "DomainResource",
+			want: &reflect.ClassInfo{
+				Namespace: "FHIR",
+				Name:      "DomainResource",
+				BaseType:  "FHIR.Resource",
+				Element: []reflect.ClassInfoElement{
+					{Name: "contained", Type: "List<FHIR.Resource>"},
+					{Name: "extension", Type: "List<FHIR.Extension>"},
+					{Name: "modifierExtension", Type: "List<FHIR.Extension>"},
+					{Name: "text", Type: "FHIR.Narrative"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := model.ClassInfo(tc.input)
+			if !ok {
+				t.Fatalf("ClassInfo(%q) ok = false; want true", tc.input)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("ClassInfo(%q) mismatch (-got +want):\n%s", tc.input, diff)
+			}
+		})
+	}
+}
+
+func TestClassInfo_UnknownType_ReturnsFalse(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"Unknown type", "Unknown"},
+		{"System type", "System.String"},
+		{"PascalCase primitive", "String"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := model.ClassInfo(tc.input); ok {
+				t.Errorf("ClassInfo(%q) ok = true; want false", tc.input)
+			}
+		})
+	}
+}
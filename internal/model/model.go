@@ -0,0 +1,299 @@
+/*
+Package model provides reflection-based access to the elements of FHIR model
+types, as defined by the `fhirpath` struct tags on the generated go-fhir
+structures.
+*/
+package model
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhirpath/namespace"
+	fpreflect "github.com/friendly-fhir/go-fhirpath/reflect"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+// Field is a single FHIR element that is defined on a Go struct type.
+type Field struct {
+	// Name is the FHIR element name of the field, as it appears in FHIRPath.
+	Name string
+
+	// Index is the index of the field within the Go struct.
+	Index int
+
+	// Type is the Go type of the field.
+	Type reflect.Type
+}
+
+// IsList returns whether this field may contain multiple values.
+func (f *Field) IsList() bool {
+	return f.Type.Kind() == reflect.Slice
+}
+
+// elementType is the type of the FHIR Element interface, which is the declared
+// type of every choice element.
+var elementType = reflect.TypeOf((*fhir.Element)(nil)).Elem()
+
+// IsChoice returns whether this field is a choice ([x]) element, which may
+// hold a value of any one of several types -- e.g. 'Observation.value[x]'.
+func (f *Field) IsChoice() bool {
+	return f.Type == elementType
+}
+
+var fieldCache sync.Map // map[reflect.Type][]Field
+
+// Fields returns all FHIR element fields defined on the specified type. If the
+// type is a pointer, the fields of the pointed-to type are returned. If the
+// type is not a struct, this returns nil.
+func Fields(t reflect.Type) []Field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]Field)
+	}
+
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup("fhirpath")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		fields = append(fields, Field{
+			Name:  name,
+			Index: i,
+			Type:  field.Type,
+		})
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// Lookup finds the field with the specified FHIR element name on the type.
+func Lookup(t reflect.Type, name string) (*Field, bool) {
+	fields := Fields(t)
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// Children returns all values of the element named name on v. Lists are
+// flattened, absent values are omitted, and native Go values (such as the
+// string "id" of an element) are converted into their FHIRPath System types.
+//
+// If v is not a FHIR model type or does not define the element, this returns
+// nil.
+func Children(v any, name string) []any {
+	value, ok := structOf(v)
+	if !ok {
+		return nil
+	}
+	field, ok := Lookup(value.Type(), name)
+	if !ok {
+		return nil
+	}
+	return flatten(value.Field(field.Index))
+}
+
+// ChoiceChildren returns the value of a choice element on v, where name is
+// the name of the element qualified by the type of the value -- e.g.
+// "valueQuantity" selects the "value" element only if it holds a Quantity.
+//
+// If v does not define such a choice element, or the element holds a value of
+// a different type, this returns nil.
+func ChoiceChildren(v any, name string) []any {
+	value, ok := structOf(v)
+	if !ok {
+		return nil
+	}
+	for _, field := range Fields(value.Type()) {
+		if !field.IsChoice() || !strings.HasPrefix(name, field.Name) {
+			continue
+		}
+		choice := value.Field(field.Index)
+		if choice.IsNil() {
+			continue
+		}
+		if namespace.R4.ChoiceName(field.Name, choice.Elem().Type()) == name {
+			return flatten(choice)
+		}
+	}
+	return nil
+}
+
+// PrimitiveValue returns the value of v if it is a FHIR primitive element, such
+// as a *fhir.String or *fhir.Boolean, converted into its FHIRPath System type.
+// If v is not a primitive element, ok is false. If the primitive has no value,
+// such as an element with only extensions, value is nil.
+//
+// The R4 model does not distinguish a boolean or numeric primitive without a
+// value from one with a zero value, so a zero value is only treated as absent
+// if the element has extensions.
+func PrimitiveValue(v any) (value any, ok bool) {
+	s, ok := structOf(v)
+	if !ok {
+		return nil, false
+	}
+	field, ok := Lookup(s.Type(), "value")
+	if !ok {
+		return nil, false
+	}
+	switch field.Type.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Struct:
+		return nil, false
+	}
+	fv := s.Field(field.Index)
+	if fv.IsZero() && len(Children(v, "extension")) > 0 {
+		return nil, true
+	}
+	for _, value := range flatten(fv) {
+		return value, true
+	}
+	return nil, true
+}
+
+// structOf dereferences v into the struct value that it refers to.
+func structOf(v any) (reflect.Value, bool) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	return value, value.Kind() == reflect.Struct
+}
+
+// flatten converts a reflected field value into its list of FHIRPath values.
+func flatten(value reflect.Value) []any {
+	switch value.Kind() {
+	case reflect.Slice:
+		var result []any
+		for i := 0; i < value.Len(); i++ {
+			result = append(result, flatten(value.Index(i))...)
+		}
+		return result
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return []any{value.Interface()}
+	case reflect.Struct:
+		return []any{value.Interface()}
+	case reflect.String:
+		if value.String() == "" {
+			return nil
+		}
+		return []any{system.String(value.String())}
+	case reflect.Bool:
+		return []any{system.Boolean(value.Bool())}
+	case reflect.Int32:
+		return []any{system.Integer(value.Int())}
+	case reflect.Int64:
+		return []any{system.Integer64(value.Int())}
+	case reflect.Float64:
+		return []any{system.NewDecimal(value.Float())}
+	}
+	return nil
+}
+
+// TypeName returns the name of the FHIR type of v, as it is named in the FHIR
+// namespace (e.g. "Patient", or "HumanName"). If v is not a FHIR model type,
+// this returns an empty string.
+func TypeName(v any) string {
+	t := reflect.TypeOf(v)
+	if t == nil || !namespace.R4.Contains(t) {
+		return ""
+	}
+	return string(namespace.R4.Name(t))
+}
+
+// IsType returns whether v is of the type named by the specifier, or of a
+// type that derives from it -- so that a Patient is a DomainResource and a
+// Resource, an Age is a Quantity, and every System value is a System.Any. An
+// unqualified specifier matches a type of the same name in either the FHIR or
+// System namespace.
+func IsType(v any, specifier string) bool {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return false
+	}
+	ns := namespace.Select(t, namespace.R4, namespace.System)
+	if ns == nil {
+		return false
+	}
+	qualifier, name, ok := strings.Cut(specifier, ".")
+	if !ok {
+		qualifier, name = "", qualifier
+	}
+	for _, base := range typeHierarchy(ns, t) {
+		baseQualifier, baseName, _ := strings.Cut(string(base), ".")
+		if baseName == name && (qualifier == "" || qualifier == baseQualifier) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeHierarchy returns the qualified names of the type t of the namespace,
+// and of each of the types that it derives from, with t first.
+func typeHierarchy(ns *namespace.Namespace, t reflect.Type) []fpreflect.TypeSpecifier {
+	result := []fpreflect.TypeSpecifier{ns.QualifiedName(t)}
+	if ns == namespace.System {
+		return append(result, "System.Any")
+	}
+	for info, _ := ClassInfo(string(ns.Name(t))); info != nil && info.BaseType != ""; info, _ = ClassInfo(string(info.BaseType)) {
+		result = append(result, info.BaseType)
+	}
+	return result
+}
+
+// Locate finds the node within root, by identity, and returns the resource
+// that most closely contains it and the container of that resource, as FHIR
+// defines '%resource' and '%rootResource'. A resource in the 'contained' of
+// another is contained by it; any other resource within root, such as that of
+// a Bundle entry, is its own root. The node may be root itself.
+//
+// Only nodes that are pointers may be located; if node is not found within
+// root, ok is false.
+func Locate(root, node any) (resource, rootResource any, ok bool) {
+	if t := reflect.TypeOf(node); t == nil || t.Kind() != reflect.Pointer {
+		return nil, nil, false
+	}
+	return locate(root, node, root, root)
+}
+
+// locate finds the node within v, where v is within resource and rootResource.
+func locate(v, node, resource, rootResource any) (any, any, bool) {
+	if v == node {
+		return resource, rootResource, true
+	}
+	for _, field := range Fields(reflect.TypeOf(v)) {
+		for _, child := range Children(v, field.Name) {
+			if reflect.TypeOf(child).Kind() != reflect.Pointer {
+				continue
+			}
+			childResource, childRoot := resource, rootResource
+			if _, ok := child.(fhir.Resource); ok {
+				childResource = child
+				if field.Name != "contained" {
+					childRoot = child
+				}
+			}
+			if r, rr, ok := locate(child, node, childResource, childRoot); ok {
+				return r, rr, true
+			}
+		}
+	}
+	return nil, nil, false
+}
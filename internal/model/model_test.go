@@ -0,0 +1,106 @@
+package model_test
+
+import (
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/bundle"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/observation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestIsType(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     any
+		specifier string
+		want      bool
+	}{
+		{"Same type", &patient.Patient{}, "Patient", true},
+		{"Qualified type", &patient.Patient{}, "FHIR.Patient", true},
+		{"Base type", &patient.Patient{}, "DomainResource", true},
+		{"Base of base type", &patient.Patient{}, "FHIR.Resource", true},
+		{"Other type", &patient.Patient{}, "Observation", false},
+		{"Base type of other namespace", &patient.Patient{}, "System.Resource", false},
+		{"Specialized complex type", &fhir.Age{}, "Quantity", true},
+		{"Specialized primitive type", &fhir.Code{}, "string", true},
+		{"Element", &fhir.Code{}, "Element", true},
+		{"Derived type", &fhir.Quantity{}, "Age", false},
+		{"System type", system.String("a"), "System.String", true},
+		{"System any", system.String("a"), "System.Any", true},
+		{"System type of other namespace", system.String("a"), "FHIR.String", false},
+		{"Nil", nil, "Patient", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := model.IsType(tc.value, tc.specifier)
+
+			if got != tc.want {
+				t.Errorf("IsType(%T, %q) = %v; want %v", tc.value, tc.specifier, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	name := &fhir.HumanName{Family: &fhir.String{Value: "Doe"}}
+	contained := &patient.Patient{ID: "p", Name: []*fhir.HumanName{name}}
+	container := &observation.Observation{Contained: []fhir.Resource{contained}, Status: &fhir.Code{Value: "final"}}
+	entry := &patient.Patient{ID: "e"}
+	root := &bundle.Bundle{Entry: []*bundle.BundleEntry{{Resource: container}, {Resource: entry}}}
+
+	testCases := []struct {
+		name             string
+		node             any
+		wantResource     any
+		wantRootResource any
+	}{
+		{"Root", root, root, root},
+		{"Element of root", root.Entry[0], root, root},
+		{"Resource of entry", container, container, container},
+		{"Element of entry", container.Status, container, container},
+		{"Contained resource", contained, contained, container},
+		{"Element of contained resource", name.Family, contained, container},
+		{"Other resource of entry", entry, entry, entry},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resource, rootResource, ok := model.Locate(root, tc.node)
+			if !ok {
+				t.Fatalf("Locate() ok = false; want true")
+			}
+
+			if resource != tc.wantResource {
+				t.Errorf("Locate() resource = %p; want %p", resource, tc.wantResource)
+			}
+			if rootResource != tc.wantRootResource {
+				t.Errorf("Locate() rootResource = %p; want %p", rootResource, tc.wantRootResource)
+			}
+		})
+	}
+}
+
+func TestLocate_NotFound_ReturnsFalse(t *testing.T) {
+	root := &patient.Patient{Gender: &fhir.Code{Value: "female"}}
+
+	testCases := []struct {
+		name string
+		node any
+	}{
+		{"Other element", &fhir.Code{Value: "female"}},
+		{"System value", system.String("female")},
+		{"Nil", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, ok := model.Locate(root, tc.node); ok {
+				t.Errorf("Locate() ok = true; want false")
+			}
+		})
+	}
+}
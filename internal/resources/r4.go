@@ -0,0 +1,302 @@
+package resources
+
+import (
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/account"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/activitydefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/adverseevent"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/allergyintolerance"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/appointment"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/appointmentresponse"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/auditevent"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/basic"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/binary"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/biologicallyderivedproduct"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/bodystructure"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/bundle"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/capabilitystatement"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/careplan"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/careteam"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/catalogentry"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/chargeitem"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/chargeitemdefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/claim"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/claimresponse"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/clinicalimpression"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/codesystem"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/communication"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/communicationrequest"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/compartmentdefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/composition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/conceptmap"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/condition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/consent"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/contract"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/coverage"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/coverageeligibilityrequest"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/coverageeligibilityresponse"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/detectedissue"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/device"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/devicedefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/devicemetric"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/devicerequest"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/deviceusestatement"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/diagnosticreport"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/documentmanifest"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/documentreference"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/effectevidencesynthesis"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/encounter"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/endpoint"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/enrollmentrequest"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/enrollmentresponse"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/episodeofcare"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/eventdefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/evidence"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/evidencevariable"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/examplescenario"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/explanationofbenefit"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/familymemberhistory"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/flag"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/goal"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/graphdefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/group"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/guidanceresponse"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/healthcareservice"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/imagingstudy"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/immunization"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/immunizationevaluation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/immunizationrecommendation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/implementationguide"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/insuranceplan"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/invoice"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/library"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/linkage"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/list"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/location"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/measure"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/measurereport"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/media"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medication"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicationadministration"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicationdispense"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicationknowledge"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicationrequest"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicationstatement"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproduct"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductauthorization"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductcontraindication"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductindication"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductingredient"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductinteraction"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductmanufactured"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductpackaged"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductpharmaceutical"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/medicinalproductundesirableeffect"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/messagedefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/messageheader"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/molecularsequence"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/namingsystem"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/nutritionorder"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/observation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/observationdefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/operationdefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/operationoutcome"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/organization"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/organizationaffiliation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/parameters"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/paymentnotice"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/paymentreconciliation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/person"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/plandefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/practitioner"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/practitionerrole"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/procedure"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/provenance"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/questionnaire"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/questionnaireresponse"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/relatedperson"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/requestgroup"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/researchdefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/researchelementdefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/researchstudy"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/researchsubject"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/riskassessment"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/riskevidencesynthesis"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/schedule"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/searchparameter"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/servicerequest"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/slot"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/specimen"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/specimendefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/structuredefinition"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/structuremap"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/subscription"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/substance"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/substancenucleicacid"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/substancepolymer"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/substanceprotein"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/substancereferenceinformation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/substancesourcematerial"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/substancespecification"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/supplydelivery"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/supplyrequest"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/task"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/terminologycapabilities"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/testreport"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/testscript"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/valueset"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/verificationresult"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/visionprescription"
+)
+
+// r4 constructs a new, empty resource of each resource type defined in FHIR
+// R4, indexed by resourceType.
+var r4 = map[string]func() fhir.Resource{
+	"Account":                           func() fhir.Resource { return &account.Account{} },
+	"ActivityDefinition":                func() fhir.Resource { return &activitydefinition.ActivityDefinition{} },
+	"AdverseEvent":                      func() fhir.Resource { return &adverseevent.AdverseEvent{} },
+	"AllergyIntolerance":                func() fhir.Resource { return &allergyintolerance.AllergyIntolerance{} },
+	"Appointment":                       func() fhir.Resource { return &appointment.Appointment{} },
+	"AppointmentResponse":               func() fhir.Resource { return &appointmentresponse.AppointmentResponse{} },
+	"AuditEvent":                        func() fhir.Resource { return &auditevent.AuditEvent{} },
+	"Basic":                             func() fhir.Resource { return &basic.Basic{} },
+	"Binary":                            func() fhir.Resource { return &binary.Binary{} },
+	"BiologicallyDerivedProduct":        func() fhir.Resource { return &biologicallyderivedproduct.BiologicallyDerivedProduct{} },
+	"BodyStructure":                     func() fhir.Resource { return &bodystructure.BodyStructure{} },
+	"Bundle":                            func() fhir.Resource { return &bundle.Bundle{} },
+	"CapabilityStatement":               func() fhir.Resource { return &capabilitystatement.CapabilityStatement{} },
+	"CarePlan":                          func() fhir.Resource { return &careplan.CarePlan{} },
+	"CareTeam":                          func() fhir.Resource { return &careteam.CareTeam{} },
+	"CatalogEntry":                      func() fhir.Resource { return &catalogentry.CatalogEntry{} },
+	"ChargeItem":                        func() fhir.Resource { return &chargeitem.ChargeItem{} },
+	"ChargeItemDefinition":              func() fhir.Resource { return &chargeitemdefinition.ChargeItemDefinition{} },
+	"Claim":                             func() fhir.Resource { return &claim.Claim{} },
+	"ClaimResponse":                     func() fhir.Resource { return &claimresponse.ClaimResponse{} },
+	"ClinicalImpression":                func() fhir.Resource { return &clinicalimpression.ClinicalImpression{} },
+	"CodeSystem":                        func() fhir.Resource { return &codesystem.CodeSystem{} },
+	"Communication":                     func() fhir.Resource { return &communication.Communication{} },
+	"CommunicationRequest":              func() fhir.Resource { return &communicationrequest.CommunicationRequest{} },
+	"CompartmentDefinition":             func() fhir.Resource { return &compartmentdefinition.CompartmentDefinition{} },
+	"Composition":                       func() fhir.Resource { return &composition.Composition{} },
+	"ConceptMap":                        func() fhir.Resource { return &conceptmap.ConceptMap{} },
+	"Condition":                         func() fhir.Resource { return &condition.Condition{} },
+	"Consent":                           func() fhir.Resource { return &consent.Consent{} },
+	"Contract":                          func() fhir.Resource { return &contract.Contract{} },
+	"Coverage":                          func() fhir.Resource { return &coverage.Coverage{} },
+	"CoverageEligibilityRequest":        func() fhir.Resource { return &coverageeligibilityrequest.CoverageEligibilityRequest{} },
+	"CoverageEligibilityResponse":       func() fhir.Resource { return &coverageeligibilityresponse.CoverageEligibilityResponse{} },
+	"DetectedIssue":                     func() fhir.Resource { return &detectedissue.DetectedIssue{} },
+	"Device":                            func() fhir.Resource { return &device.Device{} },
+	"DeviceDefinition":                  func() fhir.Resource { return &devicedefinition.DeviceDefinition{} },
+	"DeviceMetric":                      func() fhir.Resource { return &devicemetric.DeviceMetric{} },
+	"DeviceRequest":                     func() fhir.Resource { return &devicerequest.DeviceRequest{} },
+	"DeviceUseStatement":                func() fhir.Resource { return &deviceusestatement.DeviceUseStatement{} },
+	"DiagnosticReport":                  func() fhir.Resource { return &diagnosticreport.DiagnosticReport{} },
+	"DocumentManifest":                  func() fhir.Resource { return &documentmanifest.DocumentManifest{} },
+	"DocumentReference":                 func() fhir.Resource { return &documentreference.DocumentReference{} },
+	"EffectEvidenceSynthesis":           func() fhir.Resource { return &effectevidencesynthesis.EffectEvidenceSynthesis{} },
+	"Encounter":                         func() fhir.Resource { return &encounter.Encounter{} },
+	"Endpoint":                          func() fhir.Resource { return &endpoint.Endpoint{} },
+	"EnrollmentRequest":                 func() fhir.Resource { return &enrollmentrequest.EnrollmentRequest{} },
+	"EnrollmentResponse":                func() fhir.Resource { return &enrollmentresponse.EnrollmentResponse{} },
+	"EpisodeOfCare":                     func() fhir.Resource { return &episodeofcare.EpisodeOfCare{} },
+	"EventDefinition":                   func() fhir.Resource { return &eventdefinition.EventDefinition{} },
+	"Evidence":                          func() fhir.Resource { return &evidence.Evidence{} },
+	"EvidenceVariable":                  func() fhir.Resource { return &evidencevariable.EvidenceVariable{} },
+	"ExampleScenario":                   func() fhir.Resource { return &examplescenario.ExampleScenario{} },
+	"ExplanationOfBenefit":              func() fhir.Resource { return &explanationofbenefit.ExplanationOfBenefit{} },
+	"FamilyMemberHistory":               func() fhir.Resource { return &familymemberhistory.FamilyMemberHistory{} },
+	"Flag":                              func() fhir.Resource { return &flag.Flag{} },
+	"Goal":                              func() fhir.Resource { return &goal.Goal{} },
+	"GraphDefinition":                   func() fhir.Resource { return &graphdefinition.GraphDefinition{} },
+	"Group":                             func() fhir.Resource { return &group.Group{} },
+	"GuidanceResponse":                  func() fhir.Resource { return &guidanceresponse.GuidanceResponse{} },
+	"HealthcareService":                 func() fhir.Resource { return &healthcareservice.HealthcareService{} },
+	"ImagingStudy":                      func() fhir.Resource { return &imagingstudy.ImagingStudy{} },
+	"Immunization":                      func() fhir.Resource { return &immunization.Immunization{} },
+	"ImmunizationEvaluation":            func() fhir.Resource { return &immunizationevaluation.ImmunizationEvaluation{} },
+	"ImmunizationRecommendation":        func() fhir.Resource { return &immunizationrecommendation.ImmunizationRecommendation{} },
+	"ImplementationGuide":               func() fhir.Resource { return &implementationguide.ImplementationGuide{} },
+	"InsurancePlan":                     func() fhir.Resource { return &insuranceplan.InsurancePlan{} },
+	"Invoice":                           func() fhir.Resource { return &invoice.Invoice{} },
+	"Library":                           func() fhir.Resource { return &library.Library{} },
+	"Linkage":                           func() fhir.Resource { return &linkage.Linkage{} },
+	"List":                              func() fhir.Resource { return &list.List{} },
+	"Location":                          func() fhir.Resource { return &location.Location{} },
+	"Measure":                           func() fhir.Resource { return &measure.Measure{} },
+	"MeasureReport":                     func() fhir.Resource { return &measurereport.MeasureReport{} },
+	"Media":                             func() fhir.Resource { return &media.Media{} },
+	"Medication":                        func() fhir.Resource { return &medication.Medication{} },
+	"MedicationAdministration":          func() fhir.Resource { return &medicationadministration.MedicationAdministration{} },
+	"MedicationDispense":                func() fhir.Resource { return &medicationdispense.MedicationDispense{} },
+	"MedicationKnowledge":               func() fhir.Resource { return &medicationknowledge.MedicationKnowledge{} },
+	"MedicationRequest":                 func() fhir.Resource { return &medicationrequest.MedicationRequest{} },
+	"MedicationStatement":               func() fhir.Resource { return &medicationstatement.MedicationStatement{} },
+	"MedicinalProduct":                  func() fhir.Resource { return &medicinalproduct.MedicinalProduct{} },
+	"MedicinalProductAuthorization":     func() fhir.Resource { return &medicinalproductauthorization.MedicinalProductAuthorization{} },
+	"MedicinalProductContraindication":  func() fhir.Resource { return &medicinalproductcontraindication.MedicinalProductContraindication{} },
+	"MedicinalProductIndication":        func() fhir.Resource { return &medicinalproductindication.MedicinalProductIndication{} },
+	"MedicinalProductIngredient":        func() fhir.Resource { return &medicinalproductingredient.MedicinalProductIngredient{} },
+	"MedicinalProductInteraction":       func() fhir.Resource { return &medicinalproductinteraction.MedicinalProductInteraction{} },
+	"MedicinalProductManufactured":      func() fhir.Resource { return &medicinalproductmanufactured.MedicinalProductManufactured{} },
+	"MedicinalProductPackaged":          func() fhir.Resource { return &medicinalproductpackaged.MedicinalProductPackaged{} },
+	"MedicinalProductPharmaceutical":    func() fhir.Resource { return &medicinalproductpharmaceutical.MedicinalProductPharmaceutical{} },
+	"MedicinalProductUndesirableEffect": func() fhir.Resource { return &medicinalproductundesirableeffect.MedicinalProductUndesirableEffect{} },
+	"MessageDefinition":                 func() fhir.Resource { return &messagedefinition.MessageDefinition{} },
+	"MessageHeader":                     func() fhir.Resource { return &messageheader.MessageHeader{} },
+	"MolecularSequence":                 func() fhir.Resource { return &molecularsequence.MolecularSequence{} },
+	"NamingSystem":                      func() fhir.Resource { return &namingsystem.NamingSystem{} },
+	"NutritionOrder":                    func() fhir.Resource { return &nutritionorder.NutritionOrder{} },
+	"Observation":                       func() fhir.Resource { return &observation.Observation{} },
+	"ObservationDefinition":             func() fhir.Resource { return &observationdefinition.ObservationDefinition{} },
+	"OperationDefinition":               func() fhir.Resource { return &operationdefinition.OperationDefinition{} },
+	"OperationOutcome":                  func() fhir.Resource { return &operationoutcome.OperationOutcome{} },
+	"Organization":                      func() fhir.Resource { return &organization.Organization{} },
+	"OrganizationAffiliation":           func() fhir.Resource { return &organizationaffiliation.OrganizationAffiliation{} },
+	"Parameters":                        func() fhir.Resource { return &parameters.Parameters{} },
+	"Patient":                           func() fhir.Resource { return &patient.Patient{} },
+	"PaymentNotice":                     func() fhir.Resource { return &paymentnotice.PaymentNotice{} },
+	"PaymentReconciliation":             func() fhir.Resource { return &paymentreconciliation.PaymentReconciliation{} },
+	"Person":                            func() fhir.Resource { return &person.Person{} },
+	"PlanDefinition":                    func() fhir.Resource { return &plandefinition.PlanDefinition{} },
+	"Practitioner":                      func() fhir.Resource { return &practitioner.Practitioner{} },
+	"PractitionerRole":                  func() fhir.Resource { return &practitionerrole.PractitionerRole{} },
+	"Procedure":                         func() fhir.Resource { return &procedure.Procedure{} },
+	"Provenance":                        func() fhir.Resource { return &provenance.Provenance{} },
+	"Questionnaire":                     func() fhir.Resource { return &questionnaire.Questionnaire{} },
+	"QuestionnaireResponse":             func() fhir.Resource { return &questionnaireresponse.QuestionnaireResponse{} },
+	"RelatedPerson":                     func() fhir.Resource { return &relatedperson.RelatedPerson{} },
+	"RequestGroup":                      func() fhir.Resource { return &requestgroup.RequestGroup{} },
+	"ResearchDefinition":                func() fhir.Resource { return &researchdefinition.ResearchDefinition{} },
+	"ResearchElementDefinition":         func() fhir.Resource { return &researchelementdefinition.ResearchElementDefinition{} },
+	"ResearchStudy":                     func() fhir.Resource { return &researchstudy.ResearchStudy{} },
+	"ResearchSubject":                   func() fhir.Resource { return &researchsubject.ResearchSubject{} },
+	"RiskAssessment":                    func() fhir.Resource { return &riskassessment.RiskAssessment{} },
+	"RiskEvidenceSynthesis":             func() fhir.Resource { return &riskevidencesynthesis.RiskEvidenceSynthesis{} },
+	"Schedule":                          func() fhir.Resource { return &schedule.Schedule{} },
+	"SearchParameter":                   func() fhir.Resource { return &searchparameter.SearchParameter{} },
+	"ServiceRequest":                    func() fhir.Resource { return &servicerequest.ServiceRequest{} },
+	"Slot":                              func() fhir.Resource { return &slot.Slot{} },
+	"Specimen":                          func() fhir.Resource { return &specimen.Specimen{} },
+	"SpecimenDefinition":                func() fhir.Resource { return &specimendefinition.SpecimenDefinition{} },
+	"StructureDefinition":               func() fhir.Resource { return &structuredefinition.StructureDefinition{} },
+	"StructureMap":                      func() fhir.Resource { return &structuremap.StructureMap{} },
+	"Subscription":                      func() fhir.Resource { return &subscription.Subscription{} },
+	"Substance":                         func() fhir.Resource { return &substance.Substance{} },
+	"SubstanceNucleicAcid":              func() fhir.Resource { return &substancenucleicacid.SubstanceNucleicAcid{} },
+	"SubstancePolymer":                  func() fhir.Resource { return &substancepolymer.SubstancePolymer{} },
+	"SubstanceProtein":                  func() fhir.Resource { return &substanceprotein.SubstanceProtein{} },
+	"SubstanceReferenceInformation":     func() fhir.Resource { return &substancereferenceinformation.SubstanceReferenceInformation{} },
+	"SubstanceSourceMaterial":           func() fhir.Resource { return &substancesourcematerial.SubstanceSourceMaterial{} },
+	"SubstanceSpecification":            func() fhir.Resource { return &substancespecification.SubstanceSpecification{} },
+	"SupplyDelivery":                    func() fhir.Resource { return &supplydelivery.SupplyDelivery{} },
+	"SupplyRequest":                     func() fhir.Resource { return &supplyrequest.SupplyRequest{} },
+	"Task":                              func() fhir.Resource { return &task.Task{} },
+	"TerminologyCapabilities":           func() fhir.Resource { return &terminologycapabilities.TerminologyCapabilities{} },
+	"TestReport":                        func() fhir.Resource { return &testreport.TestReport{} },
+	"TestScript":                        func() fhir.Resource { return &testscript.TestScript{} },
+	"ValueSet":                          func() fhir.Resource { return &valueset.ValueSet{} },
+	"VerificationResult":                func() fhir.Resource { return &verificationresult.VerificationResult{} },
+	"VisionPrescription":                func() fhir.Resource { return &visionprescription.VisionPrescription{} },
+}
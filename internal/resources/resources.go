@@ -0,0 +1,69 @@
+/*
+Package resources provides a registry of the FHIR resource types, so that a
+resource may be decoded when its type is only known from the 'resourceType' of
+its JSON representation.
+*/
+package resources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+)
+
+// ErrUnknownResourceType is an error raised when decoding a resource whose
+// resourceType is not a FHIR resource type.
+var ErrUnknownResourceType = errors.New("unknown resource type")
+
+// New returns a new, empty resource of the named resource type. If the name is
+// not a FHIR R4 resource type, ok is false.
+func New(resourceType string) (resource fhir.Resource, ok bool) {
+	fn, ok := r4[resourceType]
+	if !ok {
+		return nil, false
+	}
+	return fn(), true
+}
+
+// Names returns the names of all FHIR R4 resource types, in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(r4))
+	for name := range r4 {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Header is the identity of a resource, as it appears in its JSON
+// representation.
+type Header struct {
+	ResourceType string `json:"resourceType"`
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	Version      string `json:"version"`
+	Meta         struct {
+		VersionID string `json:"versionId"`
+	} `json:"meta"`
+}
+
+// Decode decodes the JSON representation of a resource into the resource type
+// named by its 'resourceType'. If the type is not a FHIR R4 resource type, an
+// error wrapping ErrUnknownResourceType is returned.
+func Decode(data []byte) (fhir.Resource, error) {
+	var header Header
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	resource, ok := New(header.ResourceType)
+	if !ok {
+		return nil, fmt.Errorf("%w '%v'", ErrUnknownResourceType, header.ResourceType)
+	}
+	if err := json.Unmarshal(data, resource); err != nil {
+		return nil, fmt.Errorf("%v: %w", header.ResourceType, err)
+	}
+	return resource, nil
+}
@@ -0,0 +1,369 @@
+/*
+Package types provides the static types of FHIRPath expressions, which are
+inferred at compile time so that an expression may be checked against the type
+of its input.
+
+Types are named by their qualified type specifiers, such as "FHIR.Patient" or
+"System.String", and the elements of FHIR types are taken from the class
+information of the FHIR model.
+*/
+package types
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+)
+
+// Type is the static type of a FHIRPath expression: the type of the items that
+// it evaluates to, and whether there may be more than one of them.
+type Type struct {
+	// Name is the qualified name of the type of the items, e.g. "FHIR.Patient".
+	// If empty, the type is unknown.
+	Name reflect.TypeSpecifier
+
+	// List indicates that the expression may evaluate to more than one item.
+	List bool
+}
+
+var (
+	// Unknown is the type of a single item whose type cannot be inferred.
+	Unknown = Type{}
+
+	// Boolean is the type of a single System.Boolean.
+	Boolean = Type{Name: "System.Boolean"}
+
+	// String is the type of a single System.String.
+	String = Type{Name: "System.String"}
+
+	// Integer is the type of a single System.Integer.
+	Integer = Type{Name: "System.Integer"}
+
+	// Integer64 is the type of a single System.Long.
+	Integer64 = Type{Name: "System.Long"}
+
+	// Decimal is the type of a single System.Decimal.
+	Decimal = Type{Name: "System.Decimal"}
+
+	// Date is the type of a single System.Date.
+	Date = Type{Name: "System.Date"}
+
+	// DateTime is the type of a single System.DateTime.
+	DateTime = Type{Name: "System.DateTime"}
+
+	// Time is the type of a single System.Time.
+	Time = Type{Name: "System.Time"}
+
+	// Quantity is the type of a single System.Quantity.
+	Quantity = Type{Name: "System.Quantity"}
+)
+
+// systemTypes are the names of the types of the System namespace.
+var systemTypes = map[string]bool{
+	"Any":      true,
+	"Boolean":  true,
+	"String":   true,
+	"Integer":  true,
+	"Long":     true,
+	"Decimal":  true,
+	"Date":     true,
+	"DateTime": true,
+	"Time":     true,
+	"Quantity": true,
+}
+
+// abstract are the FHIR types that are only ever the declared type of an
+// element, and never the type of a value. Any element may be navigated on a
+// value of these types, since its actual type is not known until evaluation.
+var abstract = map[reflect.TypeSpecifier]bool{
+	"FHIR.Element":         true,
+	"FHIR.BackboneElement": true,
+	"FHIR.Resource":        true,
+	"FHIR.DomainResource":  true,
+	"System.Any":           true,
+}
+
+// numeric are the System types that are implicitly converted into one another
+// when they are compared or used in arithmetic, in order of precedence.
+var numeric = []reflect.TypeSpecifier{"System.Integer", "System.Long", "System.Decimal", "System.Quantity"}
+
+// Resolve returns the qualified name of the type named by the specifier, which
+// may be qualified by its namespace. An unqualified name is resolved in the
+// FHIR namespace first, and then in the System namespace. If the specifier
+// does not name a type, ok is false.
+func Resolve(specifier string) (name reflect.TypeSpecifier, ok bool) {
+	namespace, local, qualified := strings.Cut(specifier, ".")
+	if !qualified {
+		local = namespace
+	}
+	if !qualified || namespace == "FHIR" {
+		if _, ok := model.ClassInfo(local); ok {
+			return reflect.TypeSpecifier("FHIR." + local), true
+		}
+	}
+	if !qualified || namespace == "System" {
+		if systemTypes[local] {
+			return reflect.TypeSpecifier("System." + local), true
+		}
+	}
+	return "", false
+}
+
+// Names returns the qualified names of every type: the FHIR types, and then
+// the System types, each in sorted order.
+func Names() []reflect.TypeSpecifier {
+	var names []reflect.TypeSpecifier
+	for _, name := range model.TypeNames() {
+		names = append(names, reflect.TypeSpecifier("FHIR."+name))
+	}
+	var system []string
+	for name := range systemTypes {
+		system = append(system, name)
+	}
+	sort.Strings(system)
+	for _, name := range system {
+		names = append(names, reflect.TypeSpecifier("System."+name))
+	}
+	return names
+}
+
+// Parse returns the type named by the specifier, which may be a List of a
+// type -- e.g. "List<FHIR.HumanName>". If the specifier does not name a type,
+// ok is false.
+func Parse(specifier reflect.TypeSpecifier) (Type, bool) {
+	s := string(specifier)
+	if element, ok := strings.CutPrefix(s, "List<"); ok && strings.HasSuffix(element, ">") {
+		name, ok := Resolve(strings.TrimSuffix(element, ">"))
+		return Type{Name: name, List: true}, ok
+	}
+	name, ok := Resolve(s)
+	return Type{Name: name}, ok
+}
+
+// Specifier returns the type specifier of the type, including its cardinality
+// -- e.g. "List<FHIR.HumanName>" or "System.Boolean". An unknown type is
+// specified as "System.Any".
+func (t Type) Specifier() reflect.TypeSpecifier {
+	name := t.Name
+	if name == "" {
+		name = "System.Any"
+	}
+	if t.List {
+		return "List<" + name + ">"
+	}
+	return name
+}
+
+// String returns the type specifier of the type.
+func (t Type) String() string {
+	return string(t.Specifier())
+}
+
+// IsKnown returns whether the type of the items is known, such that it may be
+// checked. The abstract FHIR types, such as Element or Resource, are not known
+// types, since a value of these types may be of any of their subtypes.
+func (t Type) IsKnown() bool {
+	return t.Name != "" && !abstract[t.Name]
+}
+
+// Item returns the type of a single item of this type.
+func (t Type) Item() Type {
+	return Type{Name: t.Name}
+}
+
+// WithList returns this type, as a List if list is true.
+func (t Type) WithList(list bool) Type {
+	return Type{Name: t.Name, List: t.List || list}
+}
+
+// Element returns the type of the element named name on this type, which is a
+// List if either this type or the element is. Elements are looked up on the
+// type and each of its base types.
+//
+// If the type is not known, the element is of an unknown type. If the type is
+// known but does not define the element, ok is false.
+func (t Type) Element(name string) (element Type, ok bool) {
+	for info, _ := model.ClassInfo(string(t.Name)); info != nil; info, _ = model.ClassInfo(string(info.BaseType)) {
+		for _, e := range info.Element {
+			if e.Name == name {
+				element, _ := Parse(e.Type)
+				return element.WithList(t.List), true
+			}
+		}
+	}
+	if !t.IsKnown() {
+		return Unknown.WithList(t.List), true
+	}
+	return Unknown, false
+}
+
+// Member is an element of a type.
+type Member struct {
+	// Name is the name of the element.
+	Name string
+
+	// Type is the type of the element.
+	Type Type
+
+	// Owner is the qualified name of the type that defines the element, which
+	// is a base type if the element is inherited.
+	Owner reflect.TypeSpecifier
+}
+
+// Members returns the elements of the type, including those inherited from
+// its base types, in the order that they are defined from the type to its
+// least derived base. Types that are not FHIR types have no elements.
+func (t Type) Members() []Member {
+	var members []Member
+	for info, _ := model.ClassInfo(string(t.Name)); info != nil; info, _ = model.ClassInfo(string(info.BaseType)) {
+		owner := reflect.TypeSpecifier(info.Namespace + "." + info.Name)
+		for _, e := range info.Element {
+			element, _ := Parse(e.Type)
+			members = append(members, Member{Name: e.Name, Type: element, Owner: owner})
+		}
+	}
+	return members
+}
+
+// ChoiceElement returns the type of the choice ([x]) element of this type that
+// is named by its type, as it is in the FHIR JSON format -- e.g.
+// "valueQuantity" for the element "value" holding a Quantity. If the type does
+// not define such an element, ok is false.
+func (t Type) ChoiceElement(name string) (element Type, ok bool) {
+	for info, _ := model.ClassInfo(string(t.Name)); info != nil; info, _ = model.ClassInfo(string(info.BaseType)) {
+		for _, e := range info.Element {
+			suffix, ok := strings.CutPrefix(name, e.Name)
+			if !ok || suffix == "" || !isChoice(e.Type) {
+				continue
+			}
+			// Primitive types are capitalized when they name a choice, so
+			// that 'valueDateTime' holds a 'dateTime', and 'valueUri' a 'uri'.
+			for _, local := range []string{strings.ToLower(suffix[:1]) + suffix[1:], strings.ToLower(suffix), suffix} {
+				if _, ok := model.ClassInfo(local); ok {
+					return Type{Name: reflect.TypeSpecifier("FHIR." + local), List: t.List}, true
+				}
+			}
+		}
+	}
+	return Unknown, false
+}
+
+// isChoice returns whether the element type is that of a choice element.
+func isChoice(specifier reflect.TypeSpecifier) bool {
+	return specifier == "FHIR.Element" || specifier == "List<FHIR.Element>"
+}
+
+// System returns the System type that values of this type are implicitly
+// converted into, such as System.String for a FHIR code, or System.Quantity for
+// a FHIR Age. Types with no System representation are returned unchanged.
+func (t Type) System() Type {
+	if quantity := (Type{Name: "FHIR.Quantity"}); t.Is(quantity) {
+		return Quantity.WithList(t.List)
+	}
+	if !strings.HasPrefix(string(t.Name), "FHIR.") {
+		return t
+	}
+	for info, _ := model.ClassInfo(string(t.Name)); info != nil; info, _ = model.ClassInfo(string(info.BaseType)) {
+		for _, e := range info.Element {
+			if e.Name == "value" && strings.HasPrefix(string(e.Type), "System.") {
+				return Type{Name: e.Type, List: t.List}
+			}
+		}
+	}
+	return t
+}
+
+// Is returns whether this type is the other type, or derives from it -- e.g.
+// a FHIR.Patient is a FHIR.DomainResource. Cardinality is not considered.
+func (t Type) Is(other Type) bool {
+	if t.Name == other.Name {
+		return true
+	}
+	for info, _ := model.ClassInfo(string(t.Name)); info != nil; info, _ = model.ClassInfo(string(info.BaseType)) {
+		if info.BaseType == other.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignableTo returns whether a value of this type may be used where a value
+// of the other type is expected: either because it is of that type or one of
+// its subtypes, or because it is implicitly converted into it -- such as a FHIR
+// string into a System.String, or an Integer into a Decimal. Unknown types are
+// assignable to, and from, any type.
+func (t Type) AssignableTo(other Type) bool {
+	if !t.IsKnown() || !other.IsKnown() || t.Is(other) {
+		return true
+	}
+	from, to := t.System().Name, other.System().Name
+	if from == to || from == "System.Date" && to == "System.DateTime" {
+		return true
+	}
+	return precedence(from) >= 0 && precedence(from) <= precedence(to)
+}
+
+// ComparableTo returns whether values of this type may ever be equal to, or
+// ordered against, values of the other type. Unknown types are comparable to
+// any type.
+func (t Type) ComparableTo(other Type) bool {
+	return t.AssignableTo(other) || other.AssignableTo(t)
+}
+
+// precedence returns the precedence of a numeric System type in implicit
+// conversions, or -1 if the type is not numeric.
+func precedence(name reflect.TypeSpecifier) int {
+	for i, n := range numeric {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Arithmetic returns the type of the result of the arithmetic operator applied
+// to operands of the specified types. If either type is unknown, or the
+// operator is not defined for the types -- such as for a complex FHIR type like
+// HumanName -- the result is of an unknown type.
+//
+// See: https://hl7.org/fhirpath/N1/#math
+func Arithmetic(operator string, left, right Type) Type {
+	if !left.IsKnown() || !right.IsKnown() {
+		return Unknown
+	}
+	l, r := left.System().Name, right.System().Name
+	switch operator {
+	case "&":
+		if strings.HasPrefix(string(l), "System.") && strings.HasPrefix(string(r), "System.") {
+			return String
+		}
+		return Unknown
+	case "+", "-":
+		if l == "System.String" && r == "System.String" && operator == "+" {
+			return String
+		}
+		if isTemporal(l) && r == "System.Quantity" {
+			return Type{Name: l}
+		}
+	case "/":
+		if l == "System.Quantity" || r == "System.Quantity" {
+			return Quantity
+		}
+		if precedence(l) >= 0 && precedence(r) >= 0 {
+			return Decimal
+		}
+		return Unknown
+	}
+	pl, pr := precedence(l), precedence(r)
+	if pl < 0 || pr < 0 {
+		return Unknown
+	}
+	return Type{Name: numeric[max(pl, pr)]}
+}
+
+// isTemporal returns whether the System type is a date or time type.
+func isTemporal(name reflect.TypeSpecifier) bool {
+	return name == "System.Date" || name == "System.DateTime" || name == "System.Time"
+}
@@ -0,0 +1,154 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/types"
+	"github.com/friendly-fhir/go-fhirpath/reflect"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input reflect.TypeSpecifier
+		want  types.Type
+	}{
+		{"Unqualified FHIR type", "Patient", types.Type{Name: "FHIR.Patient"}},
+		{"Qualified FHIR type", "FHIR.code", types.Type{Name: "FHIR.code"}},
+		{"Unqualified System type", "String", types.String},
+		{"Qualified System type", "System.Integer", types.Integer},
+		{"List type", "List<FHIR.HumanName>", types.Type{Name: "FHIR.HumanName", List: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := types.Parse(tc.input)
+			if !ok {
+				t.Fatalf("Parse(%q) ok = false; want true", tc.input)
+			}
+
+			if got != tc.want {
+				t.Errorf("Parse(%q) = %v; want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse_UnknownType_ReturnsFalse(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input reflect.TypeSpecifier
+	}{
+		{"Unknown type", "Patinet"},
+		{"Wrong namespace", "System.Patient"},
+		{"Unknown element type", "List<Unknown>"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := types.Parse(tc.input); ok {
+				t.Errorf("Parse(%q) ok = true; want false", tc.input)
+			}
+		})
+	}
+}
+
+func TestTypeElement(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   types.Type
+		element string
+		want    types.Type
+		wantOK  bool
+	}{
+		{"Element", types.Type{Name: "FHIR.Patient"}, "gender", types.Type{Name: "FHIR.code"}, true},
+		{"List element", types.Type{Name: "FHIR.Patient"}, "name", types.Type{Name: "FHIR.HumanName", List: true}, true},
+		{"Element of list", types.Type{Name: "FHIR.HumanName", List: true}, "family", types.Type{Name: "FHIR.string", List: true}, true},
+		{"Inherited element", types.Type{Name: "FHIR.Patient"}, "text", types.Type{Name: "FHIR.Narrative"}, true},
+		{"Value of primitive", types.Type{Name: "FHIR.code"}, "value", types.String, true},
+		{"Element of abstract type", types.Type{Name: "FHIR.Resource"}, "name", types.Unknown, true},
+		{"Element of unknown type", types.Unknown, "name", types.Unknown, true},
+		{"Undefined element", types.Type{Name: "FHIR.Patient"}, "nmae", types.Unknown, false},
+		{"Element of System type", types.String, "value", types.Unknown, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.input.Element(tc.element)
+
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("Type(%v).Element(%q) = %v, %v; want %v, %v", tc.input, tc.element, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestTypeMembers(t *testing.T) {
+	got := types.Type{Name: "FHIR.Narrative", List: true}.Members()
+
+	want := []types.Member{
+		{Name: "div", Type: types.Type{Name: "FHIR.xhtml"}, Owner: "FHIR.Narrative"},
+		{Name: "status", Type: types.Type{Name: "FHIR.code"}, Owner: "FHIR.Narrative"},
+		{Name: "extension", Type: types.Type{Name: "FHIR.Extension", List: true}, Owner: "FHIR.Element"},
+		{Name: "id", Type: types.String, Owner: "FHIR.Element"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Members() mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestTypeAssignableTo(t *testing.T) {
+	testCases := []struct {
+		name string
+		from types.Type
+		to   types.Type
+		want bool
+	}{
+		{"Same type", types.String, types.String, true},
+		{"Subtype", types.Type{Name: "FHIR.Age"}, types.Type{Name: "FHIR.Quantity"}, true},
+		{"Primitive to System type", types.Type{Name: "FHIR.code"}, types.String, true},
+		{"Quantity to System type", types.Type{Name: "FHIR.Age"}, types.Quantity, true},
+		{"Integer to Decimal", types.Integer, types.Decimal, true},
+		{"Date to DateTime", types.Type{Name: "FHIR.date"}, types.DateTime, true},
+		{"Unknown type", types.Unknown, types.Integer, true},
+		{"Abstract type", types.Type{Name: "FHIR.Element"}, types.Integer, true},
+		{"Decimal to Integer", types.Decimal, types.Integer, false},
+		{"Primitive to other System type", types.Type{Name: "FHIR.code"}, types.Integer, false},
+		{"Unrelated complex types", types.Type{Name: "FHIR.HumanName"}, types.Type{Name: "FHIR.Address"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.from.AssignableTo(tc.to); got != tc.want {
+				t.Errorf("Type(%v).AssignableTo(%v) = %v; want %v", tc.from, tc.to, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	testCases := []struct {
+		name        string
+		operator    string
+		left, right types.Type
+		want        types.Type
+	}{
+		{"Integer addition", "+", types.Integer, types.Integer, types.Integer},
+		{"Mixed addition", "+", types.Integer, types.Decimal, types.Decimal},
+		{"Division", "/", types.Integer, types.Integer, types.Decimal},
+		{"String concatenation", "+", types.String, types.Type{Name: "FHIR.string"}, types.String},
+		{"Date arithmetic", "+", types.Date, types.Quantity, types.Date},
+		{"Unknown operand", "*", types.Unknown, types.Integer, types.Unknown},
+		{"Invalid operands", "*", types.String, types.Integer, types.Unknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := types.Arithmetic(tc.operator, tc.left, tc.right); got != tc.want {
+				t.Errorf("Arithmetic(%q, %v, %v) = %v; want %v", tc.operator, tc.left, tc.right, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Package xhtml provides a validator for the XHTML content of FHIR narratives,
+following the rules that the FHIR specification places on the Narrative.div
+element.
+
+See: https://hl7.org/fhir/R4/narrative.html#xhtml
+*/
+package xhtml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Namespace is the XML namespace of XHTML.
+const Namespace = "http://www.w3.org/1999/xhtml"
+
+// xmlNamespace is the XML namespace of the reserved 'xml' prefix, as used by
+// the 'xml:lang' attribute.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// ErrInvalid is an error raised when XHTML content does not conform to the
+// FHIR rules for narratives.
+var ErrInvalid = errors.New("invalid narrative")
+
+// elements are the XHTML elements that a narrative may contain.
+var elements = setOf(
+	"a", "abbr", "acronym", "address", "b", "bdo", "big", "blockquote", "br",
+	"caption", "cite", "code", "col", "colgroup", "dd", "dfn", "div", "dl",
+	"dt", "em", "h1", "h2", "h3", "h4", "h5", "h6", "hr", "i", "img", "kbd",
+	"li", "ol", "p", "pre", "q", "samp", "small", "span", "strong", "sub",
+	"sup", "table", "tbody", "td", "tfoot", "th", "thead", "tr", "tt", "ul",
+	"var",
+)
+
+// globalAttributes are the attributes that any element may have.
+var globalAttributes = setOf(
+	"accesskey", "class", "dir", "id", "lang", "style", "tabindex", "title",
+)
+
+// attributes are the attributes that specific elements may have, in addition
+// to the global attributes.
+var attributes = map[string]map[string]bool{
+	"a":          setOf("href", "hreflang", "name", "rel", "type"),
+	"blockquote": setOf("cite"),
+	"col":        setOf("align", "char", "charoff", "span", "valign", "width"),
+	"colgroup":   setOf("align", "char", "charoff", "span", "valign", "width"),
+	"img":        setOf("alt", "height", "src", "width"),
+	"li":         setOf("type", "value"),
+	"ol":         setOf("start", "type"),
+	"q":          setOf("cite"),
+	"table":      setOf("border", "cellpadding", "cellspacing", "frame", "rules", "summary", "width"),
+	"tbody":      setOf("align", "char", "charoff", "valign"),
+	"td":         setOf("abbr", "align", "axis", "char", "charoff", "colspan", "headers", "rowspan", "scope", "valign"),
+	"tfoot":      setOf("align", "char", "charoff", "valign"),
+	"th":         setOf("abbr", "align", "axis", "char", "charoff", "colspan", "headers", "rowspan", "scope", "valign"),
+	"thead":      setOf("align", "char", "charoff", "valign"),
+	"tr":         setOf("align", "char", "charoff", "valign"),
+	"ul":         setOf("type"),
+}
+
+func setOf(values ...string) map[string]bool {
+	result := make(map[string]bool, len(values))
+	for _, value := range values {
+		result[value] = true
+	}
+	return result
+}
+
+// Validate parses the XHTML content of a narrative, and validates it against
+// the FHIR rules for narratives:
+//
+//   - The content is a single 'div' element in the XHTML namespace.
+//   - Only the basic HTML formatting elements and their attributes are used,
+//     so there are no scripts, forms, frames, objects, or event handlers.
+//   - There are no references to external content, such as images or styles
+//     that are not contained within the resource.
+//   - There is some non-whitespace content.
+//
+// If the content is not well-formed XML, the parse error is returned. If the
+// content violates any rule, the returned error wraps ErrInvalid and describes
+// every violation.
+func Validate(source string) error {
+	decoder := xml.NewDecoder(strings.NewReader(source))
+	decoder.Strict = true
+	decoder.Entity = xml.HTMLEntity
+
+	v := &validator{}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		v.token(token)
+	}
+	if !v.root {
+		v.errorf("content is not a 'div' element")
+	} else if !v.content {
+		v.errorf("narrative has no content")
+	}
+	return errors.Join(v.errs...)
+}
+
+// validator records the violations found in a stream of XML tokens.
+type validator struct {
+	depth   int
+	root    bool
+	content bool
+	errs    []error
+}
+
+func (v *validator) errorf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalid}, args...)...))
+}
+
+func (v *validator) token(token xml.Token) {
+	switch token := token.(type) {
+	case xml.StartElement:
+		v.startElement(token)
+		v.depth++
+	case xml.EndElement:
+		v.depth--
+	case xml.CharData:
+		if strings.TrimSpace(string(token)) == "" {
+			return
+		}
+		if v.depth == 0 {
+			v.errorf("text outside of the 'div' element")
+			return
+		}
+		v.content = true
+	case xml.ProcInst:
+		if token.Target != "xml" {
+			v.errorf("processing instruction '%v' is not allowed", token.Target)
+		}
+	case xml.Directive:
+		v.errorf("directives such as DOCTYPE are not allowed")
+	}
+}
+
+func (v *validator) startElement(element xml.StartElement) {
+	name := element.Name.Local
+	if v.depth == 0 {
+		if v.root {
+			v.errorf("element <%v> outside of the 'div' element", name)
+		}
+		if name != "div" || element.Name.Space != Namespace {
+			v.errorf("root element <%v> is not an XHTML 'div' element", name)
+		}
+		v.root = true
+	}
+	if element.Name.Space != Namespace {
+		v.errorf("element <%v> is not in the XHTML namespace", name)
+		return
+	}
+	if !elements[name] {
+		v.errorf("element <%v> is not allowed", name)
+		return
+	}
+	if name == "img" {
+		v.content = true
+	}
+	for _, attr := range element.Attr {
+		v.attribute(name, attr)
+	}
+}
+
+func (v *validator) attribute(element string, attr xml.Attr) {
+	name := attr.Name.Local
+	switch {
+	case attr.Name.Space == "xmlns", attr.Name.Space == "" && name == "xmlns":
+		return
+	case attr.Name.Space == xmlNamespace && name == "lang":
+		return
+	case attr.Name.Space != "":
+		v.errorf("attribute '%v' of <%v> is not allowed", name, element)
+		return
+	case strings.HasPrefix(strings.ToLower(name), "on"):
+		v.errorf("event handler '%v' of <%v> is not allowed", name, element)
+		return
+	case !globalAttributes[name] && !attributes[element][name]:
+		v.errorf("attribute '%v' of <%v> is not allowed", name, element)
+		return
+	}
+
+	value := strings.ToLower(strings.TrimSpace(attr.Value))
+	switch {
+	case element == "img" && name == "src":
+		if !strings.HasPrefix(value, "#") && !strings.HasPrefix(value, "data:") {
+			v.errorf("image source '%v' is an external reference", attr.Value)
+		}
+	case element == "a" && name == "href":
+		if strings.HasPrefix(value, "javascript:") {
+			v.errorf("link '%v' is a script", attr.Value)
+		}
+	case name == "style":
+		if strings.Contains(value, "url(") || strings.Contains(value, "@import") || strings.Contains(value, "expression(") {
+			v.errorf("style of <%v> references external content", element)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package xhtml_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/xhtml"
+)
+
+func TestValidate_Valid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+	}{
+		{"Text", `<div xmlns="http://www.w3.org/1999/xhtml">Hello</div>`},
+		{"Formatting", `<div xmlns="http://www.w3.org/1999/xhtml"><p class="x">A <b>bold</b> <a href="http://example.com">link</a></p></div>`},
+		{"Table", `<div xmlns="http://www.w3.org/1999/xhtml"><table border="1"><tr><td colspan="2">1</td></tr></table></div>`},
+		{"Contained image", `<div xmlns="http://www.w3.org/1999/xhtml"><img src="#photo" alt="photo"/></div>`},
+		{"Language", `<div xmlns="http://www.w3.org/1999/xhtml" xml:lang="en" lang="en">Hello</div>`},
+		{"HTML entity", `<div xmlns="http://www.w3.org/1999/xhtml">A&nbsp;B</div>`},
+		{"Inline style", `<div xmlns="http://www.w3.org/1999/xhtml"><span style="color: red">!</span></div>`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := xhtml.Validate(tc.source); err != nil {
+				t.Errorf("Validate(%q) = %v; want nil", tc.source, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+	}{
+		{"Not a div", `<p xmlns="http://www.w3.org/1999/xhtml">Hello</p>`},
+		{"Without namespace", `<div>Hello</div>`},
+		{"Empty", `<div xmlns="http://www.w3.org/1999/xhtml">   </div>`},
+		{"Script", `<div xmlns="http://www.w3.org/1999/xhtml">Hello<script>alert(1)</script></div>`},
+		{"Form", `<div xmlns="http://www.w3.org/1999/xhtml">Hello<form><input/></form></div>`},
+		{"Event handler", `<div xmlns="http://www.w3.org/1999/xhtml"><p onclick="alert(1)">Hello</p></div>`},
+		{"Unknown attribute", `<div xmlns="http://www.w3.org/1999/xhtml"><p href="x">Hello</p></div>`},
+		{"External image", `<div xmlns="http://www.w3.org/1999/xhtml"><img src="http://example.com/x.png"/></div>`},
+		{"Script link", `<div xmlns="http://www.w3.org/1999/xhtml"><a href="javascript:alert(1)">Hello</a></div>`},
+		{"External style", `<div xmlns="http://www.w3.org/1999/xhtml"><p style="background: url(http://example.com/x.png)">Hello</p></div>`},
+		{"Other namespace", `<div xmlns="http://www.w3.org/1999/xhtml" xmlns:svg="http://www.w3.org/2000/svg"><svg:svg/>Hello</div>`},
+		{"Multiple roots", `<div xmlns="http://www.w3.org/1999/xhtml">Hello</div><div xmlns="http://www.w3.org/1999/xhtml">World</div>`},
+		{"Text outside root", `<div xmlns="http://www.w3.org/1999/xhtml">Hello</div>World`},
+		{"Doctype", `<!DOCTYPE html><div xmlns="http://www.w3.org/1999/xhtml">Hello</div>`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := xhtml.Validate(tc.source)
+
+			if got, want := err, xhtml.ErrInvalid; !errors.Is(got, want) {
+				t.Errorf("Validate(%q) = %v; want %v", tc.source, got, want)
+			}
+		})
+	}
+}
+
+func TestValidate_Malformed_ReturnsSyntaxError(t *testing.T) {
+	err := xhtml.Validate(`<div xmlns="http://www.w3.org/1999/xhtml">Hello</p>`)
+
+	var syntaxErr *xml.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("Validate() = %v; want xml.SyntaxError", err)
+	}
+}
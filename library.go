@@ -0,0 +1,121 @@
+package fhirpath
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/funcs"
+)
+
+// Function is the definition of a custom function of a [FunctionLibrary].
+type Function struct {
+	// Name is the name that the function is invoked by.
+	Name string
+
+	// Func is the Go implementation of the function, as described by
+	// [AddFunc].
+	Func any
+
+	// Doc is a description of the function, which is reported when
+	// completing expressions with [Complete].
+	Doc string
+
+	// Params are the names of the parameters of Func that follow its input,
+	// which document the signature of the function. If empty, the parameters
+	// are unnamed.
+	Params []string
+}
+
+// FunctionLibrary is a set of related custom functions that is added to the
+// compiler as a unit with [AddLibrary]. The zero value is an empty library.
+//
+// A library may define more than one function with the same name, as
+// overloads that differ in their parameters. The overload that is invoked is
+// selected by the values of the arguments: of the overloads that accept them,
+// the one that requires the fewest implicit conversions -- such as an Integer
+// into a Decimal -- is invoked, preferring the first one defined.
+type FunctionLibrary struct {
+	functions map[string][]Function
+
+	// compiled are the definitions of the functions, with each of their
+	// overloads, as validated by Define.
+	compiled map[string]*funcs.Function
+}
+
+// Define adds the function to the library, or adds an overload if the library
+// already defines a function with the same name. If the function has an
+// unsupported signature, or the same parameters as an existing overload, an
+// error wrapping [ErrInvalidSignature] is returned.
+func (l *FunctionLibrary) Define(fn Function) error {
+	if !identifierPattern.MatchString(fn.Name) {
+		return fmt.Errorf("fhirpath: cannot define function '%v': not a valid identifier", fn.Name)
+	}
+	overloads := append(slices.Clone(l.functions[fn.Name]), fn)
+	function, err := overload(fn.Name, overloads)
+	if err != nil {
+		return fmt.Errorf("fhirpath: cannot define function '%v': %w", fn.Name, err)
+	}
+	if l.functions == nil {
+		l.functions = map[string][]Function{}
+		l.compiled = map[string]*funcs.Function{}
+	}
+	l.functions[fn.Name] = overloads
+	l.compiled[fn.Name] = function
+	return nil
+}
+
+// overload returns the definition of the function named name, with each of
+// the overloads.
+func overload(name string, overloads []Function) (*funcs.Function, error) {
+	functions := make([]*funcs.Function, 0, len(overloads))
+	for _, fn := range overloads {
+		function, err := funcs.Reflect(name, fn.Func, fn.Params...)
+		if err != nil {
+			return nil, err
+		}
+		function.Doc = fn.Doc
+		functions = append(functions, function)
+	}
+	return funcs.Overload(name, functions...)
+}
+
+// Names returns the names of the functions of the library, in sorted order.
+func (l *FunctionLibrary) Names() []string {
+	names := make([]string, 0, len(l.functions))
+	for name := range l.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddLibrary returns a [CompileOption] that adds the functions of the library
+// to the FHIRPath compiler. Each function is invoked by its name, prefixed by
+// the prefix, if any -- so that with the prefix 'acme_', the function
+// 'fullName' is invoked as 'acme_fullName()'. Prefixing avoids collisions
+// with other libraries, and with functions that later versions of FHIRPath
+// may define.
+//
+// As with [AddFunc], a function of the library may not replace a function
+// defined by FHIRPath or another custom function. The library must not be nil.
+func AddLibrary(lib *FunctionLibrary, prefix string) CompileOption {
+	return compileOption(func(cfg *compileConfig) error {
+		if lib == nil {
+			return fmt.Errorf("fhirpath: cannot add library: library is nil")
+		}
+		if prefix != "" && !identifierPattern.MatchString(prefix) {
+			return fmt.Errorf("fhirpath: cannot add library with prefix '%v': not a valid identifier", prefix)
+		}
+		for _, name := range lib.Names() {
+			function := lib.compiled[name]
+			if prefix != "" {
+				function = funcs.Rename(function, prefix+name)
+			}
+			if err := cfg.addFunction(prefix+name, function); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
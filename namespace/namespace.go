@@ -3,6 +3,7 @@ package namespace
 import (
 	"fmt"
 	stdreflect "reflect"
+	"strings"
 
 	fhir "github.com/friendly-fhir/go-fhir/r4/core"
 	"github.com/friendly-fhir/go-fhirpath/reflect"
@@ -14,7 +15,7 @@ var (
 	R4 = New("FHIR", fhirNamer, r4Element, r4Resource, r4Domain, r4Backbone)
 
 	// System is the system namespace that contains all system types.
-	System = New("System", basicNamer, systemAny)
+	System = New("System", systemNamer, systemAny)
 
 	// Reflect is the namespace that contains all reflection types.
 	Reflect = New("Reflect", basicNamer, reflectInfo, reflectElement)
@@ -83,6 +84,18 @@ func (n *Namespace) Name(t stdreflect.Type) reflect.TypeSpecifier {
 	return n.namer.Name(t)
 }
 
+// ChoiceName returns the name of a choice ([x]) element when it holds a value
+// of the specified type. This is the element name followed by the capitalized
+// name of the type, e.g. "valueQuantity" for the element "value" holding a
+// Quantity.
+func (n *Namespace) ChoiceName(element string, t stdreflect.Type) string {
+	name := string(n.Name(t))
+	if name == "" {
+		return element
+	}
+	return element + strings.ToUpper(name[:1]) + name[1:]
+}
+
 // Contains returns true if the namespace contains the specified type.
 func (n *Namespace) Contains(t stdreflect.Type) bool {
 	for _, iface := range n.interfaces {
@@ -101,13 +114,16 @@ var (
 	r4Domain       = stdreflect.TypeOf((*fhir.DomainResource)(nil)).Elem()
 	reflectInfo    = stdreflect.TypeOf((*reflect.Info)(nil)).Elem()
 	reflectElement = stdreflect.TypeOf((*reflect.InfoElement)(nil)).Elem()
+	systemLong     = stdreflect.TypeOf(system.Integer64(0))
 
 	fhirNamer = NamerFunc(func(t stdreflect.Type) reflect.TypeSpecifier {
 		if t.Kind() == stdreflect.Ptr {
 			t = t.Elem()
 		}
 		name := t.Name()
-		// TODO: primitives should be in camelCase instead of PascalCase.
+		if isPrimitive(t) {
+			name = camelCase(name)
+		}
 		return reflect.TypeSpecifier(name)
 	})
 	basicNamer = NamerFunc(func(t stdreflect.Type) reflect.TypeSpecifier {
@@ -116,4 +132,45 @@ var (
 		}
 		return reflect.TypeSpecifier(t.Name())
 	})
+	// systemNamer names the System types as FHIRPath does, where the Go
+	// Integer64 type is the System Long type.
+	systemNamer = NamerFunc(func(t stdreflect.Type) reflect.TypeSpecifier {
+		if t.Kind() == stdreflect.Ptr {
+			t = t.Elem()
+		}
+		if t == systemLong {
+			return "Long"
+		}
+		return basicNamer(t)
+	})
 )
+
+// isPrimitive returns whether t is a FHIR primitive type, which is a struct
+// that holds its value in a native Go field.
+func isPrimitive(t stdreflect.Type) bool {
+	if t.Kind() != stdreflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("fhirpath") != "value" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case stdreflect.Pointer, stdreflect.Interface, stdreflect.Slice, stdreflect.Struct:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// camelCase converts the Go name of a FHIR primitive into its FHIR name, e.g.
+// "dateTime" for "DateTime". Initialisms, such as "ID" or "URI", are lowered
+// entirely.
+func camelCase(name string) string {
+	if strings.ToUpper(name) == name {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
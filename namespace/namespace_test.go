@@ -72,6 +72,16 @@ func TestNamespaceName(t *testing.T) {
 			input:     reflect.TypeOf((*fhir.Quantity)(nil)),
 			namespace: namespace.R4,
 			want:      "Quantity",
+		}, {
+			name:      "FHIR primitive type",
+			input:     reflect.TypeOf((*fhir.DateTime)(nil)),
+			namespace: namespace.R4,
+			want:      "dateTime",
+		}, {
+			name:      "FHIR primitive type with initialism",
+			input:     reflect.TypeOf((*fhir.URI)(nil)),
+			namespace: namespace.R4,
+			want:      "uri",
 		}, {
 			name:      "FHIR abstract type",
 			input:     reflect.TypeOf((*fhir.Element)(nil)).Elem(),
@@ -82,6 +92,11 @@ func TestNamespaceName(t *testing.T) {
 			input:     reflect.TypeOf((*system.String)(nil)).Elem(),
 			namespace: namespace.System,
 			want:      "String",
+		}, {
+			name:      "System Long type",
+			input:     reflect.TypeOf((*system.Integer64)(nil)).Elem(),
+			namespace: namespace.System,
+			want:      "Long",
 		}, {
 			name:      "Reflect type",
 			input:     reflect.TypeOf((*fpreflect.ClassInfo)(nil)),
@@ -150,6 +165,11 @@ func TestNamespaceQualifiedName(t *testing.T) {
 			input:     reflect.TypeOf((*fhir.Quantity)(nil)),
 			namespace: namespace.R4,
 			want:      "FHIR.Quantity",
+		}, {
+			name:      "FHIR primitive type",
+			input:     reflect.TypeOf((*fhir.String)(nil)),
+			namespace: namespace.R4,
+			want:      "FHIR.string",
 		}, {
 			name:      "FHIR abstract type",
 			input:     reflect.TypeOf((*fhir.Element)(nil)).Elem(),
@@ -234,3 +254,34 @@ func TestNamespaceContains(t *testing.T) {
 		})
 	}
 }
+
+func TestNamespaceChoiceName(t *testing.T) {
+	testCases := []struct {
+		name    string
+		element string
+		input   reflect.Type
+		want    string
+	}{
+		{
+			name:    "FHIR complex type",
+			element: "value",
+			input:   reflect.TypeOf((*fhir.Quantity)(nil)),
+			want:    "valueQuantity",
+		}, {
+			name:    "FHIR primitive type",
+			element: "effective",
+			input:   reflect.TypeOf((*fhir.DateTime)(nil)),
+			want:    "effectiveDateTime",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := namespace.R4.ChoiceName(tc.element, tc.input)
+
+			if got != tc.want {
+				t.Errorf("Namespace.ChoiceName() = %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package resolver
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/bundle"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/namespace"
+)
+
+// FromBundle returns a [Resolver] that resolves references to the resources
+// contained in the entries of a Bundle, following the FHIR rules for resolving
+// references within a bundle:
+//
+//   - Absolute references, such as 'urn:uuid:...' or 'http://.../Patient/1',
+//     match the fullUrl of an entry.
+//   - Relative references, such as 'Patient/1', are resolved against the base
+//     of the fullUrl of the entry that contains the reference, and match the
+//     entry whose fullUrl is the result. They also match an entry without a
+//     fullUrl whose resource has that type and id.
+//   - Version-specific references, such as 'Patient/1/_history/2', match as
+//     above, and only if the version of the resource is also the same.
+//
+// The entry that contains a reference is found from the [Referrer] of the
+// context, which resolve() sets to the element that holds the reference. If
+// there is no referrer, or it is not within the bundle, a relative reference
+// only matches if exactly one entry has that type and id.
+//
+// If no entry matches the reference, nil is returned without an error. If
+// several entries match, the first one is returned.
+//
+// See: https://hl7.org/fhir/R4/bundle.html#references
+func FromBundle(b *bundle.Bundle) Resolver {
+	r := &bundleResolver{owners: map[any]*bundleEntry{}}
+	for _, entry := range b.GetEntry() {
+		if entry == nil || entry.Resource == nil {
+			continue
+		}
+		e := newBundleEntry(entry)
+		r.entries = append(r.entries, e)
+		r.owners[entry.Resource] = e
+		r.index(e, entry.Resource)
+	}
+	return r
+}
+
+type bundleResolver struct {
+	entries []*bundleEntry
+
+	// owners are the entries that contain each element of the bundle, keyed by
+	// the pointer to the element.
+	owners map[any]*bundleEntry
+	BaseResolver
+}
+
+// index records entry as the owner of every element within v.
+func (r *bundleResolver) index(entry *bundleEntry, v any) {
+	for _, field := range model.Fields(reflect.TypeOf(v)) {
+		for _, child := range model.Children(v, field.Name) {
+			if reflect.TypeOf(child).Kind() != reflect.Pointer {
+				continue
+			}
+			r.owners[child] = entry
+			r.index(entry, child)
+		}
+	}
+}
+
+// bundleEntry is the identity of a single resource within a bundle.
+type bundleEntry struct {
+	fullURL  string
+	local    string
+	version  string
+	resource fhir.Resource
+
+	// base is the server base of a RESTful fullUrl, which relative references
+	// from the resource are resolved against. It is empty if the entry has no
+	// fullUrl, or if its fullUrl is not RESTful, such as a 'urn:uuid:'.
+	base string
+}
+
+func newBundleEntry(entry *bundle.BundleEntry) *bundleEntry {
+	result := &bundleEntry{resource: entry.Resource}
+	if entry.FullURL != nil {
+		result.fullURL = entry.FullURL.Value
+	}
+	if id := entry.Resource.GetID(); id != "" {
+		name := namespace.R4.Name(reflect.TypeOf(entry.Resource))
+		result.local = string(name) + "/" + id
+		if base, ok := strings.CutSuffix(result.fullURL, "/"+result.local); ok {
+			result.base = base
+		}
+	}
+	if meta := entry.Resource.GetMeta(); meta != nil && meta.VersionID != nil {
+		result.version = meta.VersionID.Value
+	}
+	return result
+}
+
+// matches returns whether the reference, without any version, refers to this
+// entry. Relative references are resolved against base, the base of the entry
+// that contains the reference.
+func (e *bundleEntry) matches(reference, base string) bool {
+	if e.fullURL == reference {
+		return true
+	}
+	if isAbsolute(reference) {
+		return false
+	}
+	if e.fullURL == "" {
+		return e.local == reference
+	}
+	return base != "" && e.fullURL == base+"/"+reference
+}
+
+// Resolve resolves the reference to the resource of the matching entry.
+func (r *bundleResolver) Resolve(ctx context.Context, reference string) (any, error) {
+	reference, version, versioned := strings.Cut(reference, "/_history/")
+	var candidates []*bundleEntry
+	for _, entry := range r.entries {
+		if !versioned || entry.version == version {
+			candidates = append(candidates, entry)
+		}
+	}
+
+	owner, ok := r.ownerOf(ctx)
+	if !ok && !isAbsolute(reference) {
+		return r.resolveLocal(candidates, reference), nil
+	}
+	var base string
+	if owner != nil {
+		base = owner.base
+	}
+	for _, entry := range candidates {
+		if entry.matches(reference, base) {
+			return entry.resource, nil
+		}
+	}
+	return nil, nil
+}
+
+// key identifies the resolution of a relative reference by the base of the
+// entry that contains its referrer, since references from entries on other
+// bases may resolve to other entries.
+func (r *bundleResolver) key(ctx context.Context, reference string) string {
+	if isAbsolute(reference) {
+		return reference
+	}
+	owner, ok := r.ownerOf(ctx)
+	if !ok {
+		return reference
+	}
+	return owner.base + "\x00" + reference
+}
+
+// ownerOf returns the entry that contains the referrer of the context.
+func (r *bundleResolver) ownerOf(ctx context.Context) (*bundleEntry, bool) {
+	referrer, ok := Referrer(ctx)
+	if !ok || reflect.TypeOf(referrer).Kind() != reflect.Pointer {
+		return nil, false
+	}
+	owner, ok := r.owners[referrer]
+	return owner, ok
+}
+
+// resolveLocal resolves a relative reference without knowing the entry that
+// contains it, which is only possible if exactly one entry has the type and
+// id of the reference, and a RESTful fullUrl or none at all.
+func (r *bundleResolver) resolveLocal(entries []*bundleEntry, reference string) any {
+	var result any
+	for _, entry := range entries {
+		if entry.local != reference || (entry.fullURL != "" && entry.base == "") {
+			continue
+		}
+		if result != nil {
+			return nil
+		}
+		result = entry.resource
+	}
+	return result
+}
+
+// isAbsolute returns whether the reference is an absolute URL or URN, rather
+// than a reference relative to a base.
+func isAbsolute(reference string) bool {
+	return strings.Contains(reference, ":")
+}
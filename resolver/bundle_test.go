@@ -0,0 +1,209 @@
+package resolver_test
+
+import (
+	"context"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/bundle"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/observation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhirpath/resolver"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFromBundle(t *testing.T) {
+	restful := &patient.Patient{
+		ID:   "123",
+		Meta: &fhir.Meta{VersionID: &fhir.ID{Value: "2"}},
+	}
+	uuid := &patient.Patient{ID: "456"}
+	local := &observation.Observation{ID: "789"}
+	b := &bundle.Bundle{
+		Entry: []*bundle.BundleEntry{
+			{FullURL: &fhir.URI{Value: "http://example.com/fhir/Patient/123"}, Resource: restful},
+			{FullURL: &fhir.URI{Value: "urn:uuid:04121321-4af5-424c-a0e1-ed3aab1c349d"}, Resource: uuid},
+			{Resource: local},
+			{FullURL: &fhir.URI{Value: "http://example.com/fhir/Patient/empty"}},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		reference string
+		want      any
+	}{
+		{
+			name:      "Absolute reference matches full URL",
+			reference: "http://example.com/fhir/Patient/123",
+			want:      restful,
+		}, {
+			name:      "Relative reference matches full URL",
+			reference: "Patient/123",
+			want:      restful,
+		}, {
+			name:      "Versioned reference matches version",
+			reference: "Patient/123/_history/2",
+			want:      restful,
+		}, {
+			name:      "Versioned absolute reference matches version",
+			reference: "http://example.com/fhir/Patient/123/_history/2",
+			want:      restful,
+		}, {
+			name:      "Versioned reference of other version",
+			reference: "Patient/123/_history/1",
+			want:      nil,
+		}, {
+			name:      "Absolute reference with other base",
+			reference: "http://other.com/fhir/Patient/123",
+			want:      nil,
+		}, {
+			name:      "UUID reference matches full URL",
+			reference: "urn:uuid:04121321-4af5-424c-a0e1-ed3aab1c349d",
+			want:      uuid,
+		}, {
+			name:      "Relative reference does not match UUID",
+			reference: "Patient/456",
+			want:      nil,
+		}, {
+			name:      "Relative reference matches entry without full URL",
+			reference: "Observation/789",
+			want:      local,
+		}, {
+			name:      "Entry without resource",
+			reference: "Patient/empty",
+			want:      nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := resolver.FromBundle(b)
+
+			got, err := r.Resolve(context.Background(), tc.reference)
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tc.reference, err)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Resolve(%q) mismatch (-got +want):\n%s", tc.reference, diff)
+			}
+		})
+	}
+}
+
+func reference(ref string) *fhir.Reference {
+	return &fhir.Reference{Reference: &fhir.String{Value: ref}}
+}
+
+func TestFromBundle_Referrer(t *testing.T) {
+	patientA := &patient.Patient{ID: "1"}
+	patientB := &patient.Patient{ID: "1"}
+	fromA := &observation.Observation{ID: "a", Subject: reference("Patient/1")}
+	fromB := &observation.Observation{ID: "b", Subject: reference("Patient/1")}
+	fromUUID := &observation.Observation{ID: "c", Subject: reference("Patient/1")}
+	b := &bundle.Bundle{
+		Entry: []*bundle.BundleEntry{
+			{FullURL: &fhir.URI{Value: "http://a.com/fhir/Patient/1"}, Resource: patientA},
+			{FullURL: &fhir.URI{Value: "http://b.com/fhir/Patient/1"}, Resource: patientB},
+			{FullURL: &fhir.URI{Value: "http://a.com/fhir/Observation/a"}, Resource: fromA},
+			{FullURL: &fhir.URI{Value: "http://b.com/fhir/Observation/b"}, Resource: fromB},
+			{FullURL: &fhir.URI{Value: "urn:uuid:9d4e0b2a-0c4b-4f6e-8f0d-3c1d6a5b7e21"}, Resource: fromUUID},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		referrer any
+		want     any
+	}{
+		{
+			name:     "Referrer on first base",
+			referrer: fromA.Subject,
+			want:     patientA,
+		}, {
+			name:     "Referrer on second base",
+			referrer: fromB.Subject,
+			want:     patientB,
+		}, {
+			name:     "Referrer with UUID full URL",
+			referrer: fromUUID.Subject,
+			want:     nil,
+		}, {
+			name:     "Referrer outside of bundle is ambiguous",
+			referrer: reference("Patient/1"),
+			want:     nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := resolver.FromBundle(b)
+			ctx := resolver.WithReferrer(context.Background(), tc.referrer)
+
+			got, err := r.Resolve(ctx, "Patient/1")
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("Resolve() = %p; want %p", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFromBundle_DecoratedReferrer(t *testing.T) {
+	patientA := &patient.Patient{ID: "1"}
+	patientB := &patient.Patient{ID: "1"}
+	fromA := &observation.Observation{ID: "a", Subject: reference("Patient/1")}
+	fromB := &observation.Observation{ID: "b", Subject: reference("Patient/1")}
+	b := &bundle.Bundle{
+		Entry: []*bundle.BundleEntry{
+			{FullURL: &fhir.URI{Value: "http://a.com/fhir/Patient/1"}, Resource: patientA},
+			{FullURL: &fhir.URI{Value: "http://b.com/fhir/Patient/1"}, Resource: patientB},
+			{FullURL: &fhir.URI{Value: "http://a.com/fhir/Observation/a"}, Resource: fromA},
+			{FullURL: &fhir.URI{Value: "http://b.com/fhir/Observation/b"}, Resource: fromB},
+		},
+	}
+
+	testCases := []struct {
+		name string
+		r    resolver.Resolver
+	}{
+		{
+			name: "Cached",
+			r:    resolver.Cached(resolver.FromBundle(b), 10, 0),
+		}, {
+			name: "Deduplicated",
+			r:    resolver.Deduplicated(resolver.FromBundle(b)),
+		}, {
+			name: "Cached chain",
+			r:    resolver.Cached(resolver.Chain(resolver.FromBundle(b)), 10, 0),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, want := range []struct {
+				referrer any
+				resource any
+			}{
+				{fromA.Subject, patientA},
+				{fromB.Subject, patientB},
+				{fromA.Subject, patientA},
+			} {
+				ctx := resolver.WithReferrer(context.Background(), want.referrer)
+
+				got, err := tc.r.Resolve(ctx, "Patient/1")
+				if err != nil {
+					t.Fatalf("Resolve() error = %v", err)
+				}
+
+				if got != want.resource {
+					t.Errorf("Resolve() = %p; want %p", got, want.resource)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package resolver
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cached returns a [Resolver] that caches the resources resolved by r, keyed
+// by reference -- or, for resolvers whose resolution also depends on the
+// referrer of the reference, such as [FromBundle], by the reference as r
+// resolves it. At most size references are cached, with the least recently
+// used reference evicted first. Each reference is cached for at most ttl,
+// after which it is resolved again.
+//
+// References that are not found are cached as well, but failures are not. If
+// size is not positive, the cache is unbounded; if ttl is not positive,
+// references never expire.
+func Cached(r Resolver, size int, ttl time.Duration) Resolver {
+	return &cachedResolver{
+		resolver: r,
+		size:     size,
+		ttl:      ttl,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+type cachedResolver struct {
+	resolver Resolver
+	size     int
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // of *cacheEntry, most recently used first
+	BaseResolver
+}
+
+type cacheEntry struct {
+	key      string
+	resource any
+	expires  time.Time
+}
+
+// Resolve returns the cached resource of the reference, or resolves it with
+// the underlying resolver if it is not cached.
+func (r *cachedResolver) Resolve(ctx context.Context, reference string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := keyOf(ctx, r.resolver, reference)
+	if resource, ok := r.lookup(key); ok {
+		return resource, nil
+	}
+	resource, err := r.resolver.Resolve(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	r.store(key, resource)
+	return resource, nil
+}
+
+func (r *cachedResolver) key(ctx context.Context, reference string) string {
+	return keyOf(ctx, r.resolver, reference)
+}
+
+func (r *cachedResolver) lookup(key string) (any, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if r.ttl > 0 && !time.Now().Before(entry.expires) {
+		r.order.Remove(element)
+		delete(r.entries, key)
+		return nil, false
+	}
+	r.order.MoveToFront(element)
+	return entry.resource, true
+}
+
+func (r *cachedResolver) store(key string, resource any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := &cacheEntry{
+		key:      key,
+		resource: resource,
+		expires:  time.Now().Add(r.ttl),
+	}
+	if element, ok := r.entries[key]; ok {
+		element.Value = entry
+		r.order.MoveToFront(element)
+		return
+	}
+	r.entries[key] = r.order.PushFront(entry)
+	for r.size > 0 && r.order.Len() > r.size {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
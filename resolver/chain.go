@@ -0,0 +1,55 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Chain returns a [Resolver] that tries each of the resolvers in order, and
+// returns the first resource that is resolved.
+//
+// A resolver that fails does not prevent the next resolver from being tried.
+// If no resolver resolves the reference, the errors of any that failed are
+// returned; otherwise, if none failed, nil is returned without an error.
+func Chain(resolvers ...Resolver) Resolver {
+	return &chainResolver{resolvers: resolvers}
+}
+
+type chainResolver struct {
+	resolvers []Resolver
+	BaseResolver
+}
+
+// Resolve resolves the reference with each resolver in turn.
+func (r *chainResolver) Resolve(ctx context.Context, reference string) (any, error) {
+	var errs []error
+	for _, resolver := range r.resolvers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		resource, err := resolver.Resolve(ctx, reference)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if resource != nil {
+			return resource, nil
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// key identifies the resolution by the keys of each of the resolvers.
+func (r *chainResolver) key(ctx context.Context, reference string) string {
+	keys := make([]string, 0, len(r.resolvers))
+	for _, resolver := range r.resolvers {
+		keys = append(keys, keyOf(ctx, resolver, reference))
+	}
+	for _, key := range keys {
+		if key != reference {
+			return strings.Join(keys, "\x00")
+		}
+	}
+	return reference
+}
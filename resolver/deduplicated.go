@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+)
+
+// Deduplicated returns a [Resolver] that collapses concurrent resolutions of
+// the same reference into a single call to r, sharing its result with every
+// caller.
+//
+// A caller whose context is cancelled stops waiting for the result without
+// affecting the other callers. The shared call is only cancelled once every
+// caller waiting on it has stopped waiting.
+func Deduplicated(r Resolver) Resolver {
+	return &deduplicatedResolver{
+		resolver: r,
+		calls:    map[string]*call{},
+	}
+}
+
+type deduplicatedResolver struct {
+	resolver Resolver
+
+	mu    sync.Mutex
+	calls map[string]*call
+	BaseResolver
+}
+
+// call is a single resolution that is shared by one or more callers.
+type call struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	resource any
+	err      error
+}
+
+// Resolve resolves the reference, joining a resolution of the same reference
+// that is already in progress if there is one.
+func (r *deduplicatedResolver) Resolve(ctx context.Context, reference string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	key := keyOf(ctx, r.resolver, reference)
+	r.mu.Lock()
+	c, ok := r.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{done: make(chan struct{}), cancel: cancel}
+		r.calls[key] = c
+		go r.resolve(callCtx, reference, key, c)
+	}
+	c.waiters++
+	r.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.resource, c.err
+	case <-ctx.Done():
+		r.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 && r.calls[key] == c {
+			delete(r.calls, key)
+			c.cancel()
+		}
+		r.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (r *deduplicatedResolver) resolve(ctx context.Context, reference, key string, c *call) {
+	defer c.cancel()
+	c.resource, c.err = r.resolver.Resolve(ctx, reference)
+
+	r.mu.Lock()
+	if r.calls[key] == c {
+		delete(r.calls, key)
+	}
+	r.mu.Unlock()
+	close(c.done)
+}
+
+func (r *deduplicatedResolver) key(ctx context.Context, reference string) string {
+	return keyOf(ctx, r.resolver, reference)
+}
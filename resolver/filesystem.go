@@ -0,0 +1,218 @@
+package resolver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/resources"
+)
+
+// FromDirectory returns a [Resolver] that resolves references to the resources
+// stored in the directory dir, as described in [FromFS].
+func FromDirectory(dir string) (Resolver, error) {
+	return FromFS(os.DirFS(dir))
+}
+
+// FromFS returns a [Resolver] that resolves references to the resources stored
+// in a filesystem, such as the output of a FHIR Bulk Data export.
+//
+// Every '.json' file in the filesystem is read as a single resource, and every
+// '.ndjson' file as one resource per line. Files and lines that are not valid
+// JSON are skipped. The files are indexed when the resolver is created, but the
+// resources are only decoded once they are resolved.
+//
+// Resources are indexed by their relative 'Type/id', and by their
+// 'Type/id/_history/versionId' if they have a 'meta.versionId'. Canonical
+// resources are also indexed by their 'url' and 'url|version'. An absolute
+// reference resolves to the resource with the same 'Type/id' on any base.
+// A version-specific reference, whether by '_history' or by 'url|version',
+// only resolves to a resource of that version. If several versions of a
+// resource are indexed, a reference without a version resolves to the first
+// one indexed.
+//
+// If no resource matches the reference, nil is returned without an error.
+func FromFS(fsys fs.FS) (Resolver, error) {
+	r := &fsResolver{fsys: fsys, index: map[string]*fsEntry{}}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch path.Ext(name) {
+		case ".json":
+			return r.indexJSON(name)
+		case ".ndjson":
+			return r.indexNDJSON(name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type fsResolver struct {
+	fsys  fs.FS
+	index map[string]*fsEntry
+	BaseResolver
+}
+
+// fsEntry is the location of a single resource within a file. A length of -1
+// refers to the whole file.
+type fsEntry struct {
+	name   string
+	offset int64
+	length int
+
+	once     sync.Once
+	resource any
+	err      error
+}
+
+func (r *fsResolver) indexJSON(name string) error {
+	data, err := fs.ReadFile(r.fsys, name)
+	if err != nil {
+		return err
+	}
+	r.add(data, &fsEntry{name: name, length: -1})
+	return nil
+}
+
+func (r *fsResolver) indexNDJSON(name string) error {
+	file, err := r.fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			r.add(line, &fsEntry{name: name, offset: offset, length: len(line)})
+		}
+		offset += int64(len(line))
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// add indexes the resource in data by each of its identities. Data that is
+// not a JSON object is skipped.
+func (r *fsResolver) add(data []byte, entry *fsEntry) {
+	var header resources.Header
+	if err := json.Unmarshal(data, &header); err != nil {
+		return
+	}
+	keys := []string{}
+	if header.ResourceType != "" && header.ID != "" {
+		local := header.ResourceType + "/" + header.ID
+		keys = append(keys, local)
+		if version := header.Meta.VersionID; version != "" {
+			keys = append(keys, local+"/_history/"+version)
+		}
+	}
+	if header.URL != "" {
+		keys = append(keys, header.URL)
+		if header.Version != "" {
+			keys = append(keys, header.URL+"|"+header.Version)
+		}
+	}
+	for _, key := range keys {
+		if _, ok := r.index[key]; !ok {
+			r.index[key] = entry
+		}
+	}
+}
+
+// Resolve resolves the reference to the indexed resource, decoding it if it
+// has not already been decoded.
+func (r *fsResolver) Resolve(ctx context.Context, reference string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entry, ok := r.lookup(reference)
+	if !ok {
+		return nil, nil
+	}
+	entry.once.Do(func() {
+		entry.resource, entry.err = r.load(entry)
+	})
+	return entry.resource, entry.err
+}
+
+// lookup finds the entry that the reference refers to, ignoring the base of
+// an absolute reference.
+func (r *fsResolver) lookup(reference string) (*fsEntry, bool) {
+	if entry, ok := r.index[reference]; ok {
+		return entry, true
+	}
+	if strings.Contains(reference, "|") {
+		return nil, false
+	}
+	reference, version, versioned := strings.Cut(reference, "/_history/")
+	segments := strings.Split(reference, "/")
+	if len(segments) < 2 {
+		return nil, false
+	}
+	key := strings.Join(segments[len(segments)-2:], "/")
+	if versioned {
+		key += "/_history/" + version
+	}
+	entry, ok := r.index[key]
+	return entry, ok
+}
+
+func (r *fsResolver) load(entry *fsEntry) (any, error) {
+	data, err := r.read(entry)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := resources.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", entry.name, err)
+	}
+	return resource, nil
+}
+
+// read reads the data of the entry, without reading the rest of its file if
+// the file supports it.
+func (r *fsResolver) read(entry *fsEntry) ([]byte, error) {
+	if entry.length < 0 {
+		return fs.ReadFile(r.fsys, entry.name)
+	}
+	file, err := r.fsys.Open(entry.name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if file, ok := file.(io.ReaderAt); ok {
+		data := make([]byte, entry.length)
+		n, err := file.ReadAt(data, entry.offset)
+		if n == len(data) {
+			return data, nil
+		}
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return data[entry.offset : entry.offset+int64(entry.length)], nil
+}
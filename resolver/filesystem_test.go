@@ -0,0 +1,192 @@
+package resolver_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/observation"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/structuredefinition"
+	"github.com/friendly-fhir/go-fhirpath/resolver"
+)
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Patient.ndjson": {Data: []byte(
+			`{"resourceType":"Patient","id":"1","meta":{"versionId":"4"},"gender":"male"}` + "\n" +
+				"\n" +
+				`{"resourceType":"Patient","id":"2","gender":"female"}` + "\n",
+		)},
+		"Observation.ndjson": {Data: []byte(
+			`{"resourceType":"Observation","id":"3","status":"final"}`,
+		)},
+		"profiles/vitals.json": {Data: []byte(
+			`{"resourceType":"StructureDefinition","id":"vitals","url":"http://example.com/vitals","version":"1.0.0","name":"Vitals"}`,
+		)},
+		"profiles/vitals-2.json": {Data: []byte(
+			`{"resourceType":"StructureDefinition","id":"vitals-2","url":"http://example.com/vitals","version":"2.0.0","name":"Vitals2"}`,
+		)},
+		"README.md": {Data: []byte("not a resource")},
+	}
+	testCases := []struct {
+		name      string
+		reference string
+		want      string
+	}{
+		{
+			name:      "Relative reference in NDJSON",
+			reference: "Patient/2",
+			want:      "female",
+		}, {
+			name:      "Absolute reference in NDJSON",
+			reference: "http://example.com/fhir/Patient/1",
+			want:      "male",
+		}, {
+			name:      "Versioned reference in NDJSON",
+			reference: "Patient/1/_history/4",
+			want:      "male",
+		}, {
+			name:      "NDJSON without trailing newline",
+			reference: "Observation/3",
+			want:      "final",
+		}, {
+			name:      "Canonical reference with version",
+			reference: "http://example.com/vitals|2.0.0",
+			want:      "Vitals2",
+		}, {
+			name:      "Canonical reference without version",
+			reference: "http://example.com/vitals",
+			want:      "Vitals2",
+		}, {
+			name:      "Relative reference in JSON",
+			reference: "StructureDefinition/vitals",
+			want:      "Vitals",
+		},
+	}
+
+	r, err := resolver.FromFS(fsys)
+	if err != nil {
+		t.Fatalf("FromFS() error = %v", err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), tc.reference)
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tc.reference, err)
+			}
+
+			if got, want := nameOf(t, got), tc.want; got != want {
+				t.Errorf("Resolve(%q) = %v; want %v", tc.reference, got, want)
+			}
+		})
+	}
+}
+
+// nameOf returns a distinguishing field of the resolved test resource.
+func nameOf(t *testing.T, resource any) string {
+	t.Helper()
+	switch resource := resource.(type) {
+	case *patient.Patient:
+		return resource.Gender.Value
+	case *observation.Observation:
+		return resource.Status.Value
+	case *structuredefinition.StructureDefinition:
+		return resource.Name.Value
+	}
+	t.Fatalf("unexpected resource %T", resource)
+	return ""
+}
+
+func TestFromFS_UnknownReference_ReturnsNil(t *testing.T) {
+	r, err := resolver.FromFS(fstest.MapFS{
+		"Patient.ndjson": {Data: []byte(`{"resourceType":"Patient","id":"1"}`)},
+	})
+	if err != nil {
+		t.Fatalf("FromFS() error = %v", err)
+	}
+
+	got, err := r.Resolve(context.Background(), "Patient/2")
+
+	if err != nil || got != nil {
+		t.Errorf("Resolve() = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestFromFS_UnknownResourceType_ReturnsError(t *testing.T) {
+	r, err := resolver.FromFS(fstest.MapFS{
+		"Widget.ndjson": {Data: []byte(`{"resourceType":"Widget","id":"1"}`)},
+	})
+	if err != nil {
+		t.Fatalf("FromFS() error = %v", err)
+	}
+
+	_, err = r.Resolve(context.Background(), "Widget/1")
+
+	if err == nil {
+		t.Errorf("Resolve() error = nil; want error")
+	}
+}
+
+func TestFromFS_OtherVersion_ReturnsNil(t *testing.T) {
+	r, err := resolver.FromFS(fstest.MapFS{
+		"Patient.ndjson": {Data: []byte(
+			`{"resourceType":"Patient","id":"1","meta":{"versionId":"4"}}` + "\n" +
+				`{"resourceType":"Patient","id":"2"}` + "\n",
+		)},
+		"vitals.json": {Data: []byte(
+			`{"resourceType":"StructureDefinition","id":"vitals","url":"http://example.com/fhir/StructureDefinition/vitals","version":"1.0.0"}`,
+		)},
+	})
+	if err != nil {
+		t.Fatalf("FromFS() error = %v", err)
+	}
+	testCases := []struct {
+		name      string
+		reference string
+	}{
+		{
+			name:      "History of other version",
+			reference: "Patient/1/_history/3",
+		}, {
+			name:      "History of resource without version",
+			reference: "Patient/2/_history/1",
+		}, {
+			name:      "Canonical of other version",
+			reference: "http://example.com/fhir/StructureDefinition/vitals|2.0.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), tc.reference)
+
+			if err != nil || got != nil {
+				t.Errorf("Resolve(%q) = %v, %v; want nil, nil", tc.reference, got, err)
+			}
+		})
+	}
+}
+
+func TestFromFS_InvalidJSON_IsSkipped(t *testing.T) {
+	r, err := resolver.FromFS(fstest.MapFS{
+		"Patient.ndjson": {Data: []byte(
+			`{"resourceType":` + "\n" +
+				`{"resourceType":"Patient","id":"1","gender":"male"}` + "\n",
+		)},
+		"package.json": {Data: []byte(`["not", "a", "resource"]`)},
+		"broken.json":  {Data: []byte(`{`)},
+	})
+	if err != nil {
+		t.Fatalf("FromFS() error = %v", err)
+	}
+
+	got, err := r.Resolve(context.Background(), "Patient/1")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if got, want := nameOf(t, got), "male"; got != want {
+		t.Errorf("Resolve() = %v; want %v", got, want)
+	}
+}
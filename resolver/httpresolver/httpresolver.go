@@ -0,0 +1,201 @@
+/*
+Package httpresolver provides a [resolver.Resolver] that resolves references by
+reading resources from a FHIR server with the FHIR REST API.
+*/
+package httpresolver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/friendly-fhir/go-fhirpath/internal/fhirclient"
+	"github.com/friendly-fhir/go-fhirpath/internal/model"
+	"github.com/friendly-fhir/go-fhirpath/internal/resources"
+	"github.com/friendly-fhir/go-fhirpath/resolver"
+)
+
+// ContentType is the media type of the FHIR JSON format, which is requested
+// from the server.
+const ContentType = fhirclient.ContentType
+
+// Option is an option that configures the resolver.
+type Option interface {
+	apply(*Resolver)
+}
+
+type option func(*Resolver)
+
+func (f option) apply(r *Resolver) {
+	f(r)
+}
+
+// WithClient returns an [Option] that configures the resolver to send requests
+// with the specified client.
+//
+// By default, without this specified, [http.DefaultClient] is used.
+func WithClient(client *http.Client) Option {
+	return option(func(r *Resolver) {
+		r.client.HTTP = client
+	})
+}
+
+// WithAuthHeader returns an [Option] that configures the resolver to set the
+// Authorization header of each request to the value returned by fn. This is
+// called for every request, so that credentials such as bearer tokens may be
+// refreshed as they expire.
+func WithAuthHeader(fn func(ctx context.Context) (string, error)) Option {
+	return option(func(r *Resolver) {
+		r.client.Auth = fn
+	})
+}
+
+// Resolver is a [resolver.Resolver] that reads resources from a FHIR server.
+//
+// References relative to the server, or absolute references under its base
+// URL, are read from the server with 'GET [base]/Type/id'. Version-specific
+// references are read with 'GET [base]/Type/id/_history/vid'. Only references
+// of exactly this form, with a known resource type and a valid id, are read;
+// others, such as conditional references like 'Patient?identifier=x', are
+// resolved to nil without a request.
+//
+// Other absolute references are treated as canonical references if the Type
+// that is taken from the URL is of a canonical resource, which has a 'url'
+// element, and are searched for with 'GET [base]/Type?url=...'. Absolute
+// references to resources of other types, which are held by other servers,
+// are resolved to nil without a request.
+//
+// A resource that the server does not have is resolved to nil, without an
+// error. If the server otherwise fails a request, or responds with an
+// OperationOutcome in place of the resource, an *[Error] is returned.
+type Resolver struct {
+	client fhirclient.Client
+	resolver.BaseResolver
+}
+
+// New returns a [Resolver] for the FHIR server at the base URL.
+func New(base string, opts ...Option) *Resolver {
+	r := &Resolver{
+		client: fhirclient.Client{Base: strings.TrimSuffix(base, "/")},
+	}
+	for _, opt := range opts {
+		opt.apply(r)
+	}
+	return r
+}
+
+// Resolve reads the referenced resource from the server.
+func (r *Resolver) Resolve(ctx context.Context, reference string) (any, error) {
+	if strings.HasPrefix(reference, "#") {
+		return nil, nil
+	}
+	if path, ok := strings.CutPrefix(reference, r.client.Base+"/"); ok {
+		return r.read(ctx, path)
+	}
+	if strings.Contains(reference, ":") {
+		return r.search(ctx, reference)
+	}
+	return r.read(ctx, reference)
+}
+
+// read reads the resource at the path relative to the base URL, if the path
+// is that of a resource or a version of it.
+func (r *Resolver) read(ctx context.Context, path string) (any, error) {
+	if !isResourcePath(path) {
+		return nil, nil
+	}
+	data, err := r.client.Get(ctx, path)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return resources.Decode(data)
+}
+
+// idPattern matches a valid FHIR id.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9\-.]{1,64}$`)
+
+// isResourcePath returns whether the path is of the form 'Type/id' or
+// 'Type/id/_history/vid', with a known resource type and valid ids.
+func isResourcePath(path string) bool {
+	segments := strings.Split(path, "/")
+	switch {
+	case len(segments) == 2:
+	case len(segments) == 4 && segments[2] == "_history":
+		if !isID(segments[3]) {
+			return false
+		}
+	default:
+		return false
+	}
+	if _, ok := resources.New(segments[0]); !ok {
+		return false
+	}
+	return isID(segments[1])
+}
+
+// isID returns whether the segment is a valid FHIR id, which may not be a dot
+// segment that would change the path that is requested.
+func isID(segment string) bool {
+	return idPattern.MatchString(segment) && segment != "." && segment != ".."
+}
+
+// search searches for the canonical resource with the canonical URL, which
+// may be qualified by a version as 'url|version'.
+func (r *Resolver) search(ctx context.Context, canonical string) (any, error) {
+	canonical, version, _ := strings.Cut(canonical, "|")
+	if strings.HasPrefix(canonical, "urn:") {
+		return nil, nil
+	}
+	segments := strings.Split(canonical, "/")
+	if len(segments) < 2 {
+		return nil, nil
+	}
+	resourceType := segments[len(segments)-2]
+	if !isCanonicalType(resourceType) {
+		return nil, nil
+	}
+
+	query := url.Values{"url": {canonical}}
+	if version != "" {
+		query.Set("version", version)
+	}
+	data, err := r.client.Get(ctx, resourceType+"?"+query.Encode())
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var bundle struct {
+		Entry []struct {
+			Resource json.RawMessage `json:"resource"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, err
+	}
+	for _, entry := range bundle.Entry {
+		if entry.Resource != nil {
+			return resources.Decode(entry.Resource)
+		}
+	}
+	return nil, nil
+}
+
+// isCanonicalType returns whether the resource type is known, and has a 'url'
+// element by which it may be referenced.
+func isCanonicalType(resourceType string) bool {
+	resource, ok := resources.New(resourceType)
+	if !ok {
+		return false
+	}
+	_, ok = model.Lookup(reflect.TypeOf(resource), "url")
+	return ok
+}
+
+// Error is an error returned by the server when it fails a request.
+type Error = fhirclient.Error
+
+var _ resolver.Resolver = (*Resolver)(nil)
@@ -0,0 +1,265 @@
+package httpresolver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/structuredefinition"
+	"github.com/friendly-fhir/go-fhirpath/resolver/httpresolver"
+)
+
+// newServer returns a test FHIR server that responds to each request URI with
+// the status and body in responses, and with 404 for all other requests.
+func newServer(t *testing.T, responses map[string]response) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got, want := req.Header.Get("Accept"), httpresolver.ContentType; got != want {
+			t.Errorf("Accept = %v; want %v", got, want)
+		}
+		resp, ok := responses[req.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", httpresolver.ContentType)
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+type response struct {
+	status int
+	body   string
+}
+
+func TestResolver(t *testing.T) {
+	server := newServer(t, map[string]response{
+		"/fhir/Patient/123":            {http.StatusOK, `{"resourceType":"Patient","id":"123","gender":"male"}`},
+		"/fhir/Patient/123/_history/2": {http.StatusOK, `{"resourceType":"Patient","id":"123","gender":"female"}`},
+		"/fhir/StructureDefinition?url=http%3A%2F%2Fexample.com%2FStructureDefinition%2Fvitals": {http.StatusOK,
+			`{"resourceType":"Bundle","type":"searchset","entry":[{"resource":{"resourceType":"StructureDefinition","id":"vitals","name":"Vitals"}}]}`},
+		"/fhir/StructureDefinition?url=http%3A%2F%2Fexample.com%2FStructureDefinition%2Fvitals&version=2": {http.StatusOK,
+			`{"resourceType":"Bundle","type":"searchset","entry":[{"resource":{"resourceType":"StructureDefinition","id":"vitals-2","name":"Vitals2"}}]}`},
+		"/fhir/StructureDefinition?url=http%3A%2F%2Fexample.com%2FStructureDefinition%2Funknown": {http.StatusOK,
+			`{"resourceType":"Bundle","type":"searchset"}`},
+	})
+	testCases := []struct {
+		name      string
+		reference string
+		want      string
+	}{
+		{
+			name:      "Relative reference",
+			reference: "Patient/123",
+			want:      "male",
+		}, {
+			name:      "Absolute reference on server",
+			reference: server.URL + "/fhir/Patient/123",
+			want:      "male",
+		}, {
+			name:      "Versioned reference",
+			reference: "Patient/123/_history/2",
+			want:      "female",
+		}, {
+			name:      "Canonical reference",
+			reference: "http://example.com/StructureDefinition/vitals",
+			want:      "Vitals",
+		}, {
+			name:      "Canonical reference with version",
+			reference: "http://example.com/StructureDefinition/vitals|2",
+			want:      "Vitals2",
+		}, {
+			name:      "Canonical reference not found",
+			reference: "http://example.com/StructureDefinition/unknown",
+			want:      "",
+		}, {
+			name:      "Resource not found",
+			reference: "Patient/456",
+			want:      "",
+		}, {
+			name:      "Contained reference",
+			reference: "#123",
+			want:      "",
+		}, {
+			name:      "UUID reference",
+			reference: "urn:uuid:04121321-4af5-424c-a0e1-ed3aab1c349d",
+			want:      "",
+		},
+	}
+
+	r := httpresolver.New(server.URL + "/fhir/")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), tc.reference)
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tc.reference, err)
+			}
+
+			var name string
+			switch got := got.(type) {
+			case *patient.Patient:
+				name = got.Gender.Value
+			case *structuredefinition.StructureDefinition:
+				name = got.Name.Value
+			}
+			if got, want := name, tc.want; got != want {
+				t.Errorf("Resolve(%q) = %v; want %v", tc.reference, got, want)
+			}
+		})
+	}
+}
+
+func TestResolver_InvalidReference_ReturnsNil(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Errorf("unexpected request %v", req.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	testCases := []struct {
+		name      string
+		reference string
+	}{
+		{
+			name:      "Conditional reference",
+			reference: "Patient?identifier=x",
+		}, {
+			name:      "Parent path segment",
+			reference: "../x",
+		}, {
+			name:      "Parent path segment as id",
+			reference: "Patient/..",
+		}, {
+			name:      "Parent path segment under base",
+			reference: server.URL + "/fhir/../admin/x",
+		}, {
+			name:      "Unknown resource type",
+			reference: "Widget/1",
+		}, {
+			name:      "Query in id",
+			reference: "Patient/1?_format=xml",
+		}, {
+			name:      "Operation",
+			reference: "Patient/1/$everything",
+		}, {
+			name:      "Invalid version",
+			reference: "Patient/1/_history/../2",
+		}, {
+			name:      "Absolute reference on other server",
+			reference: "http://other.example.com/fhir/Patient/1",
+		}, {
+			name:      "Canonical of unknown type",
+			reference: "http://example.com/Widget/1",
+		},
+	}
+
+	r := httpresolver.New(server.URL+"/fhir", httpresolver.WithClient(server.Client()))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), tc.reference)
+
+			if err != nil || got != nil {
+				t.Errorf("Resolve(%q) = %v, %v; want nil, nil", tc.reference, got, err)
+			}
+		})
+	}
+}
+
+func TestResolver_OperationOutcome_ReturnsError(t *testing.T) {
+	server := newServer(t, map[string]response{
+		"/Patient/123": {http.StatusForbidden, `{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"forbidden","diagnostics":"access denied"}]}`},
+	})
+	r := httpresolver.New(server.URL)
+
+	_, err := r.Resolve(context.Background(), "Patient/123")
+
+	var httpErr *httpresolver.Error
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Resolve() error = %v; want *httpresolver.Error", err)
+	}
+	if got, want := httpErr.StatusCode, http.StatusForbidden; got != want {
+		t.Errorf("Error.StatusCode = %v; want %v", got, want)
+	}
+	if httpErr.Outcome == nil || len(httpErr.Outcome.Issue) != 1 {
+		t.Fatalf("Error.Outcome = %v; want 1 issue", httpErr.Outcome)
+	}
+	if got, want := err.Error(), "fhir server: 403 Forbidden: access denied"; got != want {
+		t.Errorf("Error() = %v; want %v", got, want)
+	}
+}
+
+func TestResolver_SuccessfulOperationOutcome_ReturnsError(t *testing.T) {
+	outcome := `{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"processing","diagnostics":"search failed"}]}`
+	server := newServer(t, map[string]response{
+		"/Patient/123": {http.StatusOK, outcome},
+		"/StructureDefinition?url=http%3A%2F%2Fexample.com%2FStructureDefinition%2Fvitals": {http.StatusOK, outcome},
+	})
+	r := httpresolver.New(server.URL)
+
+	for _, reference := range []string{"Patient/123", "http://example.com/StructureDefinition/vitals"} {
+		_, err := r.Resolve(context.Background(), reference)
+
+		var httpErr *httpresolver.Error
+		if !errors.As(err, &httpErr) || httpErr.Outcome == nil {
+			t.Errorf("Resolve(%q) error = %v; want *httpresolver.Error with outcome", reference, err)
+		}
+	}
+}
+
+func TestResolver_ServerErrorWithoutOutcome_ReturnsError(t *testing.T) {
+	server := newServer(t, map[string]response{
+		"/Patient/123": {http.StatusInternalServerError, `oops`},
+	})
+	r := httpresolver.New(server.URL)
+
+	_, err := r.Resolve(context.Background(), "Patient/123")
+
+	var httpErr *httpresolver.Error
+	if !errors.As(err, &httpErr) || httpErr.Outcome != nil {
+		t.Errorf("Resolve() error = %v; want *httpresolver.Error without outcome", err)
+	}
+}
+
+func TestResolver_AuthHeader(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	r := httpresolver.New(server.URL,
+		httpresolver.WithClient(server.Client()),
+		httpresolver.WithAuthHeader(func(context.Context) (string, error) {
+			return "Bearer token", nil
+		}),
+	)
+
+	_, err := r.Resolve(context.Background(), "Patient/123")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if want := "Bearer token"; got != want {
+		t.Errorf("Authorization = %v; want %v", got, want)
+	}
+}
+
+func TestResolver_AuthHeaderError_ReturnsError(t *testing.T) {
+	want := errors.New("token expired")
+	r := httpresolver.New("http://example.com",
+		httpresolver.WithAuthHeader(func(context.Context) (string, error) {
+			return "", want
+		}),
+	)
+
+	_, err := r.Resolve(context.Background(), "Patient/123")
+
+	if got := err; !errors.Is(got, want) {
+		t.Errorf("Resolve() error = %v; want %v", got, want)
+	}
+}
@@ -47,3 +47,37 @@ func (BaseResolver) Resolve(ctx context.Context, reference string) (any, error)
 }
 
 func (BaseResolver) isResolver() {}
+
+type referrerKey struct{}
+
+// WithReferrer returns a context in which referrer is the element that holds
+// the reference being resolved, such as the Reference of an Observation's
+// subject. Resolvers may use it to resolve relative references against the
+// location of the element, as [FromBundle] does.
+func WithReferrer(ctx context.Context, referrer any) context.Context {
+	return context.WithValue(ctx, referrerKey{}, referrer)
+}
+
+// Referrer returns the element that holds the reference being resolved, if
+// one was set with [WithReferrer].
+func Referrer(ctx context.Context) (any, bool) {
+	referrer := ctx.Value(referrerKey{})
+	return referrer, referrer != nil
+}
+
+// keyer is implemented by resolvers whose resolution of a reference depends on
+// the context as well as on the reference, such as on its [Referrer]. The key
+// identifies the resolution, so that decorators that share resolutions, such
+// as [Cached] and [Deduplicated], only share them between equal keys.
+type keyer interface {
+	key(ctx context.Context, reference string) string
+}
+
+// keyOf returns the key that identifies the resolution of the reference by r,
+// which is the reference itself unless r depends on the context.
+func keyOf(ctx context.Context, r Resolver, reference string) string {
+	if k, ok := r.(keyer); ok {
+		return k.key(ctx, reference)
+	}
+	return reference
+}
@@ -0,0 +1,217 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/friendly-fhir/go-fhirpath/resolver"
+	"github.com/friendly-fhir/go-fhirpath/resolver/resolvertest"
+	"github.com/google/go-cmp/cmp"
+)
+
+// counter is a resolver that resolves every reference to itself, and counts
+// the number of times it is called.
+type counter struct {
+	calls atomic.Int32
+	resolver.BaseResolver
+}
+
+func (c *counter) Resolve(_ context.Context, reference string) (any, error) {
+	c.calls.Add(1)
+	return reference, nil
+}
+
+func TestChain(t *testing.T) {
+	errResolve := errors.New("resolve failed")
+	testCases := []struct {
+		name      string
+		resolvers []resolver.Resolver
+		want      any
+		wantErr   error
+	}{
+		{
+			name:      "First resolver resolves",
+			resolvers: []resolver.Resolver{resolvertest.Return("first"), resolvertest.Return("second")},
+			want:      "first",
+		}, {
+			name:      "Not found tries next resolver",
+			resolvers: []resolver.Resolver{resolvertest.Return(nil), resolvertest.Return("second")},
+			want:      "second",
+		}, {
+			name:      "Error tries next resolver",
+			resolvers: []resolver.Resolver{resolvertest.Error(errResolve), resolvertest.Return("second")},
+			want:      "second",
+		}, {
+			name:      "Not found by any resolver",
+			resolvers: []resolver.Resolver{resolvertest.Return(nil), resolvertest.Return(nil)},
+			want:      nil,
+		}, {
+			name:      "Error without resolution",
+			resolvers: []resolver.Resolver{resolvertest.Error(errResolve), resolvertest.Return(nil)},
+			wantErr:   errResolve,
+		}, {
+			name:      "No resolvers",
+			resolvers: nil,
+			want:      nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := resolver.Chain(tc.resolvers...)
+
+			got, err := r.Resolve(context.Background(), "Patient/1")
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Resolve() error = %v; want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Resolve() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestChain_CancelledContext_ReturnsError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := resolver.Chain(resolvertest.Return("first"))
+
+	_, err := r.Resolve(ctx, "Patient/1")
+
+	if got, want := err, context.Canceled; !errors.Is(got, want) {
+		t.Errorf("Resolve() error = %v; want %v", got, want)
+	}
+}
+
+func TestCached(t *testing.T) {
+	testCases := []struct {
+		name       string
+		size       int
+		ttl        time.Duration
+		references []string
+		wantCalls  int32
+	}{
+		{
+			name:       "Repeated reference is cached",
+			size:       2,
+			references: []string{"Patient/1", "Patient/1", "Patient/1"},
+			wantCalls:  1,
+		}, {
+			name:       "Least recently used reference is evicted",
+			size:       2,
+			references: []string{"Patient/1", "Patient/2", "Patient/3", "Patient/1"},
+			wantCalls:  4,
+		}, {
+			name:       "Recently used reference is not evicted",
+			size:       2,
+			references: []string{"Patient/1", "Patient/2", "Patient/1", "Patient/3", "Patient/1"},
+			wantCalls:  3,
+		}, {
+			name:       "Unbounded size",
+			size:       0,
+			references: []string{"Patient/1", "Patient/2", "Patient/3", "Patient/1"},
+			wantCalls:  3,
+		}, {
+			name:       "Expired reference is resolved again",
+			size:       2,
+			ttl:        time.Nanosecond,
+			references: []string{"Patient/1", "Patient/1"},
+			wantCalls:  2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &counter{}
+			r := resolver.Cached(c, tc.size, tc.ttl)
+
+			for _, reference := range tc.references {
+				got, err := r.Resolve(context.Background(), reference)
+				if err != nil {
+					t.Fatalf("Resolve(%q) error = %v", reference, err)
+				}
+				if got != reference {
+					t.Errorf("Resolve(%q) = %v; want %v", reference, got, reference)
+				}
+			}
+
+			if got, want := c.calls.Load(), tc.wantCalls; got != want {
+				t.Errorf("Resolve() calls = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCached_Error_IsNotCached(t *testing.T) {
+	var calls int
+	r := resolver.Cached(resolver.ResolverFunc(func(context.Context, string) (any, error) {
+		calls++
+		return nil, errors.New("resolve failed")
+	}), 2, 0)
+
+	_, _ = r.Resolve(context.Background(), "Patient/1")
+	_, _ = r.Resolve(context.Background(), "Patient/1")
+
+	if got, want := calls, 2; got != want {
+		t.Errorf("Resolve() calls = %v; want %v", got, want)
+	}
+}
+
+func TestDeduplicated_ConcurrentLookups_ResolveOnce(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := resolver.Deduplicated(resolver.ResolverFunc(func(_ context.Context, reference string) (any, error) {
+		calls.Add(1)
+		<-release
+		return reference, nil
+	}))
+
+	var wg sync.WaitGroup
+	results := make([]any, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = r.Resolve(context.Background(), "Patient/1")
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got, want := calls.Load(), int32(1); got != want {
+		t.Errorf("Resolve() calls = %v; want %v", got, want)
+	}
+	for _, got := range results {
+		if want := "Patient/1"; got != want {
+			t.Errorf("Resolve() = %v; want %v", got, want)
+		}
+	}
+}
+
+func TestDeduplicated_CancelledContext_CancelsLookup(t *testing.T) {
+	cancelled := make(chan struct{})
+	r := resolver.Deduplicated(resolver.ResolverFunc(func(ctx context.Context, _ string) (any, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond, cancel)
+
+	_, err := r.Resolve(ctx, "Patient/1")
+
+	if got, want := err, context.Canceled; !errors.Is(got, want) {
+		t.Errorf("Resolve() error = %v; want %v", got, want)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Errorf("Resolve() did not cancel the lookup")
+	}
+}
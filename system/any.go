@@ -9,8 +9,8 @@ type Any interface {
 // name value. This function is case-sensitive.
 func IsType(ty string) bool {
 	switch ty {
-	case "Boolean", "Integer", "Any", "Date", "DateTime", "Decimal", "Quantity",
-		"String", "Time":
+	case "Boolean", "Integer", "Long", "Any", "Date", "DateTime", "Decimal",
+		"Quantity", "String", "Time":
 		return true
 	}
 	return false
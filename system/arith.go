@@ -0,0 +1,233 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidOperands is an error raised when an arithmetic operator is applied
+// to values whose types it is not defined for, such as adding a Boolean to an
+// Integer.
+var ErrInvalidOperands = errors.New("invalid operands")
+
+// ErrOverflow is an error raised when the result of an arithmetic operation on
+// Integer or Integer64 values does not fit within the range of the type.
+var ErrOverflow = errors.New("overflow")
+
+// Add adds two FHIRPath values, following the semantics of the FHIRPath '+'
+// operator. Numbers are added, and Strings are concatenated.
+//
+// Integers are implicitly promoted to Integer64, and Integer64 values are
+// implicitly promoted to Decimal, when combined with those types.
+//
+// If the values are of types that cannot be added, an error wrapping
+// ErrInvalidOperands is returned.
+func Add(lhs, rhs any) (result Any, ok bool, err error) {
+	lhs, rhs = Normalize(lhs), Normalize(rhs)
+	if l, ok := lhs.(String); ok {
+		if r, ok := rhs.(String); ok {
+			return l + r, true, nil
+		}
+	}
+	return arithmetic(lhs, rhs, numericOps{
+		integer: (*big.Int).Add,
+		decimal: decimal.Decimal.Add,
+	})
+}
+
+// Subtract subtracts two FHIRPath numbers, following the semantics of the
+// FHIRPath '-' operator. Numeric types are promoted as described in [Add].
+func Subtract(lhs, rhs any) (result Any, ok bool, err error) {
+	return arithmetic(Normalize(lhs), Normalize(rhs), numericOps{
+		integer: (*big.Int).Sub,
+		decimal: decimal.Decimal.Sub,
+	})
+}
+
+// Multiply multiplies two FHIRPath numbers, following the semantics of the
+// FHIRPath '*' operator. Numeric types are promoted as described in [Add].
+func Multiply(lhs, rhs any) (result Any, ok bool, err error) {
+	return arithmetic(Normalize(lhs), Normalize(rhs), numericOps{
+		integer: (*big.Int).Mul,
+		decimal: decimal.Decimal.Mul,
+	})
+}
+
+// Divide divides two FHIRPath numbers, following the semantics of the FHIRPath
+// '/' operator. The result is always a Decimal, even when both operands are
+// integers.
+//
+// If the divisor is zero, the result is undefined and ok is false.
+func Divide(lhs, rhs any) (result Any, ok bool, err error) {
+	l, r, err := promote(Normalize(lhs), Normalize(rhs))
+	if err != nil {
+		return nil, false, err
+	}
+	divisor := decimalOf(r)
+	if divisor.IsZero() {
+		return nil, false, nil
+	}
+	return Decimal(decimalOf(l).Div(divisor)), true, nil
+}
+
+// Div performs the truncated division of two FHIRPath numbers, following the
+// semantics of the FHIRPath 'div' operator. The result is an Integer, or an
+// Integer64 if either operand is an Integer64.
+//
+// If the divisor is zero, the result is undefined and ok is false.
+func Div(lhs, rhs any) (result Any, ok bool, err error) {
+	l, r, err := promote(Normalize(lhs), Normalize(rhs))
+	if err != nil {
+		return nil, false, err
+	}
+	if decimalOf(r).IsZero() {
+		return nil, false, nil
+	}
+	if d, ok := l.(Decimal); ok {
+		quotient := decimal.Decimal(d).Div(decimal.Decimal(r.(Decimal))).Truncate(0)
+		return integerOf(quotient.BigInt(), Integer(0))
+	}
+	return integerOf(new(big.Int).Quo(bigIntOf(l), bigIntOf(r)), l)
+}
+
+// Mod computes the remainder of the truncated division of two FHIRPath
+// numbers, following the semantics of the FHIRPath 'mod' operator. The sign
+// of the result follows the dividend. Numeric types are promoted as described
+// in [Add].
+//
+// If the divisor is zero, the result is undefined and ok is false.
+func Mod(lhs, rhs any) (result Any, ok bool, err error) {
+	l, r, err := promote(Normalize(lhs), Normalize(rhs))
+	if err != nil {
+		return nil, false, err
+	}
+	if decimalOf(r).IsZero() {
+		return nil, false, nil
+	}
+	if d, ok := l.(Decimal); ok {
+		return Decimal(decimal.Decimal(d).Mod(decimal.Decimal(r.(Decimal)))), true, nil
+	}
+	return integerOf(new(big.Int).Rem(bigIntOf(l), bigIntOf(r)), l)
+}
+
+// Negate negates a FHIRPath number or Quantity, following the semantics of
+// the FHIRPath unary '-' operator.
+func Negate(v any) (Any, error) {
+	switch v := Normalize(v).(type) {
+	case Integer:
+		if v == math.MinInt32 {
+			return nil, fmt.Errorf("%w of System.Integer", ErrOverflow)
+		}
+		return v.Negate(), nil
+	case Integer64:
+		if v == math.MinInt64 {
+			return nil, fmt.Errorf("%w of System.Long", ErrOverflow)
+		}
+		return v.Negate(), nil
+	case Decimal:
+		return Decimal(decimal.Decimal(v).Neg()), nil
+	case Quantity:
+		return NewQuantity(Decimal(decimal.Decimal(v.value).Neg()), v.unit), nil
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOperands, typeName(v))
+	}
+}
+
+// numericOps are the implementations of an arithmetic operator for each of
+// the numeric representations.
+type numericOps struct {
+	integer func(z, x, y *big.Int) *big.Int
+	decimal func(x, y decimal.Decimal) decimal.Decimal
+}
+
+// arithmetic applies a numeric operator to two normalized values, promoting
+// the values to a common numeric type first.
+func arithmetic(lhs, rhs any, ops numericOps) (Any, bool, error) {
+	l, r, err := promote(lhs, rhs)
+	if err != nil {
+		return nil, false, err
+	}
+	if d, ok := l.(Decimal); ok {
+		return Decimal(ops.decimal(decimal.Decimal(d), decimal.Decimal(r.(Decimal)))), true, nil
+	}
+	return integerOf(ops.integer(new(big.Int), bigIntOf(l), bigIntOf(r)), l)
+}
+
+// numericRank orders the numeric types by their implicit promotions. Values of
+// a lower rank are promoted to the type of a higher rank.
+func numericRank(v any) (int, bool) {
+	switch v.(type) {
+	case Integer:
+		return 0, true
+	case Integer64:
+		return 1, true
+	case Decimal:
+		return 2, true
+	}
+	return 0, false
+}
+
+// promote converts two numeric values into the same numeric type, following
+// the FHIRPath implicit conversions.
+func promote(lhs, rhs any) (l, r Any, err error) {
+	lr, lok := numericRank(lhs)
+	rr, rok := numericRank(rhs)
+	if !lok || !rok {
+		return nil, nil, fmt.Errorf("%w: %v and %v", ErrInvalidOperands, typeName(lhs), typeName(rhs))
+	}
+	return promoteTo(lhs, max(lr, rr)), promoteTo(rhs, max(lr, rr)), nil
+}
+
+func promoteTo(v any, rank int) Any {
+	switch rank {
+	case 0:
+		return v.(Integer)
+	case 1:
+		return Integer64(bigIntOf(v.(Any)).Int64())
+	}
+	return Decimal(decimalOf(v.(Any)))
+}
+
+func bigIntOf(v Any) *big.Int {
+	switch v := v.(type) {
+	case Integer:
+		return big.NewInt(int64(v))
+	case Integer64:
+		return big.NewInt(int64(v))
+	}
+	return nil
+}
+
+func decimalOf(v Any) decimal.Decimal {
+	switch v := v.(type) {
+	case Integer:
+		return decimal.NewFromInt32(int32(v))
+	case Integer64:
+		return decimal.NewFromInt(int64(v))
+	case Decimal:
+		return decimal.Decimal(v)
+	}
+	return decimal.Decimal{}
+}
+
+// integerOf converts the result of an integer operation into the same type as
+// like, which is either an Integer or an Integer64. If the result does not fit
+// in the type, an error wrapping ErrOverflow is returned.
+func integerOf(result *big.Int, like Any) (Any, bool, error) {
+	switch like.(type) {
+	case Integer:
+		if !result.IsInt64() || result.Int64() < math.MinInt32 || result.Int64() > math.MaxInt32 {
+			return nil, false, fmt.Errorf("%w of System.Integer", ErrOverflow)
+		}
+		return Integer(result.Int64()), true, nil
+	default:
+		if !result.IsInt64() {
+			return nil, false, fmt.Errorf("%w of System.Long", ErrOverflow)
+		}
+		return Integer64(result.Int64()), true, nil
+	}
+}
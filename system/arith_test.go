@@ -0,0 +1,107 @@
+package system_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestArithmetic(t *testing.T) {
+	testCases := []struct {
+		name string
+		fn   func(lhs, rhs any) (system.Any, bool, error)
+		lhs  any
+		rhs  any
+		want system.Any
+	}{
+		{"Add integers", system.Add, system.Integer(1), system.Integer(2), system.Integer(3)},
+		{"Add integer and integer64", system.Add, system.Integer(1), system.Integer64(2), system.Integer64(3)},
+		{"Add integer64 and decimal", system.Add, system.Integer64(1), system.MustParseDecimal("0.5"), system.MustParseDecimal("1.5")},
+		{"Add strings", system.Add, system.String("a"), system.String("b"), system.String("ab")},
+		{"Subtract integer64s", system.Subtract, system.Integer64(math.MaxInt64), system.Integer64(1), system.Integer64(math.MaxInt64 - 1)},
+		{"Multiply integer and integer64", system.Multiply, system.Integer(math.MaxInt32), system.Integer64(2), system.Integer64(2 * math.MaxInt32)},
+		{"Divide integers", system.Divide, system.Integer(1), system.Integer(2), system.MustParseDecimal("0.5")},
+		{"Div integers", system.Div, system.Integer(5), system.Integer(2), system.Integer(2)},
+		{"Div decimals", system.Div, system.MustParseDecimal("5.5"), system.MustParseDecimal("0.7"), system.Integer(7)},
+		{"Div integer64", system.Div, system.Integer64(7), system.Integer(2), system.Integer64(3)},
+		{"Mod integers", system.Mod, system.Integer(5), system.Integer(2), system.Integer(1)},
+		{"Mod negative integer", system.Mod, system.Integer(-5), system.Integer(2), system.Integer(-1)},
+		{"Mod decimals", system.Mod, system.MustParseDecimal("5.5"), system.MustParseDecimal("0.7"), system.MustParseDecimal("0.6")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok, err := tc.fn(tc.lhs, tc.rhs)
+			if err != nil {
+				t.Fatalf("error = %v; want nil", err)
+			}
+			if !ok {
+				t.Fatalf("ok = false; want true")
+			}
+
+			if !system.Equal(got, tc.want) {
+				t.Errorf("result = %v; want %v", got, tc.want)
+			}
+			if got, want := fmt.Sprintf("%T", got), fmt.Sprintf("%T", tc.want); got != want {
+				t.Errorf("result type = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestArithmetic_DivisionByZero_ReturnsNotOK(t *testing.T) {
+	testCases := []struct {
+		name string
+		fn   func(lhs, rhs any) (system.Any, bool, error)
+	}{
+		{"Divide", system.Divide},
+		{"Div", system.Div},
+		{"Mod", system.Mod},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ok, err := tc.fn(system.Integer(1), system.Integer(0))
+
+			if err != nil {
+				t.Fatalf("error = %v; want nil", err)
+			}
+			if ok {
+				t.Errorf("ok = true; want false")
+			}
+		})
+	}
+}
+
+func TestArithmetic_Overflow_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		fn   func(lhs, rhs any) (system.Any, bool, error)
+		lhs  any
+		rhs  any
+	}{
+		{"Integer", system.Add, system.Integer(math.MaxInt32), system.Integer(1)},
+		{"Integer64", system.Multiply, system.Integer64(math.MaxInt64), system.Integer(2)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := tc.fn(tc.lhs, tc.rhs)
+
+			if got, want := err, system.ErrOverflow; !errors.Is(got, want) {
+				t.Errorf("error = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestArithmetic_InvalidOperands_ReturnsError(t *testing.T) {
+	_, _, err := system.Add(system.Integer(1), system.String("1"))
+
+	if got, want := err, system.ErrInvalidOperands; !errors.Is(got, want) {
+		t.Errorf("Add() error = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,260 @@
+package system
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Boundaries
+//
+// The FHIRPath boundary functions express the precision of a value as the
+// number of digits it contains, rather than as a named unit. For example, a
+// DateTime precise to the minute (YYYY-MM-DDThh:mm) has a precision of 12,
+// and a Time precise to the millisecond (hh:mm:ss.fff) has a precision of 9.
+
+// dateTimeDigits are the digits of precision of each DateTimePrecision, as
+// used by the Date and DateTime boundary functions.
+var dateTimeDigits = [...]int{
+	PrecisionYear:        4,
+	PrecisionMonth:       6,
+	PrecisionDay:         8,
+	PrecisionHour:        10,
+	PrecisionMinute:      12,
+	PrecisionSecond:      14,
+	PrecisionMillisecond: 17,
+}
+
+// timeDigits are the digits of precision of each DateTimePrecision, as used
+// by the Time boundary functions. Time values are never less precise than an
+// hour.
+var timeDigits = [...]int{
+	PrecisionHour:        2,
+	PrecisionMinute:      4,
+	PrecisionSecond:      6,
+	PrecisionMillisecond: 9,
+}
+
+// precisionOfDigits finds the DateTimePrecision that has the specified digits
+// of precision in the table.
+func precisionOfDigits(table []int, digits int, from DateTimePrecision) (DateTimePrecision, bool) {
+	for p := from; int(p) < len(table); p++ {
+		if table[p] == digits {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// MaxDecimalDigits is the greatest number of decimal places that may be
+// requested from the boundary functions of a System.Decimal.
+const MaxDecimalDigits = 28
+
+// Digits returns the number of digits after the decimal point of this
+// decimal, as it was specified. For example, 1.58700 has 5 digits.
+func (d Decimal) Digits() int {
+	return max(0, -int(decimal.Decimal(d).Exponent()))
+}
+
+// LowBoundary returns the least possible value of this decimal with the
+// specified number of digits after the decimal point. The value of the decimal
+// is treated as uncertain beyond its own digits, so 1.587 has a low boundary
+// of 1.5865 at 4 digits.
+//
+// If digits is negative or greater than [MaxDecimalDigits], ok is false.
+func (d Decimal) LowBoundary(digits int) (result Decimal, ok bool) {
+	return d.boundary(digits, decimal.NewFromFloat(-0.5), decimal.Decimal.Floor)
+}
+
+// HighBoundary returns the greatest possible value of this decimal with the
+// specified number of digits after the decimal point. The value of the decimal
+// is treated as uncertain beyond its own digits, so 1.587 has a high boundary
+// of 1.5875 at 4 digits.
+//
+// If digits is negative or greater than [MaxDecimalDigits], ok is false.
+func (d Decimal) HighBoundary(digits int) (result Decimal, ok bool) {
+	return d.boundary(digits, decimal.NewFromFloat(0.5), decimal.Decimal.Ceil)
+}
+
+func (d Decimal) boundary(digits int, offset decimal.Decimal, round func(decimal.Decimal) decimal.Decimal) (Decimal, bool) {
+	if digits < 0 || digits > MaxDecimalDigits {
+		return Decimal{}, false
+	}
+	// The uncertainty of the value is half of its least significant digit.
+	uncertainty := offset.Shift(-int32(d.Digits()))
+	value := decimal.Decimal(d).Add(uncertainty)
+
+	coefficient := round(value.Shift(int32(digits))).BigInt()
+	return Decimal(decimal.NewFromBigInt(coefficient, -int32(digits))), true
+}
+
+// Digits returns the number of digits of precision of this date: 4 for a
+// year, 6 for a month, and 8 for a day.
+func (d Date) Digits() int {
+	return dateTimeDigits[d.precision]
+}
+
+// LowBoundary returns the earliest possible date within the period of this
+// date, to the specified digits of precision (4, 6, or 8). For example, the
+// low boundary of @2014 at 6 digits is @2014-01.
+//
+// If digits is not a valid Date precision, ok is false.
+func (d Date) LowBoundary(digits int) (result Date, ok bool) {
+	dt, ok := d.DateTime().LowBoundary(digits)
+	if !ok || dt.precision > PrecisionDay {
+		return Date{}, false
+	}
+	return dt.Date(), true
+}
+
+// HighBoundary returns the latest possible date within the period of this
+// date, to the specified digits of precision (4, 6, or 8). For example, the
+// high boundary of @2014 at 6 digits is @2014-12.
+//
+// If digits is not a valid Date precision, ok is false.
+func (d Date) HighBoundary(digits int) (result Date, ok bool) {
+	dt, ok := d.DateTime().HighBoundary(digits)
+	if !ok || dt.precision > PrecisionDay {
+		return Date{}, false
+	}
+	return dt.Date(), true
+}
+
+// Digits returns the number of digits of precision of this datetime, from 4
+// for a year up to 17 for a millisecond.
+func (dt DateTime) Digits() int {
+	return dateTimeDigits[dt.precision]
+}
+
+// LowBoundary returns the earliest possible datetime within the period of this
+// datetime, to the specified digits of precision (4, 6, 8, 10, 12, 14, or 17).
+// For example, the low boundary of @2014-01-01T08 at 17 digits is
+// @2014-01-01T08:00:00.000.
+//
+// The timezone of the datetime, or its absence, is preserved. If digits is not
+// a valid DateTime precision, ok is false.
+func (dt DateTime) LowBoundary(digits int) (result DateTime, ok bool) {
+	precision, ok := precisionOfDigits(dateTimeDigits[:], digits, PrecisionYear)
+	if !ok {
+		return DateTime{}, false
+	}
+	return dt.truncate(precision), true
+}
+
+// HighBoundary returns the latest possible datetime within the period of this
+// datetime, to the specified digits of precision (4, 6, 8, 10, 12, 14, or 17).
+// For example, the high boundary of @2014-01-01T08 at 17 digits is
+// @2014-01-01T08:59:59.999.
+//
+// The timezone of the datetime, or its absence, is preserved. If digits is not
+// a valid DateTime precision, ok is false.
+func (dt DateTime) HighBoundary(digits int) (result DateTime, ok bool) {
+	precision, ok := precisionOfDigits(dateTimeDigits[:], digits, PrecisionYear)
+	if !ok {
+		return DateTime{}, false
+	}
+
+	start := dt.truncate(dt.precision).value
+	var end time.Time
+	switch dt.precision {
+	case PrecisionYear:
+		end = start.AddDate(1, 0, 0)
+	case PrecisionMonth:
+		end = start.AddDate(0, 1, 0)
+	case PrecisionDay:
+		end = start.AddDate(0, 0, 1)
+	case PrecisionHour:
+		end = start.Add(time.Hour)
+	case PrecisionMinute:
+		end = start.Add(time.Minute)
+	case PrecisionSecond:
+		end = start.Add(time.Second)
+	default:
+		end = start.Add(time.Millisecond)
+	}
+
+	result = DateTime{
+		value:     end.Add(-time.Millisecond),
+		precision: PrecisionMillisecond,
+		zoned:     dt.zoned,
+	}
+	return result.truncate(precision), true
+}
+
+// truncate returns this datetime with the specified precision, where all the
+// components that are more precise are set to their earliest value.
+func (dt DateTime) truncate(precision DateTimePrecision) DateTime {
+	v := dt.value
+	fields := [...]int{v.Year(), int(v.Month()), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond()}
+	earliest := [...]int{0, 1, 1, 0, 0, 0, 0}
+	for i := int(precision) + 1; i < len(fields); i++ {
+		fields[i] = earliest[i]
+	}
+	fields[6] -= fields[6] % int(time.Millisecond)
+
+	return DateTime{
+		value:     time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], fields[6], v.Location()),
+		precision: precision,
+		zoned:     dt.zoned,
+	}
+}
+
+// Digits returns the number of digits of precision of this time, from 2 for
+// an hour up to 9 for a millisecond.
+func (t Time) Digits() int {
+	return timeDigits[t.precision]
+}
+
+// LowBoundary returns the earliest possible time within the period of this
+// time, to the specified digits of precision (2, 4, 6, or 9). For example, the
+// low boundary of @T10:30 at 9 digits is @T10:30:00.000.
+//
+// If digits is not a valid Time precision, ok is false.
+func (t Time) LowBoundary(digits int) (result Time, ok bool) {
+	precision, ok := precisionOfDigits(timeDigits[:], digits, PrecisionHour)
+	if !ok {
+		return Time{}, false
+	}
+	return t.truncate(precision), true
+}
+
+// HighBoundary returns the latest possible time within the period of this
+// time, to the specified digits of precision (2, 4, 6, or 9). For example, the
+// high boundary of @T10:30 at 9 digits is @T10:30:59.999.
+//
+// If digits is not a valid Time precision, ok is false.
+func (t Time) HighBoundary(digits int) (result Time, ok bool) {
+	precision, ok := precisionOfDigits(timeDigits[:], digits, PrecisionHour)
+	if !ok {
+		return Time{}, false
+	}
+	start := t.truncate(t.precision).value
+	result = Time{
+		value:     start + t.precision.unit() - time.Millisecond,
+		precision: PrecisionMillisecond,
+	}
+	return result.truncate(precision), true
+}
+
+// truncate returns this time with the specified precision, where all the
+// components that are more precise are set to zero.
+func (t Time) truncate(precision DateTimePrecision) Time {
+	return Time{
+		value:     t.value - t.value%precision.unit(),
+		precision: precision,
+	}
+}
+
+// unit returns the duration of a single unit of a time-of-day precision. This
+// is only meaningful for precisions of an hour or finer.
+func (p DateTimePrecision) unit() time.Duration {
+	switch p {
+	case PrecisionHour:
+		return time.Hour
+	case PrecisionMinute:
+		return time.Minute
+	case PrecisionSecond:
+		return time.Second
+	}
+	return time.Millisecond
+}
@@ -0,0 +1,184 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestDecimalBoundary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		digits   int
+		wantLow  string
+		wantHigh string
+	}{
+		{"Default precision", "1.587", 8, "1.58650000", "1.58750000"},
+		{"Greater precision", "1.587", 6, "1.586500", "1.587500"},
+		{"Lesser precision", "1.587", 2, "1.58", "1.59"},
+		{"No decimal places", "1.587", 0, "1", "2"},
+		{"Negative value", "-1.587", 8, "-1.58750000", "-1.58650000"},
+		{"Integer value", "1", 1, "0.5", "1.5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value := system.MustParseDecimal(tc.value)
+
+			low, ok := value.LowBoundary(tc.digits)
+			if !ok {
+				t.Fatalf("LowBoundary(%v) ok = false; want true", tc.digits)
+			}
+			high, ok := value.HighBoundary(tc.digits)
+			if !ok {
+				t.Fatalf("HighBoundary(%v) ok = false; want true", tc.digits)
+			}
+
+			if got, want := low.String(), tc.wantLow; got != want {
+				t.Errorf("LowBoundary(%v) = %v; want %v", tc.digits, got, want)
+			}
+			if got, want := high.String(), tc.wantHigh; got != want {
+				t.Errorf("HighBoundary(%v) = %v; want %v", tc.digits, got, want)
+			}
+			if got, want := low.Digits(), tc.digits; got != want {
+				t.Errorf("LowBoundary(%v).Digits() = %v; want %v", tc.digits, got, want)
+			}
+		})
+	}
+}
+
+func TestDecimalBoundary_InvalidPrecision_ReturnsNotOK(t *testing.T) {
+	value := system.MustParseDecimal("1.587")
+
+	if _, ok := value.LowBoundary(-1); ok {
+		t.Errorf("LowBoundary(-1) ok = true; want false")
+	}
+	if _, ok := value.HighBoundary(system.MaxDecimalDigits + 1); ok {
+		t.Errorf("HighBoundary(%v) ok = true; want false", system.MaxDecimalDigits+1)
+	}
+}
+
+func TestDateTimeBoundary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		digits   int
+		wantLow  string
+		wantHigh string
+	}{
+		{"Year to month", "2014", 6, "2014-01", "2014-12"},
+		{"Month to millisecond", "2020-03", 17, "2020-03-01T00:00:00.000", "2020-03-31T23:59:59.999"},
+		{"Leap year February", "2020-02", 8, "2020-02-01", "2020-02-29"},
+		{"Hour to millisecond", "2014-01-01T08", 17, "2014-01-01T08:00:00.000", "2014-01-01T08:59:59.999"},
+		{"Millisecond to day", "2014-01-01T08:30:15.250", 8, "2014-01-01", "2014-01-01"},
+		{"Timezone is preserved", "2014-01-01T08+02:00", 12, "2014-01-01T08:00+02:00", "2014-01-01T08:59+02:00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value := system.MustParseDateTime(tc.value)
+
+			low, ok := value.LowBoundary(tc.digits)
+			if !ok {
+				t.Fatalf("LowBoundary(%v) ok = false; want true", tc.digits)
+			}
+			high, ok := value.HighBoundary(tc.digits)
+			if !ok {
+				t.Fatalf("HighBoundary(%v) ok = false; want true", tc.digits)
+			}
+
+			if got, want := low.String(), tc.wantLow; got != want {
+				t.Errorf("LowBoundary(%v) = %v; want %v", tc.digits, got, want)
+			}
+			if got, want := high.String(), tc.wantHigh; got != want {
+				t.Errorf("HighBoundary(%v) = %v; want %v", tc.digits, got, want)
+			}
+			if got, want := low.Digits(), tc.digits; got != want {
+				t.Errorf("LowBoundary(%v).Digits() = %v; want %v", tc.digits, got, want)
+			}
+		})
+	}
+}
+
+func TestDateBoundary(t *testing.T) {
+	value := system.MustParseDate("2014")
+
+	low, ok := value.LowBoundary(8)
+	if !ok {
+		t.Fatalf("LowBoundary(8) ok = false; want true")
+	}
+	high, ok := value.HighBoundary(8)
+	if !ok {
+		t.Fatalf("HighBoundary(8) ok = false; want true")
+	}
+
+	if got, want := low, system.MustParseDate("2014-01-01"); got != want {
+		t.Errorf("LowBoundary(8) = %v; want %v", got, want)
+	}
+	if got, want := high, system.MustParseDate("2014-12-31"); got != want {
+		t.Errorf("HighBoundary(8) = %v; want %v", got, want)
+	}
+	if _, ok := value.LowBoundary(10); ok {
+		t.Errorf("LowBoundary(10) ok = true; want false")
+	}
+}
+
+func TestTimeBoundary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		digits   int
+		wantLow  string
+		wantHigh string
+	}{
+		{"Minute to millisecond", "10:30", 9, "10:30:00.000", "10:30:59.999"},
+		{"Hour to minute", "10", 4, "10:00", "10:59"},
+		{"Second to hour", "10:30:15", 2, "10", "10"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value := system.MustParseTime(tc.value)
+
+			low, ok := value.LowBoundary(tc.digits)
+			if !ok {
+				t.Fatalf("LowBoundary(%v) ok = false; want true", tc.digits)
+			}
+			high, ok := value.HighBoundary(tc.digits)
+			if !ok {
+				t.Fatalf("HighBoundary(%v) ok = false; want true", tc.digits)
+			}
+
+			if got, want := low.String(), tc.wantLow; got != want {
+				t.Errorf("LowBoundary(%v) = %v; want %v", tc.digits, got, want)
+			}
+			if got, want := high.String(), tc.wantHigh; got != want {
+				t.Errorf("HighBoundary(%v) = %v; want %v", tc.digits, got, want)
+			}
+		})
+	}
+}
+
+func TestDigits(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value interface{ Digits() int }
+		want  int
+	}{
+		{"Decimal", system.MustParseDecimal("1.58700"), 5},
+		{"Integral decimal", system.MustParseDecimal("42"), 0},
+		{"Date", system.MustParseDate("2014"), 4},
+		{"DateTime", system.MustParseDateTime("2014-01-05T10:30:00.000"), 17},
+		{"Time", system.MustParseTime("10:30"), 4},
+		{"Time with milliseconds", system.MustParseTime("10:30:00.000"), 9},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, want := tc.value.Digits(), tc.want; got != want {
+				t.Errorf("Digits() = %v; want %v", got, want)
+			}
+		})
+	}
+}
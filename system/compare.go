@@ -0,0 +1,139 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNotComparable is an error raised when two values cannot be compared to
+// one another, either because their types are not comparable, or because the
+// result of the comparison cannot be determined.
+var ErrNotComparable = errors.New("not comparable")
+
+// TryCompare compares two FHIRPath values following the semantics of the
+// FHIRPath comparison operators (<, <=, >, >=). FHIR elements are normalized
+// into their System types before comparison.
+//
+// This returns a negative value if lhs is less than rhs, a positive value if
+// lhs is greater than rhs, and zero if they are equal.
+//
+// If the values are comparable, but the result cannot be determined -- such as
+// comparing dates of different precisions, or quantities of incompatible units
+// -- then ok is false and no error is returned. In FHIRPath, this yields an
+// empty result.
+//
+// If the values are of types that cannot be compared at all, such as a String
+// and an Integer, an error wrapping ErrNotComparable is returned.
+//
+// Integers are implicitly promoted to Integer64 and Decimal, and Dates are
+// implicitly promoted to DateTime, when compared against those types.
+func TryCompare(lhs, rhs any) (result int, ok bool, err error) {
+	lhs, rhs = Normalize(lhs), Normalize(rhs)
+	switch l := lhs.(type) {
+	case Integer:
+		switch r := rhs.(type) {
+		case Integer:
+			return l.Compare(r), true, nil
+		case Integer64:
+			return Integer64(l).Compare(r), true, nil
+		case Decimal:
+			return decimal.NewFromInt32(int32(l)).Cmp(decimal.Decimal(r)), true, nil
+		}
+	case Integer64:
+		switch r := rhs.(type) {
+		case Integer:
+			return l.Compare(Integer64(r)), true, nil
+		case Integer64:
+			return l.Compare(r), true, nil
+		case Decimal:
+			return decimal.NewFromInt(int64(l)).Cmp(decimal.Decimal(r)), true, nil
+		}
+	case Decimal:
+		switch r := rhs.(type) {
+		case Integer:
+			return decimal.Decimal(l).Cmp(decimal.NewFromInt32(int32(r))), true, nil
+		case Integer64:
+			return decimal.Decimal(l).Cmp(decimal.NewFromInt(int64(r))), true, nil
+		case Decimal:
+			return l.Compare(r), true, nil
+		}
+	case String:
+		if r, ok := rhs.(String); ok {
+			return l.Compare(r), true, nil
+		}
+	case Date:
+		switch r := rhs.(type) {
+		case Date:
+			result, ok := l.TryCompare(r)
+			return result, ok, nil
+		case DateTime:
+			result, ok := l.DateTime().TryCompare(r)
+			return result, ok, nil
+		}
+	case DateTime:
+		switch r := rhs.(type) {
+		case Date:
+			result, ok := l.TryCompare(r.DateTime())
+			return result, ok, nil
+		case DateTime:
+			result, ok := l.TryCompare(r)
+			return result, ok, nil
+		}
+	case Time:
+		if r, ok := rhs.(Time); ok {
+			result, ok := l.TryCompare(r)
+			return result, ok, nil
+		}
+	case Quantity:
+		if r, ok := rhs.(Quantity); ok {
+			result, ok := l.TryCompare(r)
+			return result, ok, nil
+		}
+	}
+	return 0, false, fmt.Errorf("%w: %v and %v", ErrNotComparable, typeName(lhs), typeName(rhs))
+}
+
+// Compare compares two FHIRPath values following the same semantics as
+// [TryCompare], except that a comparison whose result cannot be determined is
+// also treated as an error wrapping ErrNotComparable.
+//
+// This is useful for operations that require a total ordering, such as
+// sorting.
+func Compare(lhs, rhs any) (int, error) {
+	result, ok, err := TryCompare(lhs, rhs)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w: result of comparing %v and %v is indeterminate", ErrNotComparable, lhs, rhs)
+	}
+	return result, nil
+}
+
+// typeName returns the FHIRPath name of the type of the value, for use in
+// error messages.
+func typeName(v any) string {
+	switch v.(type) {
+	case Boolean:
+		return "System.Boolean"
+	case Integer:
+		return "System.Integer"
+	case Integer64:
+		return "System.Long"
+	case Decimal:
+		return "System.Decimal"
+	case String:
+		return "System.String"
+	case Date:
+		return "System.Date"
+	case DateTime:
+		return "System.DateTime"
+	case Time:
+		return "System.Time"
+	case Quantity:
+		return "System.Quantity"
+	}
+	return fmt.Sprintf("%T", v)
+}
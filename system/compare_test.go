@@ -0,0 +1,75 @@
+package system_test
+
+import (
+	"errors"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestTryCompare(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lhs    any
+		rhs    any
+		want   cmpResult
+		wantOK bool
+	}{
+		{"Integers", system.Integer(1), system.Integer(2), less, true},
+		{"Integer and decimal", system.Integer(2), system.MustParseDecimal("1.5"), greater, true},
+		{"Integer and integer64", system.Integer(2), system.Integer64(2), equal, true},
+		{"Strings", system.String("b"), system.String("a"), greater, true},
+		{"Date and datetime", system.MustParseDate("2012-01-01"), system.MustParseDateTime("2012-01-01T"), equal, true},
+		{"Partial dates", system.MustParseDate("2012"), system.MustParseDate("2012-01"), nil, false},
+		{"FHIR elements", &fhir.Integer{Value: 3}, &fhir.Decimal{Value: 2.5}, greater, true},
+		{"FHIR date and system date", &fhir.Date{Value: "2012-01"}, system.MustParseDate("2012-02"), less, true},
+		{"Incompatible quantities", system.MustParseQuantity("1 'g'"), system.MustParseQuantity("1 'm'"), nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok, err := system.TryCompare(tc.lhs, tc.rhs)
+
+			if err != nil {
+				t.Fatalf("TryCompare() error = %v; want nil", err)
+			}
+			if got, want := ok, tc.wantOK; got != want {
+				t.Fatalf("TryCompare() ok = %v; want %v", got, want)
+			}
+			if ok && !tc.want(got) {
+				t.Errorf("TryCompare() = %v; want different result", got)
+			}
+		})
+	}
+}
+
+func TestTryCompare_IncomparableTypes_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		lhs  any
+		rhs  any
+	}{
+		{"Integer and string", system.Integer(1), system.String("1")},
+		{"Booleans", system.Boolean(true), system.Boolean(false)},
+		{"Date and time", system.MustParseDate("2012"), system.MustParseTime("10")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := system.TryCompare(tc.lhs, tc.rhs)
+
+			if got, want := err, system.ErrNotComparable; !errors.Is(got, want) {
+				t.Errorf("TryCompare() error = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCompare_IndeterminateResult_ReturnsError(t *testing.T) {
+	_, err := system.Compare(system.MustParseDate("2012"), system.MustParseDate("2012-01"))
+
+	if got, want := err, system.ErrNotComparable; !errors.Is(got, want) {
+		t.Errorf("Compare() error = %v; want %v", got, want)
+	}
+}
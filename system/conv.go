@@ -22,13 +22,74 @@ func FromR4(element fhir.Element) (Any, error) {
 		var s String
 		s.FromR4(e)
 		return s, nil
+	case profile.URI:
+		return String(e.GetValue()), nil
+	case *fhir.Base64Binary:
+		return String(e.Value), nil
+	case *fhir.Decimal:
+		var d Decimal
+		d.FromR4(e)
+		return d, nil
+	case *fhir.Date:
+		var d Date
+		if err := d.FromR4(e); err != nil {
+			return nil, err
+		}
+		return d, nil
+	case *fhir.DateTime:
+		var dt DateTime
+		if err := dt.FromR4(e); err != nil {
+			return nil, err
+		}
+		return dt, nil
+	case *fhir.Instant:
+		return ParseDateTime(e.Value)
+	case *fhir.Time:
+		var t Time
+		if err := t.FromR4(e); err != nil {
+			return nil, err
+		}
+		return t, nil
+	case *fhir.Quantity:
+		return quantityFromR4(e)
+	case *fhir.Age:
+		return quantityFromR4(&fhir.Quantity{Value: e.Value, Unit: e.Unit, Code: e.Code})
+	case *fhir.Count:
+		return quantityFromR4(&fhir.Quantity{Value: e.Value, Unit: e.Unit, Code: e.Code})
+	case *fhir.Distance:
+		return quantityFromR4(&fhir.Quantity{Value: e.Value, Unit: e.Unit, Code: e.Code})
+	case *fhir.Duration:
+		return quantityFromR4(&fhir.Quantity{Value: e.Value, Unit: e.Unit, Code: e.Code})
 	}
 	return nil, fmt.Errorf("%w: %T is not a valid R4 type", ErrNotConvertible, element)
 }
 
+func quantityFromR4(e *fhir.Quantity) (Any, error) {
+	if e.Value == nil {
+		return nil, fmt.Errorf("%w: quantity has no value", ErrNotConvertible)
+	}
+	var q Quantity
+	q.FromR4(e)
+	return q, nil
+}
+
+// integer64Element is implemented by FHIR integer64 elements, which were
+// introduced in FHIR R5. These are matched by their shape rather than by their
+// type, since only the R4 model is known to this package.
+type integer64Element interface {
+	GetID() string
+	GetValue() int64
+}
+
 // Normalizes a FHIR R4 type into a system type, if able -- or just returns
 // the input value if it's not a FHIR R4 type.
+//
+// FHIR integer64 elements of later FHIR versions are normalized into a
+// System.Integer64.
 func Normalize(v any) any {
+	if e, ok := v.(integer64Element); ok {
+		return Integer64(e.GetValue())
+	}
 	element, ok := v.(fhir.Element)
 	if !ok {
 		return v
@@ -0,0 +1,27 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+type integer64Element struct {
+	Value int64
+}
+
+func (e *integer64Element) GetID() string {
+	return ""
+}
+
+func (e *integer64Element) GetValue() int64 {
+	return e.Value
+}
+
+func TestNormalize_Integer64Element_ReturnsInteger64(t *testing.T) {
+	got := system.Normalize(&integer64Element{Value: 42})
+
+	if got, want := got, system.Integer64(42); got != want {
+		t.Errorf("Normalize() = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,236 @@
+package system
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+)
+
+// Date is the Go-representation of the FHIRPath System.Date type. This is a
+// (possibly partial) calendar date, which may be precise to the year, month,
+// or day.
+type Date struct {
+	year      int
+	month     time.Month
+	day       int
+	precision DateTimePrecision
+}
+
+// NewDate constructs a new System.Date object precise to the day.
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{
+		year:      year,
+		month:     month,
+		day:       day,
+		precision: PrecisionDay,
+	}
+}
+
+// ParseDate parses a string in the form "YYYY[-MM[-DD]]" into the valid
+// FHIRPath System.Date type. The precision of the result is determined by the
+// components that are present in the input.
+//
+// The FHIRPath literal prefix '@' is not accepted by this function.
+func ParseDate(str string) (Date, error) {
+	date, err := parseDate(str)
+	if err != nil {
+		return Date{}, newParseError[Date](str, err)
+	}
+	return date, nil
+}
+
+// MustParseDate parses a date string, and panics if the value is invalid.
+func MustParseDate(str string) Date {
+	got, err := ParseDate(str)
+	if err != nil {
+		panic(err)
+	}
+	return got
+}
+
+func parseDate(str string) (Date, error) {
+	parts := strings.Split(str, "-")
+	if len(parts) > 3 {
+		return Date{}, fmt.Errorf("too many date components")
+	}
+
+	year, err := parseFixedDigits(parts[0], 4, 0, 9999)
+	if err != nil {
+		return Date{}, fmt.Errorf("year: %w", err)
+	}
+	result := Date{year: year, month: time.January, day: 1, precision: PrecisionYear}
+
+	if len(parts) > 1 {
+		month, err := parseFixedDigits(parts[1], 2, 1, 12)
+		if err != nil {
+			return Date{}, fmt.Errorf("month: %w", err)
+		}
+		result.month = time.Month(month)
+		result.precision = PrecisionMonth
+	}
+
+	if len(parts) > 2 {
+		day, err := parseFixedDigits(parts[2], 2, 1, daysIn(result.year, result.month))
+		if err != nil {
+			return Date{}, fmt.Errorf("day: %w", err)
+		}
+		result.day = day
+		result.precision = PrecisionDay
+	}
+	return result, nil
+}
+
+// daysIn returns the number of days in the specified month of the year.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (Date) isAny() {}
+
+// Year returns the year of this date.
+func (d Date) Year() int {
+	return d.year
+}
+
+// Month returns the month of this date. If the date is less precise than a
+// month, this returns January.
+func (d Date) Month() time.Month {
+	return d.month
+}
+
+// Day returns the day of the month of this date. If the date is less precise
+// than a day, this returns 1.
+func (d Date) Day() int {
+	return d.day
+}
+
+// Precision returns the precision of this date.
+func (d Date) Precision() DateTimePrecision {
+	return d.precision
+}
+
+// Comparisons
+
+// TryCompare compares two System.Date values, following the FHIRPath rules
+// for comparing partial dates.
+//
+// This returns a negative value if this date is before other, a positive
+// value if it is after other, and zero if both dates are the same. If the
+// dates have different precisions and are equal up to the lesser of the two
+// precisions, the result cannot be determined and ok is false.
+//
+// For example, comparing @2012 to @2012-01 cannot be determined, but comparing
+// @2011 to @2012-06 can be.
+func (d Date) TryCompare(other Date) (result int, ok bool) {
+	return compareTemporal(d.fields(), d.precision, other.fields(), other.precision, PrecisionYear)
+}
+
+func (d Date) fields() temporalFields {
+	return temporalFields{d.year, int(d.month), d.day}
+}
+
+// TryEqual compares two System.Date values for equality. If the result cannot
+// be determined, ok is false. See [Date.TryCompare] for when this may occur.
+func (d Date) TryEqual(other Date) (equal, ok bool) {
+	result, ok := d.TryCompare(other)
+	return result == 0, ok
+}
+
+// Conversions
+
+// DateTime converts this System.Date into a System.DateTime with the same
+// precision.
+func (d Date) DateTime() DateTime {
+	return DateTime{
+		value:     time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC),
+		precision: d.precision,
+	}
+}
+
+// Formatting
+
+// String returns the string representation of the System.Date, formatted to
+// its precision.
+func (d Date) String() string {
+	switch d.precision {
+	case PrecisionYear:
+		return fmt.Sprintf("%04d", d.year)
+	case PrecisionMonth:
+		return fmt.Sprintf("%04d-%02d", d.year, int(d.month))
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.year, int(d.month), d.day)
+}
+
+// Format implements the fmt.Formatter interface.
+func (d Date) Format(state fmt.State, verb rune) {
+	fmt.Fprintf(state, "%"+string(verb), d.String())
+}
+
+var (
+	_ fmt.Stringer  = (*Date)(nil)
+	_ fmt.Formatter = (*Date)(nil)
+)
+
+// R4 conversions
+
+// FromR4 converts a FHIR Date type into a System.Date type.
+func (d *Date) FromR4(r *fhir.Date) error {
+	value, err := ParseDate(r.Value)
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
+
+// R4 converts this System.Date into a FHIR Date type.
+func (d Date) R4() *fhir.Date {
+	return &fhir.Date{Value: d.String()}
+}
+
+// JSON conversions
+
+// MarshalJSON converts this Date object into a JSON object.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON converts a JSON object into a Date object.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return d.UnmarshalText([]byte(str))
+}
+
+var (
+	_ json.Marshaler   = (*Date)(nil)
+	_ json.Unmarshaler = (*Date)(nil)
+)
+
+// Text conversions
+
+// MarshalText converts this Date object into a text object.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText converts a text object into a Date object.
+func (d *Date) UnmarshalText(text []byte) error {
+	value, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
+
+var (
+	_ encoding.TextMarshaler   = (*Date)(nil)
+	_ encoding.TextUnmarshaler = (*Date)(nil)
+)
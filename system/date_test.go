@@ -0,0 +1,118 @@
+package system_test
+
+import (
+	"errors"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhirpath/system"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDate(t *testing.T) {
+	testCases := []struct {
+		input     string
+		precision system.DateTimePrecision
+	}{
+		{"2012", system.PrecisionYear},
+		{"2012-02", system.PrecisionMonth},
+		{"2012-02-29", system.PrecisionDay},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := system.ParseDate(tc.input)
+
+			if err != nil {
+				t.Fatalf("ParseDate() = %v; want nil", err)
+			}
+
+			if got, want := got.Precision(), tc.precision; got != want {
+				t.Errorf("ParseDate().Precision() = %v; want %v", got, want)
+			}
+			if got, want := got.String(), tc.input; got != want {
+				t.Errorf("ParseDate().String() = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseDate_InvalidString_ReturnsParseError(t *testing.T) {
+	testCases := []struct {
+		input string
+	}{
+		{"bad value"},
+		{"12"},
+		{"2012-13"},
+		{"2013-02-29"},
+		{"2012-01-01T10:00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := system.ParseDate(tc.input)
+
+			var parseErr *system.ParseError
+			ok := errors.As(err, &parseErr)
+
+			if got, want := ok, true; got != want {
+				t.Errorf("ParseDate() = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDateTryCompare(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lhs    string
+		rhs    string
+		want   cmpResult
+		wantOK bool
+	}{
+		{"Same date", "2012-01-01", "2012-01-01", equal, true},
+		{"Earlier day", "2012-01-01", "2012-01-02", less, true},
+		{"Later month", "2012-02", "2012-01", greater, true},
+		{"Different precision, different year", "2011", "2012-06", less, true},
+		{"Different precision, same year", "2012", "2012-01", nil, false},
+		{"Different precision, same month", "2012-01-01", "2012-01", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhs, rhs := system.MustParseDate(tc.lhs), system.MustParseDate(tc.rhs)
+
+			got, ok := lhs.TryCompare(rhs)
+
+			if got, want := ok, tc.wantOK; got != want {
+				t.Fatalf("Date.TryCompare() ok = %v; want %v", got, want)
+			}
+			if ok && !tc.want(got) {
+				t.Errorf("Date.TryCompare() = %v; want different result", got)
+			}
+		})
+	}
+}
+
+func TestDateR4(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  *fhir.Date
+	}{
+		{"2012", &fhir.Date{Value: "2012"}},
+		{"2012-01", &fhir.Date{Value: "2012-01"}},
+		{"2012-01-15", &fhir.Date{Value: "2012-01-15"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			v := system.MustParseDate(tc.input)
+
+			got := v.R4()
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Date.R4() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
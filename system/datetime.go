@@ -0,0 +1,283 @@
+package system
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+)
+
+// DateTime is the Go-representation of the FHIRPath System.DateTime type. This
+// is a (possibly partial) moment in time, which may be precise anywhere from
+// the year to the millisecond, and may optionally contain a timezone offset.
+//
+// DateTime values that do not specify a timezone offset are treated as UTC for
+// the purposes of comparison.
+type DateTime struct {
+	value     time.Time
+	precision DateTimePrecision
+	zoned     bool
+}
+
+// NewDateTime constructs a new System.DateTime object from a Go time.Time,
+// precise to the millisecond.
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{
+		value:     t,
+		precision: PrecisionMillisecond,
+		zoned:     true,
+	}
+}
+
+// ParseDateTime parses a string in the form
+// "YYYY[-MM[-DD]][T[hh[:mm[:ss[.fff]]]][Z|(+|-)hh:mm]]" into the valid FHIRPath
+// System.DateTime type. The precision of the result is determined by the
+// components that are present in the input.
+//
+// The FHIRPath literal prefix '@' is not accepted by this function.
+func ParseDateTime(str string) (DateTime, error) {
+	dt, err := parseDateTime(str)
+	if err != nil {
+		return DateTime{}, newParseError[DateTime](str, err)
+	}
+	return dt, nil
+}
+
+// MustParseDateTime parses a datetime string, and panics if the value is
+// invalid.
+func MustParseDateTime(str string) DateTime {
+	got, err := ParseDateTime(str)
+	if err != nil {
+		panic(err)
+	}
+	return got
+}
+
+func parseDateTime(str string) (DateTime, error) {
+	datePart, timePart, _ := strings.Cut(str, "T")
+	date, err := parseDate(datePart)
+	if err != nil {
+		return DateTime{}, err
+	}
+	result := date.DateTime()
+	if timePart == "" {
+		return result, nil
+	}
+
+	location := time.UTC
+	if tod, zone, ok := cutZone(timePart); ok {
+		location, err = parseZone(zone)
+		if err != nil {
+			return DateTime{}, err
+		}
+		timePart = tod
+		result.zoned = true
+	}
+
+	tod, precision, err := parseTimeOfDay(timePart)
+	if err != nil {
+		return DateTime{}, err
+	}
+	result.value = time.Date(date.year, date.month, date.day, 0, 0, 0, 0, location).Add(tod)
+	result.precision = precision
+	return result, nil
+}
+
+// cutZone splits a time-of-day string into the time and its timezone offset,
+// if one is present.
+func cutZone(str string) (tod, zone string, ok bool) {
+	if i := strings.IndexAny(str, "Z+-"); i >= 0 {
+		return str[:i], str[i:], true
+	}
+	return str, "", false
+}
+
+// parseZone parses a timezone offset in the form "Z" or "(+|-)hh:mm".
+func parseZone(zone string) (*time.Location, error) {
+	if zone == "Z" {
+		return time.UTC, nil
+	}
+	hours, minutes, ok := strings.Cut(zone[1:], ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid timezone offset %q", zone)
+	}
+	hour, err := parseFixedDigits(hours, 2, 0, 14)
+	if err != nil {
+		return nil, fmt.Errorf("timezone hour: %w", err)
+	}
+	minute, err := parseFixedDigits(minutes, 2, 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("timezone minute: %w", err)
+	}
+	offset := hour*60*60 + minute*60
+	if zone[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone("", offset), nil
+}
+
+func (DateTime) isAny() {}
+
+// Precision returns the precision of this datetime.
+func (dt DateTime) Precision() DateTimePrecision {
+	return dt.precision
+}
+
+// HasTimezone returns whether this datetime was specified with an explicit
+// timezone offset.
+func (dt DateTime) HasTimezone() bool {
+	return dt.zoned
+}
+
+// Time returns the Go time.Time representation of this datetime. Any components
+// that are not specified by the precision of this datetime are set to their
+// earliest value.
+func (dt DateTime) Time() time.Time {
+	return dt.value
+}
+
+// Date returns the date component of this datetime.
+func (dt DateTime) Date() Date {
+	return Date{
+		year:      dt.value.Year(),
+		month:     dt.value.Month(),
+		day:       dt.value.Day(),
+		precision: min(dt.precision, PrecisionDay),
+	}
+}
+
+// Comparisons
+
+// TryCompare compares two System.DateTime values, following the FHIRPath rules
+// for comparing partial datetimes.
+//
+// This returns a negative value if this datetime is before other, a positive
+// value if it is after other, and zero if both datetimes are the same. If the
+// datetimes have different precisions and are equal up to the lesser of the
+// two precisions, the result cannot be determined and ok is false.
+//
+// Seconds and milliseconds are considered a single precision, so
+// @2012-01-01T10:30:00 and @2012-01-01T10:30:00.000 are equal. When both
+// values are at least precise to the hour, timezone offsets are normalized
+// before comparing.
+func (dt DateTime) TryCompare(other DateTime) (result int, ok bool) {
+	lhs, rhs := dt.value, other.value
+	if min(dt.precision, other.precision) >= PrecisionHour {
+		lhs, rhs = lhs.UTC(), rhs.UTC()
+	}
+	return compareTemporal(dateTimeFields(lhs), dt.precision, dateTimeFields(rhs), other.precision, PrecisionYear)
+}
+
+func dateTimeFields(t time.Time) temporalFields {
+	millis := t.Second()*1000 + t.Nanosecond()/int(time.Millisecond)
+	return temporalFields{t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), millis}
+}
+
+// TryEqual compares two System.DateTime values for equality. If the result cannot
+// be determined, ok is false. See [DateTime.TryCompare] for when this may occur.
+func (dt DateTime) TryEqual(other DateTime) (equal, ok bool) {
+	result, ok := dt.TryCompare(other)
+	return result == 0, ok
+}
+
+// Formatting
+
+// String returns the string representation of the System.DateTime, formatted
+// to its precision.
+func (dt DateTime) String() string {
+	date := dt.Date().String()
+	if dt.precision < PrecisionHour {
+		return date
+	}
+	midnight := time.Date(dt.value.Year(), dt.value.Month(), dt.value.Day(), 0, 0, 0, 0, dt.value.Location())
+	result := date + "T" + formatTimeOfDay(dt.value.Sub(midnight), dt.precision)
+	if dt.zoned {
+		result += formatZone(dt.value)
+	}
+	return result
+}
+
+func formatZone(t time.Time) string {
+	_, offset := t.Zone()
+	if offset == 0 {
+		return "Z"
+	}
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// Format implements the fmt.Formatter interface.
+func (dt DateTime) Format(state fmt.State, verb rune) {
+	fmt.Fprintf(state, "%"+string(verb), dt.String())
+}
+
+var (
+	_ fmt.Stringer  = (*DateTime)(nil)
+	_ fmt.Formatter = (*DateTime)(nil)
+)
+
+// R4 conversions
+
+// FromR4 converts a FHIR DateTime type into a System.DateTime type.
+func (dt *DateTime) FromR4(r *fhir.DateTime) error {
+	value, err := ParseDateTime(r.Value)
+	if err != nil {
+		return err
+	}
+	*dt = value
+	return nil
+}
+
+// R4 converts this System.DateTime into a FHIR DateTime type.
+func (dt DateTime) R4() *fhir.DateTime {
+	return &fhir.DateTime{Value: dt.String()}
+}
+
+// JSON conversions
+
+// MarshalJSON converts this DateTime object into a JSON object.
+func (dt DateTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dt.String())
+}
+
+// UnmarshalJSON converts a JSON object into a DateTime object.
+func (dt *DateTime) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return dt.UnmarshalText([]byte(str))
+}
+
+var (
+	_ json.Marshaler   = (*DateTime)(nil)
+	_ json.Unmarshaler = (*DateTime)(nil)
+)
+
+// Text conversions
+
+// MarshalText converts this DateTime object into a text object.
+func (dt DateTime) MarshalText() ([]byte, error) {
+	return []byte(dt.String()), nil
+}
+
+// UnmarshalText converts a text object into a DateTime object.
+func (dt *DateTime) UnmarshalText(text []byte) error {
+	value, err := ParseDateTime(string(text))
+	if err != nil {
+		return err
+	}
+	*dt = value
+	return nil
+}
+
+var (
+	_ encoding.TextMarshaler   = (*DateTime)(nil)
+	_ encoding.TextUnmarshaler = (*DateTime)(nil)
+)
@@ -0,0 +1,99 @@
+package system_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestParseDateTime(t *testing.T) {
+	testCases := []struct {
+		input     string
+		precision system.DateTimePrecision
+		want      string
+	}{
+		{"2012", system.PrecisionYear, "2012"},
+		{"2012-01-01T", system.PrecisionDay, "2012-01-01"},
+		{"2012-01-01T10", system.PrecisionHour, "2012-01-01T10"},
+		{"2012-01-01T10:30", system.PrecisionMinute, "2012-01-01T10:30"},
+		{"2012-01-01T10:30:15", system.PrecisionSecond, "2012-01-01T10:30:15"},
+		{"2012-01-01T10:30:15.250", system.PrecisionMillisecond, "2012-01-01T10:30:15.250"},
+		{"2012-01-01T10:30:15Z", system.PrecisionSecond, "2012-01-01T10:30:15Z"},
+		{"2012-01-01T10:30:15-05:00", system.PrecisionSecond, "2012-01-01T10:30:15-05:00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := system.ParseDateTime(tc.input)
+
+			if err != nil {
+				t.Fatalf("ParseDateTime() = %v; want nil", err)
+			}
+
+			if got, want := got.Precision(), tc.precision; got != want {
+				t.Errorf("ParseDateTime().Precision() = %v; want %v", got, want)
+			}
+			if got, want := got.String(), tc.want; got != want {
+				t.Errorf("ParseDateTime().String() = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseDateTime_InvalidString_ReturnsParseError(t *testing.T) {
+	testCases := []struct {
+		input string
+	}{
+		{"bad value"},
+		{"2012-01-01T25:00"},
+		{"2012-01-01T10:00+5"},
+		{"2012-01-01T10:00:00."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := system.ParseDateTime(tc.input)
+
+			var parseErr *system.ParseError
+			ok := errors.As(err, &parseErr)
+
+			if got, want := ok, true; got != want {
+				t.Errorf("ParseDateTime() = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDateTimeTryCompare(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lhs    string
+		rhs    string
+		want   cmpResult
+		wantOK bool
+	}{
+		{"Same datetime", "2012-01-01T10:30", "2012-01-01T10:30", equal, true},
+		{"Earlier minute", "2012-01-01T10:29", "2012-01-01T10:30", less, true},
+		{"Seconds and milliseconds", "2012-01-01T10:30:00", "2012-01-01T10:30:00.000", equal, true},
+		{"Later milliseconds", "2012-01-01T10:30:00.500", "2012-01-01T10:30:00", greater, true},
+		{"Timezones are normalized", "2012-01-01T10:00+02:00", "2012-01-01T08:00Z", equal, true},
+		{"Different precision, same hour", "2012-01-01T10", "2012-01-01T10:30", nil, false},
+		{"Different precision, different day", "2012-01-01", "2012-01-02T10:30", less, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhs, rhs := system.MustParseDateTime(tc.lhs), system.MustParseDateTime(tc.rhs)
+
+			got, ok := lhs.TryCompare(rhs)
+
+			if got, want := ok, tc.wantOK; got != want {
+				t.Fatalf("DateTime.TryCompare() ok = %v; want %v", got, want)
+			}
+			if ok && !tc.want(got) {
+				t.Errorf("DateTime.TryCompare() = %v; want different result", got)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
 	"github.com/shopspring/decimal"
 )
 
@@ -30,6 +31,15 @@ func ParseDecimal(str string) (Decimal, error) {
 	return Decimal(value), nil
 }
 
+// MustParseDecimal parses a decimal string, and panics if the value is invalid.
+func MustParseDecimal(str string) Decimal {
+	got, err := ParseDecimal(str)
+	if err != nil {
+		panic(err)
+	}
+	return got
+}
+
 func (Decimal) isAny() {}
 
 // Comparisons
@@ -77,14 +87,22 @@ func (d Decimal) Float64() float64 {
 
 // Formatter
 
-// String returns the string representation of the System.Decimal.
+// String returns the string representation of the System.Decimal, with the
+// digits after the decimal point that it was specified with -- so that the
+// trailing zeros of 1.50, or of a boundary such as 1.58650000, are kept.
 func (d Decimal) String() string {
-	return decimal.Decimal(d).String()
+	return decimal.Decimal(d).StringFixed(int32(d.Digits()))
 }
 
-// Format implements the fmt.Formatter interface.
+// Format implements the fmt.Formatter interface. The 'v' and 's' verbs format
+// the decimal as its String, and any other verb formats it as a float.
 func (d Decimal) Format(state fmt.State, verb rune) {
-	fmt.Fprintf(state, "%"+string(verb), decimal.Decimal(d).InexactFloat64())
+	switch verb {
+	case 'v', 's':
+		fmt.Fprintf(state, fmt.FormatString(state, 's'), d.String())
+	default:
+		fmt.Fprintf(state, "%"+string(verb), decimal.Decimal(d).InexactFloat64())
+	}
 }
 
 var (
@@ -92,6 +110,18 @@ var (
 	_ fmt.Formatter = (*Decimal)(nil)
 )
 
+// R4 conversions
+
+// FromR4 converts a FHIR Decimal type into a System.Decimal type.
+func (d *Decimal) FromR4(r *fhir.Decimal) {
+	*d = NewDecimal(r.Value)
+}
+
+// R4 converts this System.Decimal into a FHIR Decimal type.
+func (d Decimal) R4() *fhir.Decimal {
+	return &fhir.Decimal{Value: d.Float64()}
+}
+
 // JSON conversions
 
 // MarshalJSON converts this Decimal object into a JSON object.
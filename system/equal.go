@@ -0,0 +1,48 @@
+package system
+
+import (
+	"reflect"
+)
+
+// TryEqual compares two FHIRPath values for equality, following the semantics
+// of the FHIRPath equality operator (=). FHIR elements are normalized into
+// their System types before comparison.
+//
+// Values of different types are never equal, with the exception of the
+// implicit conversions that FHIRPath defines (such as Integer to Decimal, or
+// Date to DateTime). FHIR elements that have no System representation, such as
+// a Coding, are compared element-by-element.
+//
+// If the result of the comparison cannot be determined -- such as comparing
+// dates of different precisions, or quantities of incompatible units -- then ok
+// is false. In FHIRPath, this yields an empty result.
+func TryEqual(lhs, rhs any) (equal, ok bool) {
+	lhs, rhs = Normalize(lhs), Normalize(rhs)
+	switch l := lhs.(type) {
+	case Boolean:
+		r, ok := rhs.(Boolean)
+		return ok && l == r, true
+	case String:
+		r, ok := rhs.(String)
+		return ok && l == r, true
+	case Integer, Integer64, Decimal, Date, DateTime, Time, Quantity:
+		result, ok, err := TryCompare(l, rhs)
+		if err != nil {
+			// Values of incomparable types are simply not equal.
+			return false, true
+		}
+		return result == 0, ok
+	}
+	if _, ok := rhs.(Any); ok {
+		return false, true
+	}
+	return reflect.DeepEqual(lhs, rhs), true
+}
+
+// Equal returns whether two FHIRPath values are equal, following the semantics
+// of [TryEqual]. Comparisons whose result cannot be determined are treated as
+// not equal.
+func Equal(lhs, rhs any) bool {
+	equal, ok := TryEqual(lhs, rhs)
+	return equal && ok
+}
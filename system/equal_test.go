@@ -0,0 +1,42 @@
+package system_test
+
+import (
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestTryEqual(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lhs    any
+		rhs    any
+		want   bool
+		wantOK bool
+	}{
+		{"Same strings", system.String("a"), system.String("a"), true, true},
+		{"Different strings", system.String("a"), system.String("b"), false, true},
+		{"Integer and decimal", system.Integer(1), system.MustParseDecimal("1.0"), true, true},
+		{"Integer and string", system.Integer(1), system.String("1"), false, true},
+		{"FHIR code and string", &fhir.Code{Value: "male"}, system.String("male"), true, true},
+		{"Partial dates", system.MustParseDate("2012"), system.MustParseDate("2012-01"), false, false},
+		{"Convertible quantities", system.MustParseQuantity("1 'g'"), system.MustParseQuantity("1000 'mg'"), true, true},
+		{"Incompatible quantities", system.MustParseQuantity("1 'g'"), system.MustParseQuantity("1 'm'"), false, false},
+		{"Same codings", &fhir.Coding{Code: &fhir.Code{Value: "a"}}, &fhir.Coding{Code: &fhir.Code{Value: "a"}}, true, true},
+		{"Different codings", &fhir.Coding{Code: &fhir.Code{Value: "a"}}, &fhir.Coding{Code: &fhir.Code{Value: "b"}}, false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := system.TryEqual(tc.lhs, tc.rhs)
+
+			if got, want := ok, tc.wantOK; got != want {
+				t.Fatalf("TryEqual() ok = %v; want %v", got, want)
+			}
+			if got, want := got, tc.want; ok && got != want {
+				t.Errorf("TryEqual() = %v; want %v", got, want)
+			}
+		})
+	}
+}
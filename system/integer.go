@@ -1,6 +1,7 @@
 package system
 
 import (
+	"cmp"
 	"encoding"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 
 	fhir "github.com/friendly-fhir/go-fhir/r4/core"
 	profile "github.com/friendly-fhir/go-fhir/r4/core/profiles"
+	"github.com/shopspring/decimal"
 )
 
 // Integer is the Go-representation of the FHIRPath System.Integer type. This is
@@ -57,7 +59,7 @@ func (i Integer) Negate() Integer {
 //	assert.True(b.Compare(a) > 0)
 //	assert.True(a.Compare(a) == 0)
 func (i Integer) Compare(other Integer) int {
-	return int(i - other)
+	return cmp.Compare(i, other)
 }
 
 // Int32 converts this system.Integer into an in32Go native type.
@@ -65,6 +67,11 @@ func (i Integer) Int32() int32 {
 	return int32(i)
 }
 
+// Decimal converts this system.Integer into a system.Decimal type.
+func (i Integer) Decimal() Decimal {
+	return Decimal(decimal.NewFromInt32(int32(i)))
+}
+
 // Formatter
 
 // String returns the string representation of the System.Integer.
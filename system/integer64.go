@@ -1,10 +1,13 @@
 package system
 
 import (
+	"cmp"
 	"encoding"
 	"encoding/json"
 	"fmt"
 	"strconv"
+
+	"github.com/shopspring/decimal"
 )
 
 // Integer64 is the Go-representation of the FHIRPath System.Integer type. This
@@ -54,7 +57,7 @@ func (i Integer64) Negate() Integer64 {
 //	assert.True(b.Compare(a) > 0)
 //	assert.True(a.Compare(a) == 0)
 func (i Integer64) Compare(other Integer64) int {
-	return int(i - other)
+	return cmp.Compare(i, other)
 }
 
 // Int64 converts this system.Integer into an in64Go native type.
@@ -62,6 +65,11 @@ func (i Integer64) Int64() int64 {
 	return int64(i)
 }
 
+// Decimal converts this system.Integer64 into a system.Decimal type.
+func (i Integer64) Decimal() Decimal {
+	return Decimal(decimal.NewFromInt(int64(i)))
+}
+
 // Formatting
 
 // String returns the string representation of the System.Integer.
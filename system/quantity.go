@@ -0,0 +1,188 @@
+package system
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/shopspring/decimal"
+)
+
+// Quantity is the Go-representation of the FHIRPath System.Quantity type. This
+// is a decimal value paired with a unit, which is either a UCUM unit code or
+// one of the FHIRPath calendar duration keywords (e.g. "year", "days").
+type Quantity struct {
+	value Decimal
+	unit  string
+}
+
+// NewQuantity constructs a new System.Quantity object with the given value and
+// unit. An empty unit is equivalent to the UCUM unity unit '1', and plural
+// calendar durations (e.g. "days") are normalized into their singular form.
+func NewQuantity(value Decimal, unit string) Quantity {
+	return Quantity{
+		value: value,
+		unit:  canonicalUnit(unit),
+	}
+}
+
+// ParseQuantity parses a string in the form of a FHIRPath quantity literal,
+// such as "4.5 'mg'", "3 days", or "42", into the valid FHIRPath
+// System.Quantity type.
+func ParseQuantity(str string) (Quantity, error) {
+	number, unit, _ := strings.Cut(strings.TrimSpace(str), " ")
+	value, err := ParseDecimal(number)
+	if err != nil {
+		return Quantity{}, newParseError[Quantity](str, err)
+	}
+
+	unit = strings.TrimSpace(unit)
+	if strings.HasPrefix(unit, "'") {
+		ucum, err := ParseString(unit)
+		if err != nil {
+			return Quantity{}, newParseError[Quantity](str, err)
+		}
+		return NewQuantity(value, string(ucum)), nil
+	}
+	if unit != "" && !isCalendarUnit(unit) {
+		return Quantity{}, newParseError[Quantity](str, fmt.Errorf("unknown calendar duration %q", unit))
+	}
+	return NewQuantity(value, unit), nil
+}
+
+// MustParseQuantity parses a quantity string, and panics if the value is
+// invalid.
+func MustParseQuantity(str string) Quantity {
+	got, err := ParseQuantity(str)
+	if err != nil {
+		panic(err)
+	}
+	return got
+}
+
+func (Quantity) isAny() {}
+
+// Value returns the numeric value of this quantity.
+func (q Quantity) Value() Decimal {
+	return q.value
+}
+
+// Unit returns the unit of this quantity.
+func (q Quantity) Unit() string {
+	return q.unit
+}
+
+// Comparisons
+
+// TryCompare compares two System.Quantity values.
+//
+// This returns a negative value if this quantity is less than other, a
+// positive value if it is greater than other, and zero if both are the same.
+// Quantities with different units are converted to a common unit where
+// possible; if the units are not convertible to one another, the result cannot
+// be determined and ok is false.
+//
+// Calendar durations of years and months are only comparable with each other,
+// and not with the UCUM definite-duration units 'a' and 'mo'.
+func (q Quantity) TryCompare(other Quantity) (result int, ok bool) {
+	lhs, rhs, ok := convertUnits(decimal.Decimal(q.value), q.unit, decimal.Decimal(other.value), other.unit)
+	if !ok {
+		return 0, false
+	}
+	return lhs.Cmp(rhs), true
+}
+
+// TryEqual compares two System.Quantity values for equality. If the result cannot
+// be determined, ok is false. See [Quantity.TryCompare] for when this may occur.
+func (q Quantity) TryEqual(other Quantity) (equal, ok bool) {
+	result, ok := q.TryCompare(other)
+	return result == 0, ok
+}
+
+// Formatting
+
+// String returns the string representation of the System.Quantity, in the
+// form of a FHIRPath quantity literal.
+func (q Quantity) String() string {
+	if isCalendarUnit(q.unit) {
+		return fmt.Sprintf("%v %v", q.value, q.unit)
+	}
+	return fmt.Sprintf("%v '%v'", q.value, q.unit)
+}
+
+// Format implements the fmt.Formatter interface.
+func (q Quantity) Format(state fmt.State, verb rune) {
+	fmt.Fprintf(state, "%"+string(verb), q.String())
+}
+
+var (
+	_ fmt.Stringer  = (*Quantity)(nil)
+	_ fmt.Formatter = (*Quantity)(nil)
+)
+
+// R4 conversions
+
+// FromR4 converts a FHIR Quantity type into a System.Quantity type. The UCUM
+// code of the quantity is preferred over the human-readable unit.
+func (q *Quantity) FromR4(r *fhir.Quantity) {
+	unit := r.GetUnit().GetValue()
+	if code := r.GetCode().GetValue(); code != "" {
+		unit = code
+	}
+	*q = NewQuantity(NewDecimal(r.GetValue().GetValue()), unit)
+}
+
+// R4 converts this System.Quantity into a FHIR Quantity type.
+func (q Quantity) R4() *fhir.Quantity {
+	return &fhir.Quantity{
+		Value:  &fhir.Decimal{Value: q.value.Float64()},
+		Unit:   &fhir.String{Value: q.unit},
+		Code:   &fhir.Code{Value: q.unit},
+		System: &fhir.URI{Value: "http://unitsofmeasure.org"},
+	}
+}
+
+// JSON conversions
+
+// MarshalJSON converts this Quantity object into a JSON object.
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.String())
+}
+
+// UnmarshalJSON converts a JSON object into a Quantity object.
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return q.UnmarshalText([]byte(str))
+}
+
+var (
+	_ json.Marshaler   = (*Quantity)(nil)
+	_ json.Unmarshaler = (*Quantity)(nil)
+)
+
+// Text conversions
+
+// MarshalText converts this Quantity object into a text object.
+func (q Quantity) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText converts a text object into a Quantity object.
+func (q *Quantity) UnmarshalText(text []byte) error {
+	value, err := ParseQuantity(string(text))
+	if err != nil {
+		return err
+	}
+	*q = value
+	return nil
+}
+
+var (
+	_ encoding.TextMarshaler   = (*Quantity)(nil)
+	_ encoding.TextUnmarshaler = (*Quantity)(nil)
+)
@@ -0,0 +1,94 @@
+package system_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestParseQuantity(t *testing.T) {
+	testCases := []struct {
+		input string
+		unit  string
+		want  string
+	}{
+		{"4.5 'mg'", "mg", "4.5 'mg'"},
+		{"3 days", "day", "3 day"},
+		{"1 year", "year", "1 year"},
+		{"42", "1", "42 '1'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := system.ParseQuantity(tc.input)
+
+			if err != nil {
+				t.Fatalf("ParseQuantity() = %v; want nil", err)
+			}
+
+			if got, want := got.Unit(), tc.unit; got != want {
+				t.Errorf("ParseQuantity().Unit() = %v; want %v", got, want)
+			}
+			if got, want := got.String(), tc.want; got != want {
+				t.Errorf("ParseQuantity().String() = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseQuantity_InvalidString_ReturnsParseError(t *testing.T) {
+	testCases := []struct {
+		input string
+	}{
+		{"bad value"},
+		{"4 fortnights"},
+		{"4 'mg"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := system.ParseQuantity(tc.input)
+
+			var parseErr *system.ParseError
+			ok := errors.As(err, &parseErr)
+
+			if got, want := ok, true; got != want {
+				t.Errorf("ParseQuantity() = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestQuantityTryCompare(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lhs    string
+		rhs    string
+		want   cmpResult
+		wantOK bool
+	}{
+		{"Same unit", "4 'mg'", "5 'mg'", less, true},
+		{"Convertible units", "1 'kg'", "1000 'g'", equal, true},
+		{"Calendar and UCUM durations", "1 day", "24 'h'", equal, true},
+		{"Calendar years and months", "1 year", "12 months", equal, true},
+		{"Calendar years and UCUM years", "1 year", "1 'a'", nil, false},
+		{"Incompatible units", "1 'mg'", "1 'mL'", nil, false},
+		{"Unknown units", "1 '[foo]'", "1 '[bar]'", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhs, rhs := system.MustParseQuantity(tc.lhs), system.MustParseQuantity(tc.rhs)
+
+			got, ok := lhs.TryCompare(rhs)
+
+			if got, want := ok, tc.wantOK; got != want {
+				t.Fatalf("Quantity.TryCompare() ok = %v; want %v", got, want)
+			}
+			if ok && !tc.want(got) {
+				t.Errorf("Quantity.TryCompare() = %v; want different result", got)
+			}
+		})
+	}
+}
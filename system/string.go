@@ -51,7 +51,14 @@ func MustParseString(str string) String {
 
 func (String) isAny() {}
 
-// Compares compares the other system.String value to provide a total-ordering.
+// Compare compares the other system.String value to provide a total-ordering.
+//
+// The ordering is the collation that FHIRPath defines for strings: it is
+// strictly lexical, based on the Unicode code points of the characters, and is
+// not sensitive to locale. Since strings are UTF-8 encoded, this is the order
+// of their bytes -- so 'B' sorts before 'a', and 'é' sorts after 'z'.
+//
+// See: https://hl7.org/fhirpath/N1/#comparison
 func (s String) Compare(other String) int {
 	return strings.Compare(string(s), string(other))
 }
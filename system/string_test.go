@@ -96,6 +96,9 @@ func TestStringCompare(t *testing.T) {
 		{"Equal", "hello", "hello", equal},
 		{"Less", "123", "987", less},
 		{"Greater", "987", "123", greater},
+		{"Upper case before lower case", "Z", "a", less},
+		{"By code point", "é", "z", greater},
+		{"Prefix", "abc", "abcd", less},
 	}
 
 	for _, tc := range testCases {
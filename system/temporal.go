@@ -0,0 +1,194 @@
+package system
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateTimePrecision is the precision of a (possibly partial) temporal value,
+// such as a Date, DateTime, or Time.
+//
+// Precisions are ordered from least precise to most precise, so that a
+// comparison of two precisions indicates which is more specific.
+type DateTimePrecision int
+
+const (
+	// PrecisionYear indicates a value that is only precise to the year.
+	PrecisionYear DateTimePrecision = iota
+
+	// PrecisionMonth indicates a value that is only precise to the month.
+	PrecisionMonth
+
+	// PrecisionDay indicates a value that is only precise to the day.
+	PrecisionDay
+
+	// PrecisionHour indicates a value that is only precise to the hour.
+	PrecisionHour
+
+	// PrecisionMinute indicates a value that is only precise to the minute.
+	PrecisionMinute
+
+	// PrecisionSecond indicates a value that is only precise to the second.
+	PrecisionSecond
+
+	// PrecisionMillisecond indicates a value that is precise to the millisecond.
+	PrecisionMillisecond
+)
+
+// String returns the FHIRPath calendar-duration keyword of this precision,
+// e.g. "year" or "millisecond".
+func (p DateTimePrecision) String() string {
+	switch p {
+	case PrecisionYear:
+		return "year"
+	case PrecisionMonth:
+		return "month"
+	case PrecisionDay:
+		return "day"
+	case PrecisionHour:
+		return "hour"
+	case PrecisionMinute:
+		return "minute"
+	case PrecisionSecond:
+		return "second"
+	case PrecisionMillisecond:
+		return "millisecond"
+	}
+	return fmt.Sprintf("DateTimePrecision(%d)", int(p))
+}
+
+// comparable returns the precision used when comparing temporal values. In
+// FHIRPath, seconds and milliseconds are considered to be a single precision
+// using a decimal value.
+func (p DateTimePrecision) comparable() DateTimePrecision {
+	if p == PrecisionMillisecond {
+		return PrecisionSecond
+	}
+	return p
+}
+
+// temporalFields are the individual fields of a temporal value, ordered from
+// least to most precise. Seconds are stored in milliseconds, so that they
+// compare as a single decimal precision.
+type temporalFields [6]int
+
+// compareTemporal compares two sets of temporal fields, each with their own
+// precision, following the FHIRPath rules for comparing partial temporal
+// values.
+//
+// Fields are compared from the least precise to the most precise. If a
+// difference is found in a precision that both values specify, that difference
+// is the result. If all common precisions are equal but one value is more
+// precise than the other, the result cannot be determined and ok is false.
+func compareTemporal(lhs temporalFields, lp DateTimePrecision, rhs temporalFields, rp DateTimePrecision, from DateTimePrecision) (result int, ok bool) {
+	lp, rp = lp.comparable(), rp.comparable()
+	common := min(lp, rp)
+	for p := from; p <= common; p++ {
+		i := int(p - from)
+		if lhs[i] < rhs[i] {
+			return -1, true
+		}
+		if lhs[i] > rhs[i] {
+			return 1, true
+		}
+	}
+	if lp != rp {
+		return 0, false
+	}
+	return 0, true
+}
+
+// parseTimeOfDay parses the time-of-day component of a Time or DateTime value
+// in the form "hh[:mm[:ss[.fff]]]", returning the duration since midnight and
+// the precision of the parsed value.
+func parseTimeOfDay(str string) (time.Duration, DateTimePrecision, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) > 3 {
+		return 0, 0, fmt.Errorf("too many time components")
+	}
+
+	hour, err := parseFixedDigits(parts[0], 2, 0, 23)
+	if err != nil {
+		return 0, 0, fmt.Errorf("hour: %w", err)
+	}
+	result := time.Duration(hour) * time.Hour
+	precision := PrecisionHour
+
+	if len(parts) > 1 {
+		minute, err := parseFixedDigits(parts[1], 2, 0, 59)
+		if err != nil {
+			return 0, 0, fmt.Errorf("minute: %w", err)
+		}
+		result += time.Duration(minute) * time.Minute
+		precision = PrecisionMinute
+	}
+
+	if len(parts) > 2 {
+		seconds, fraction, hasFraction := strings.Cut(parts[2], ".")
+		second, err := parseFixedDigits(seconds, 2, 0, 59)
+		if err != nil {
+			return 0, 0, fmt.Errorf("second: %w", err)
+		}
+		result += time.Duration(second) * time.Second
+		precision = PrecisionSecond
+
+		if hasFraction {
+			if fraction == "" || !isDigits(fraction) {
+				return 0, 0, fmt.Errorf("invalid fractional seconds %q", fraction)
+			}
+			// Only nanosecond precision is representable; anything beyond is
+			// truncated.
+			fraction = (fraction + "000000000")[:9]
+			nanos, _ := strconv.Atoi(fraction)
+			result += time.Duration(nanos)
+			precision = PrecisionMillisecond
+		}
+	}
+	return result, precision, nil
+}
+
+// formatTimeOfDay formats a duration since midnight into a time-of-day string
+// with the given precision.
+func formatTimeOfDay(d time.Duration, precision DateTimePrecision) string {
+	hour := int(d / time.Hour)
+	minute := int(d % time.Hour / time.Minute)
+	second := int(d % time.Minute / time.Second)
+	millis := int(d % time.Second / time.Millisecond)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%02d", hour)
+	if precision >= PrecisionMinute {
+		fmt.Fprintf(&sb, ":%02d", minute)
+	}
+	if precision >= PrecisionSecond {
+		fmt.Fprintf(&sb, ":%02d", second)
+	}
+	if precision >= PrecisionMillisecond {
+		fmt.Fprintf(&sb, ".%03d", millis)
+	}
+	return sb.String()
+}
+
+// parseFixedDigits parses a string of exactly n digits into an integer that
+// lies within the inclusive range [lo, hi].
+func parseFixedDigits(str string, n, lo, hi int) (int, error) {
+	if len(str) != n || !isDigits(str) {
+		return 0, fmt.Errorf("expected %d digits, got %q", n, str)
+	}
+	value, _ := strconv.Atoi(str)
+	if value < lo || value > hi {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", value, lo, hi)
+	}
+	return value, nil
+}
+
+func isDigits(str string) bool {
+	for _, r := range str {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+package system
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+)
+
+// Time is the Go-representation of the FHIRPath System.Time type. This is a
+// (possibly partial) wall-clock time of day, disconnected from any date, which
+// may be precise anywhere from the hour to the millisecond.
+type Time struct {
+	value     time.Duration
+	precision DateTimePrecision
+}
+
+// NewTime constructs a new System.Time object, precise to the millisecond.
+func NewTime(hour, minute, second, millisecond int) Time {
+	value := time.Duration(hour)*time.Hour +
+		time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second +
+		time.Duration(millisecond)*time.Millisecond
+	return Time{
+		value:     value,
+		precision: PrecisionMillisecond,
+	}
+}
+
+// ParseTime parses a string in the form "hh[:mm[:ss[.fff]]]" into the valid
+// FHIRPath System.Time type. The precision of the result is determined by the
+// components that are present in the input.
+//
+// The FHIRPath literal prefix '@T' is not accepted by this function.
+func ParseTime(str string) (Time, error) {
+	value, precision, err := parseTimeOfDay(str)
+	if err != nil {
+		return Time{}, newParseError[Time](str, err)
+	}
+	return Time{value: value, precision: precision}, nil
+}
+
+// MustParseTime parses a time string, and panics if the value is invalid.
+func MustParseTime(str string) Time {
+	got, err := ParseTime(str)
+	if err != nil {
+		panic(err)
+	}
+	return got
+}
+
+func (Time) isAny() {}
+
+// Hour returns the hour of this time.
+func (t Time) Hour() int {
+	return int(t.value / time.Hour)
+}
+
+// Minute returns the minute of this time. If the time is less precise than a
+// minute, this returns 0.
+func (t Time) Minute() int {
+	return int(t.value % time.Hour / time.Minute)
+}
+
+// Second returns the second of this time. If the time is less precise than a
+// second, this returns 0.
+func (t Time) Second() int {
+	return int(t.value % time.Minute / time.Second)
+}
+
+// Millisecond returns the millisecond of this time. If the time is less
+// precise than a millisecond, this returns 0.
+func (t Time) Millisecond() int {
+	return int(t.value % time.Second / time.Millisecond)
+}
+
+// Precision returns the precision of this time.
+func (t Time) Precision() DateTimePrecision {
+	return t.precision
+}
+
+// Duration returns the duration since midnight that this time represents.
+func (t Time) Duration() time.Duration {
+	return t.value
+}
+
+// Comparisons
+
+// TryCompare compares two System.Time values, following the FHIRPath rules
+// for comparing partial times.
+//
+// This returns a negative value if this time is before other, a positive
+// value if it is after other, and zero if both times are the same. If the
+// times have different precisions and are equal up to the lesser of the two
+// precisions, the result cannot be determined and ok is false.
+func (t Time) TryCompare(other Time) (result int, ok bool) {
+	return compareTemporal(t.fields(), t.precision, other.fields(), other.precision, PrecisionHour)
+}
+
+func (t Time) fields() temporalFields {
+	return temporalFields{t.Hour(), t.Minute(), t.Second()*1000 + t.Millisecond()}
+}
+
+// TryEqual compares two System.Time values for equality. If the result cannot
+// be determined, ok is false. See [Time.TryCompare] for when this may occur.
+func (t Time) TryEqual(other Time) (equal, ok bool) {
+	result, ok := t.TryCompare(other)
+	return result == 0, ok
+}
+
+// Formatting
+
+// String returns the string representation of the System.Time, formatted to
+// its precision.
+func (t Time) String() string {
+	return formatTimeOfDay(t.value, t.precision)
+}
+
+// Format implements the fmt.Formatter interface.
+func (t Time) Format(state fmt.State, verb rune) {
+	fmt.Fprintf(state, "%"+string(verb), t.String())
+}
+
+var (
+	_ fmt.Stringer  = (*Time)(nil)
+	_ fmt.Formatter = (*Time)(nil)
+)
+
+// R4 conversions
+
+// FromR4 converts a FHIR Time type into a System.Time type.
+func (t *Time) FromR4(r *fhir.Time) error {
+	value, err := ParseTime(r.Value)
+	if err != nil {
+		return err
+	}
+	*t = value
+	return nil
+}
+
+// R4 converts this System.Time into a FHIR Time type.
+func (t Time) R4() *fhir.Time {
+	return &fhir.Time{Value: t.String()}
+}
+
+// JSON conversions
+
+// MarshalJSON converts this Time object into a JSON object.
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON converts a JSON object into a Time object.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(str))
+}
+
+var (
+	_ json.Marshaler   = (*Time)(nil)
+	_ json.Unmarshaler = (*Time)(nil)
+)
+
+// Text conversions
+
+// MarshalText converts this Time object into a text object.
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText converts a text object into a Time object.
+func (t *Time) UnmarshalText(text []byte) error {
+	value, err := ParseTime(string(text))
+	if err != nil {
+		return err
+	}
+	*t = value
+	return nil
+}
+
+var (
+	_ encoding.TextMarshaler   = (*Time)(nil)
+	_ encoding.TextUnmarshaler = (*Time)(nil)
+)
@@ -0,0 +1,91 @@
+package system_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/friendly-fhir/go-fhirpath/system"
+)
+
+func TestParseTime(t *testing.T) {
+	testCases := []struct {
+		input     string
+		precision system.DateTimePrecision
+	}{
+		{"10", system.PrecisionHour},
+		{"10:30", system.PrecisionMinute},
+		{"10:30:15", system.PrecisionSecond},
+		{"10:30:15.250", system.PrecisionMillisecond},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := system.ParseTime(tc.input)
+
+			if err != nil {
+				t.Fatalf("ParseTime() = %v; want nil", err)
+			}
+
+			if got, want := got.Precision(), tc.precision; got != want {
+				t.Errorf("ParseTime().Precision() = %v; want %v", got, want)
+			}
+			if got, want := got.String(), tc.input; got != want {
+				t.Errorf("ParseTime().String() = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseTime_InvalidString_ReturnsParseError(t *testing.T) {
+	testCases := []struct {
+		input string
+	}{
+		{"bad value"},
+		{"24:00"},
+		{"10:60"},
+		{"10:30:15:00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := system.ParseTime(tc.input)
+
+			var parseErr *system.ParseError
+			ok := errors.As(err, &parseErr)
+
+			if got, want := ok, true; got != want {
+				t.Errorf("ParseTime() = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTimeTryCompare(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lhs    string
+		rhs    string
+		want   cmpResult
+		wantOK bool
+	}{
+		{"Same time", "10:30", "10:30", equal, true},
+		{"Earlier hour", "09:30", "10:30", less, true},
+		{"Different precision, different hour", "11", "10:30", greater, true},
+		{"Different precision, same hour", "10", "10:30", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhs, rhs := system.MustParseTime(tc.lhs), system.MustParseTime(tc.rhs)
+
+			got, ok := lhs.TryCompare(rhs)
+
+			if got, want := ok, tc.wantOK; got != want {
+				t.Fatalf("Time.TryCompare() ok = %v; want %v", got, want)
+			}
+			if ok && !tc.want(got) {
+				t.Errorf("Time.TryCompare() = %v; want different result", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package system
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// unit is a definition of a unit of measure that may be converted to other
+// units of the same dimension.
+type unit struct {
+	// dimension is a name for the kind of measurement this unit represents.
+	// Only units of the same dimension are convertible to one another.
+	dimension string
+
+	// factor is the multiplier required to convert a value in this unit into
+	// the base unit of the dimension.
+	factor decimal.Decimal
+}
+
+// units is the set of known, convertible units of measure. This is not an
+// exhaustive UCUM implementation, but covers the common units seen in clinical
+// data. Units that are not present here are only comparable to quantities with
+// an identical unit.
+var units = map[string]unit{}
+
+func defineUnits(dimension string, factors map[string]string) {
+	for name, factor := range factors {
+		units[name] = unit{
+			dimension: dimension,
+			factor:    decimal.RequireFromString(factor),
+		}
+	}
+}
+
+func init() {
+	defineUnits("time", map[string]string{
+		// UCUM definite-duration units.
+		"ms":  "0.001",
+		"s":   "1",
+		"min": "60",
+		"h":   "3600",
+		"d":   "86400",
+		"wk":  "604800",
+		"mo":  "2629800",
+		"a":   "31557600",
+
+		// FHIRPath calendar durations that are comparable to UCUM units. Years
+		// and months are variable in length and so are defined separately.
+		"millisecond": "0.001",
+		"second":      "1",
+		"minute":      "60",
+		"hour":        "3600",
+		"day":         "86400",
+		"week":        "604800",
+	})
+	defineUnits("calendar", map[string]string{
+		"month": "1",
+		"year":  "12",
+	})
+	defineUnits("mass", map[string]string{
+		"kg":        "1000",
+		"g":         "1",
+		"mg":        "0.001",
+		"ug":        "0.000001",
+		"ng":        "0.000000001",
+		"[lb_av]":   "453.59237",
+		"[oz_av]":   "28.349523125",
+		"[gr]":      "0.06479891",
+		"[ston_av]": "6350.29318",
+	})
+	defineUnits("length", map[string]string{
+		"km":     "1000",
+		"m":      "1",
+		"dm":     "0.1",
+		"cm":     "0.01",
+		"mm":     "0.001",
+		"um":     "0.000001",
+		"nm":     "0.000000001",
+		"[in_i]": "0.0254",
+		"[ft_i]": "0.3048",
+		"[yd_i]": "0.9144",
+		"[mi_i]": "1609.344",
+	})
+	defineUnits("volume", map[string]string{
+		"L":  "1",
+		"l":  "1",
+		"dL": "0.1",
+		"dl": "0.1",
+		"cL": "0.01",
+		"cl": "0.01",
+		"mL": "0.001",
+		"ml": "0.001",
+		"uL": "0.000001",
+		"ul": "0.000001",
+	})
+	defineUnits("dimensionless", map[string]string{
+		"1": "1",
+		"%": "0.01",
+	})
+}
+
+// calendarUnits maps the plural forms of the FHIRPath calendar duration
+// keywords to their singular form.
+var calendarUnits = map[string]string{
+	"years":        "year",
+	"months":       "month",
+	"weeks":        "week",
+	"days":         "day",
+	"hours":        "hour",
+	"minutes":      "minute",
+	"seconds":      "second",
+	"milliseconds": "millisecond",
+}
+
+// canonicalUnit returns the canonical spelling of a unit, which collapses
+// plural calendar durations into their singular form, and an absent unit into
+// the UCUM unity unit "1".
+func canonicalUnit(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "1"
+	}
+	if singular, ok := calendarUnits[name]; ok {
+		return singular
+	}
+	return name
+}
+
+// isCalendarUnit returns whether the unit is one of the FHIRPath calendar
+// duration keywords.
+func isCalendarUnit(name string) bool {
+	switch canonicalUnit(name) {
+	case "year", "month", "week", "day", "hour", "minute", "second", "millisecond":
+		return true
+	}
+	return false
+}
+
+// convertUnits converts two values in the specified units into values of a
+// common unit. If the units are not convertible to one another, ok is false.
+func convertUnits(lhs decimal.Decimal, lunit string, rhs decimal.Decimal, runit string) (l, r decimal.Decimal, ok bool) {
+	lunit, runit = canonicalUnit(lunit), canonicalUnit(runit)
+	if lunit == runit {
+		return lhs, rhs, true
+	}
+
+	ldef, lok := units[lunit]
+	rdef, rok := units[runit]
+	if !lok || !rok || ldef.dimension != rdef.dimension {
+		return lhs, rhs, false
+	}
+	return lhs.Mul(ldef.factor), rhs.Mul(rdef.factor), true
+}
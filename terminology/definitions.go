@@ -0,0 +1,204 @@
+package terminology
+
+import (
+	"encoding/json"
+	"strconv"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/codesystem"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/valueset"
+)
+
+// The definitions in this file mirror the JSON representation of the parts of
+// CodeSystem and ValueSet resources that are needed to evaluate terminology
+// operations. The go-fhir model omits the recursive elements of these
+// resources -- 'CodeSystem.concept.concept', 'ValueSet.compose.exclude', and
+// 'ValueSet.expansion.contains.contains' -- so resources are decoded into these
+// definitions rather than into the model types.
+
+type codeSystemDefinition struct {
+	URL     string               `json:"url"`
+	Version string               `json:"version"`
+	Name    string               `json:"name"`
+	Concept []*conceptDefinition `json:"concept"`
+}
+
+type conceptDefinition struct {
+	Code     string                `json:"code"`
+	Display  string                `json:"display"`
+	Property []*propertyDefinition `json:"property"`
+	Concept  []*conceptDefinition  `json:"concept"`
+}
+
+// propertyDefinition is a property of a concept, with its value[x] converted
+// into the string form that filters are expressed in.
+type propertyDefinition struct {
+	Code  string
+	Value string
+}
+
+func (p *propertyDefinition) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Code          string      `json:"code"`
+		ValueCode     *string     `json:"valueCode"`
+		ValueString   *string     `json:"valueString"`
+		ValueDateTime *string     `json:"valueDateTime"`
+		ValueBoolean  *bool       `json:"valueBoolean"`
+		ValueInteger  json.Number `json:"valueInteger"`
+		ValueDecimal  json.Number `json:"valueDecimal"`
+		ValueCoding   *struct {
+			Code string `json:"code"`
+		} `json:"valueCoding"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.Code = raw.Code
+	switch {
+	case raw.ValueCode != nil:
+		p.Value = *raw.ValueCode
+	case raw.ValueString != nil:
+		p.Value = *raw.ValueString
+	case raw.ValueDateTime != nil:
+		p.Value = *raw.ValueDateTime
+	case raw.ValueBoolean != nil:
+		p.Value = strconv.FormatBool(*raw.ValueBoolean)
+	case raw.ValueInteger != "":
+		p.Value = raw.ValueInteger.String()
+	case raw.ValueDecimal != "":
+		p.Value = raw.ValueDecimal.String()
+	case raw.ValueCoding != nil:
+		p.Value = raw.ValueCoding.Code
+	}
+	return nil
+}
+
+type valueSetDefinition struct {
+	URL       string               `json:"url"`
+	Version   string               `json:"version"`
+	Compose   *composeDefinition   `json:"compose"`
+	Expansion *expansionDefinition `json:"expansion"`
+}
+
+type composeDefinition struct {
+	Include []*includeDefinition `json:"include"`
+	Exclude []*includeDefinition `json:"exclude"`
+}
+
+type includeDefinition struct {
+	System   string                      `json:"system"`
+	Version  string                      `json:"version"`
+	Concept  []*includeConceptDefinition `json:"concept"`
+	Filter   []*filterDefinition         `json:"filter"`
+	ValueSet []string                    `json:"valueSet"`
+}
+
+type includeConceptDefinition struct {
+	Code    string `json:"code"`
+	Display string `json:"display"`
+}
+
+type filterDefinition struct {
+	Property string `json:"property"`
+	Op       string `json:"op"`
+	Value    string `json:"value"`
+}
+
+type expansionDefinition struct {
+	Contains []*containsDefinition `json:"contains"`
+}
+
+type containsDefinition struct {
+	System   string                `json:"system"`
+	Version  string                `json:"version"`
+	Code     string                `json:"code"`
+	Display  string                `json:"display"`
+	Contains []*containsDefinition `json:"contains"`
+}
+
+func codeSystemDefinitionOf(cs *codesystem.CodeSystem) *codeSystemDefinition {
+	def := &codeSystemDefinition{
+		URL:     cs.GetURL().GetValue(),
+		Version: cs.GetVersion().GetValue(),
+		Name:    cs.GetName().GetValue(),
+	}
+	for _, concept := range cs.GetConcept() {
+		conceptDef := &conceptDefinition{
+			Code:    concept.GetCode().GetValue(),
+			Display: concept.GetDisplay().GetValue(),
+		}
+		for _, property := range concept.GetProperty() {
+			conceptDef.Property = append(conceptDef.Property, &propertyDefinition{
+				Code:  property.GetCode().GetValue(),
+				Value: propertyValueOf(property.GetValue()),
+			})
+		}
+		def.Concept = append(def.Concept, conceptDef)
+	}
+	return def
+}
+
+func propertyValueOf(value fhir.Element) string {
+	switch value := value.(type) {
+	case *fhir.Code:
+		return value.Value
+	case *fhir.String:
+		return value.Value
+	case *fhir.DateTime:
+		return value.Value
+	case *fhir.Boolean:
+		return strconv.FormatBool(value.Value)
+	case *fhir.Integer:
+		return strconv.Itoa(int(value.Value))
+	case *fhir.Decimal:
+		return strconv.FormatFloat(value.Value, 'f', -1, 64)
+	case *fhir.Coding:
+		return value.GetCode().GetValue()
+	}
+	return ""
+}
+
+func valueSetDefinitionOf(vs *valueset.ValueSet) *valueSetDefinition {
+	def := &valueSetDefinition{
+		URL:     vs.GetURL().GetValue(),
+		Version: vs.GetVersion().GetValue(),
+	}
+	if compose := vs.GetCompose(); compose != nil {
+		def.Compose = &composeDefinition{}
+		for _, include := range compose.GetInclude() {
+			includeDef := &includeDefinition{
+				System:  include.GetSystem().GetValue(),
+				Version: include.GetVersion().GetValue(),
+			}
+			for _, concept := range include.GetConcept() {
+				includeDef.Concept = append(includeDef.Concept, &includeConceptDefinition{
+					Code:    concept.GetCode().GetValue(),
+					Display: concept.GetDisplay().GetValue(),
+				})
+			}
+			for _, filter := range include.GetFilter() {
+				includeDef.Filter = append(includeDef.Filter, &filterDefinition{
+					Property: filter.GetProperty().GetValue(),
+					Op:       filter.GetOp().GetValue(),
+					Value:    filter.GetValue().GetValue(),
+				})
+			}
+			for _, valueSet := range include.GetValueSet() {
+				includeDef.ValueSet = append(includeDef.ValueSet, valueSet.GetValue())
+			}
+			def.Compose.Include = append(def.Compose.Include, includeDef)
+		}
+	}
+	if expansion := vs.GetExpansion(); expansion != nil {
+		def.Expansion = &expansionDefinition{}
+		for _, contains := range expansion.GetContains() {
+			def.Expansion.Contains = append(def.Expansion.Contains, &containsDefinition{
+				System:  contains.GetSystem().GetValue(),
+				Version: contains.GetVersion().GetValue(),
+				Code:    contains.GetCode().GetValue(),
+				Display: contains.GetDisplay().GetValue(),
+			})
+		}
+	}
+	return def
+}
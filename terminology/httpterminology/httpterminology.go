@@ -0,0 +1,274 @@
+/*
+Package httpterminology provides a [terminology.Terminology] that performs
+terminology operations with a FHIR terminology server over HTTP.
+*/
+package httpterminology
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/parameters"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/valueset"
+	"github.com/friendly-fhir/go-fhirpath/internal/fhirclient"
+	"github.com/friendly-fhir/go-fhirpath/internal/resources"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
+)
+
+// Option is an option that configures the terminology.
+type Option interface {
+	apply(*Terminology)
+}
+
+type option func(*Terminology)
+
+func (f option) apply(t *Terminology) {
+	f(t)
+}
+
+// WithClient returns an [Option] that configures the terminology to send
+// requests with the specified client.
+//
+// By default, without this specified, [http.DefaultClient] is used.
+func WithClient(client *http.Client) Option {
+	return option(func(t *Terminology) {
+		t.client.HTTP = client
+	})
+}
+
+// WithAuthHeader returns an [Option] that configures the terminology to set the
+// Authorization header of each request to the value returned by fn. This is
+// called for every request, so that credentials such as bearer tokens may be
+// refreshed as they expire.
+func WithAuthHeader(fn func(ctx context.Context) (string, error)) Option {
+	return option(func(t *Terminology) {
+		t.client.Auth = fn
+	})
+}
+
+// Terminology is a [terminology.Terminology] that performs each operation with
+// the corresponding FHIR terminology operation on a server, such as
+// 'GET [base]/ValueSet/$expand?url=...'. The parameters of each operation are
+// sent along with the request.
+//
+// If the server does not have the value set, code system, or concept map of an
+// operation, an error wrapping the corresponding terminology error is
+// returned. If the server otherwise fails a request with an OperationOutcome,
+// an *[Error] is returned.
+type Terminology struct {
+	client fhirclient.Client
+	terminology.BaseTerminology
+}
+
+// Error is an error returned by the server when it fails a request.
+type Error = fhirclient.Error
+
+// New returns a [Terminology] for the FHIR terminology server at the base URL.
+func New(base string, opts ...Option) *Terminology {
+	t := &Terminology{
+		client: fhirclient.Client{Base: strings.TrimSuffix(base, "/")},
+	}
+	for _, opt := range opts {
+		opt.apply(t)
+	}
+	return t
+}
+
+// MemberOf validates the coding against the value set with the
+// ValueSet/$validate-code operation.
+func (t *Terminology) MemberOf(ctx context.Context, coding terminology.Coding, valueSet string) (bool, error) {
+	result, err := t.ValidateVS(ctx, valueSet, coding, nil)
+	if err != nil {
+		return false, err
+	}
+	value, _ := parameter(result, "result").(*fhir.Boolean)
+	return value.GetValue(), nil
+}
+
+// Subsumes tests the subsumption of the codes with the CodeSystem/$subsumes
+// operation.
+func (t *Terminology) Subsumes(ctx context.Context, system, codeA, codeB string) (terminology.Outcome, error) {
+	query := url.Values{"system": {system}, "codeA": {codeA}, "codeB": {codeB}}
+	result, _, err := t.parameters(ctx, "CodeSystem/$subsumes", query, nil, terminology.ErrUnknownCodeSystem, system)
+	if err != nil {
+		return "", err
+	}
+	outcome, ok := parameter(result, "outcome").(*fhir.Code)
+	if !ok {
+		return "", fmt.Errorf("code system '%v': $subsumes returned no outcome", system)
+	}
+	return terminology.Outcome(outcome.Value), nil
+}
+
+// Expand expands the value set with the ValueSet/$expand operation.
+func (t *Terminology) Expand(ctx context.Context, valueSet string, params url.Values) (*valueset.ValueSet, error) {
+	data, err := t.get(ctx, "ValueSet/$expand", url.Values{"url": {valueSet}}, params, terminology.ErrUnknownValueSet, valueSet)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := resources.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := resource.(*valueset.ValueSet)
+	if !ok {
+		return nil, fmt.Errorf("value set '%v': $expand returned %T", valueSet, resource)
+	}
+	return result, nil
+}
+
+// Lookup looks up the coding with the CodeSystem/$lookup operation.
+func (t *Terminology) Lookup(ctx context.Context, coding terminology.Coding, params url.Values) (*parameters.Parameters, error) {
+	result, _, err := t.parameters(ctx, "CodeSystem/$lookup", codingQuery(nil, coding, "system"), params, terminology.ErrUnknownCodeSystem, coding.System)
+	return result, err
+}
+
+// ValidateVS validates the coding against the value set with the
+// ValueSet/$validate-code operation.
+func (t *Terminology) ValidateVS(ctx context.Context, valueSet string, coding terminology.Coding, params url.Values) (*parameters.Parameters, error) {
+	query := codingQuery(url.Values{"url": {valueSet}}, coding, "system")
+	result, _, err := t.parameters(ctx, "ValueSet/$validate-code", query, params, terminology.ErrUnknownValueSet, valueSet)
+	return result, err
+}
+
+// ValidateCS validates the coding against the code system with the
+// CodeSystem/$validate-code operation.
+func (t *Terminology) ValidateCS(ctx context.Context, codeSystem string, coding terminology.Coding, params url.Values) (*parameters.Parameters, error) {
+	coding.System = ""
+	query := codingQuery(url.Values{"url": {codeSystem}}, coding, "")
+	result, _, err := t.parameters(ctx, "CodeSystem/$validate-code", query, params, terminology.ErrUnknownCodeSystem, codeSystem)
+	return result, err
+}
+
+// Translate translates the coding with the ConceptMap/$translate operation.
+// Each 'match' parameter of the response is flattened into a 'match' and an
+// 'equivalence' parameter, as described by [terminology.Terminology].
+func (t *Terminology) Translate(ctx context.Context, conceptMap string, coding terminology.Coding, params url.Values) (*parameters.Parameters, error) {
+	query := codingQuery(url.Values{"url": {conceptMap}}, coding, "system")
+	result, data, err := t.parameters(ctx, "ConceptMap/$translate", query, params, terminology.ErrUnknownConceptMap, conceptMap)
+	if err != nil {
+		return nil, err
+	}
+	if err := flattenMatches(result, data); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+var _ terminology.Terminology = (*Terminology)(nil)
+
+// codingQuery adds the coding to the query of an operation, where system is the
+// name of the parameter for the system of the coding.
+func codingQuery(query url.Values, coding terminology.Coding, system string) url.Values {
+	if query == nil {
+		query = url.Values{}
+	}
+	if coding.System != "" && system != "" {
+		query.Set(system, coding.System)
+	}
+	if coding.Version != "" {
+		query.Set("version", coding.Version)
+	}
+	query.Set("code", coding.Code)
+	if coding.Display != "" {
+		query.Set("display", coding.Display)
+	}
+	return query
+}
+
+// get invokes the operation with the query and the additional parameters. If
+// the server does not have the resource that the operation is invoked for, an
+// error wrapping notFound is returned.
+func (t *Terminology) get(ctx context.Context, operation string, query, params url.Values, notFound error, canonical string) ([]byte, error) {
+	for name, values := range params {
+		query[name] = append(query[name], values...)
+	}
+	data, err := t.client.Get(ctx, operation+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%w '%v'", notFound, canonical)
+	}
+	return data, nil
+}
+
+// parameters invokes the operation, and returns the Parameters that it
+// responded with along with the data of the response.
+func (t *Terminology) parameters(ctx context.Context, operation string, query, params url.Values, notFound error, canonical string) (*parameters.Parameters, []byte, error) {
+	data, err := t.get(ctx, operation, query, params, notFound, canonical)
+	if err != nil {
+		return nil, nil, err
+	}
+	resource, err := resources.Decode(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, ok := resource.(*parameters.Parameters)
+	if !ok {
+		return nil, nil, fmt.Errorf("%v returned %T", operation, resource)
+	}
+	return result, data, nil
+}
+
+// parameter returns the value of the first parameter with the name.
+func parameter(params *parameters.Parameters, name string) fhir.Element {
+	for _, param := range params.GetParameter() {
+		if param.GetName().GetValue() == name {
+			return param.GetValue()
+		}
+	}
+	return nil
+}
+
+// flattenMatches replaces the 'match' parameters of a $translate response,
+// whose parts the go-fhir model does not decode, with 'match' and
+// 'equivalence' parameters decoded from the data of the response.
+func flattenMatches(params *parameters.Parameters, data []byte) error {
+	var raw struct {
+		Parameter []struct {
+			Name string `json:"name"`
+			Part []struct {
+				Name        string       `json:"name"`
+				ValueCode   string       `json:"valueCode"`
+				ValueCoding *fhir.Coding `json:"valueCoding"`
+			} `json:"part"`
+		} `json:"parameter"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	params.Parameter = slices.DeleteFunc(params.Parameter, func(param *parameters.ParametersParameter) bool {
+		return param.GetName().GetValue() == "match"
+	})
+	for _, param := range raw.Parameter {
+		if param.Name != "match" {
+			continue
+		}
+		var concept *fhir.Coding
+		var equivalence string
+		for _, part := range param.Part {
+			switch part.Name {
+			case "concept":
+				concept = part.ValueCoding
+			case "equivalence":
+				equivalence = part.ValueCode
+			}
+		}
+		if concept == nil {
+			continue
+		}
+		params.Parameter = append(params.Parameter,
+			&parameters.ParametersParameter{Name: &fhir.String{Value: "match"}, Value: concept},
+			&parameters.ParametersParameter{Name: &fhir.String{Value: "equivalence"}, Value: &fhir.Code{Value: equivalence}},
+		)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package httpterminology_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/parameters"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
+	"github.com/friendly-fhir/go-fhirpath/terminology/httpterminology"
+	"github.com/google/go-cmp/cmp"
+)
+
+// newServer returns a test FHIR terminology server that responds to each
+// request URI with the status and body in responses, and with 404 for all other
+// requests.
+func newServer(t *testing.T, responses map[string]response) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resp, ok := responses[req.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/fhir+json")
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+type response struct {
+	status int
+	body   string
+}
+
+const (
+	system     = "http://example.org/animals"
+	valueSet   = "http://example.org/ValueSet/mammals"
+	conceptMap = "http://example.org/ConceptMap/animals"
+)
+
+func TestTerminology(t *testing.T) {
+	server := newServer(t, map[string]response{
+		"/fhir/ValueSet/$validate-code?code=dog&system=http%3A%2F%2Fexample.org%2Fanimals&url=http%3A%2F%2Fexample.org%2FValueSet%2Fmammals": {http.StatusOK,
+			`{"resourceType":"Parameters","parameter":[{"name":"result","valueBoolean":true},{"name":"display","valueString":"Dog"}]}`},
+		"/fhir/CodeSystem/$subsumes?codeA=mammal&codeB=dog&system=http%3A%2F%2Fexample.org%2Fanimals": {http.StatusOK,
+			`{"resourceType":"Parameters","parameter":[{"name":"outcome","valueCode":"subsumes"}]}`},
+		"/fhir/ValueSet/$expand?count=1&url=http%3A%2F%2Fexample.org%2FValueSet%2Fmammals": {http.StatusOK,
+			`{"resourceType":"ValueSet","expansion":{"total":2,"contains":[{"system":"http://example.org/animals","code":"dog"}]}}`},
+		"/fhir/CodeSystem/$lookup?code=dog&system=http%3A%2F%2Fexample.org%2Fanimals": {http.StatusOK,
+			`{"resourceType":"Parameters","parameter":[{"name":"name","valueString":"Animals"},{"name":"display","valueString":"Dog"}]}`},
+		"/fhir/CodeSystem/$validate-code?code=dog&url=http%3A%2F%2Fexample.org%2Fanimals": {http.StatusOK,
+			`{"resourceType":"Parameters","parameter":[{"name":"result","valueBoolean":true}]}`},
+		"/fhir/ConceptMap/$translate?code=dog&system=http%3A%2F%2Fexample.org%2Fanimals&url=http%3A%2F%2Fexample.org%2FConceptMap%2Fanimals": {http.StatusOK,
+			`{"resourceType":"Parameters","parameter":[
+				{"name":"result","valueBoolean":true},
+				{"name":"match","part":[
+					{"name":"equivalence","valueCode":"equivalent"},
+					{"name":"concept","valueCoding":{"system":"http://example.org/pets","code":"canine"}}
+				]}
+			]}`},
+	})
+	tx := httpterminology.New(server.URL + "/fhir/")
+	ctx := context.Background()
+	dog := terminology.Coding{System: system, Code: "dog"}
+
+	t.Run("MemberOf", func(t *testing.T) {
+		got, err := tx.MemberOf(ctx, dog, valueSet)
+		if err != nil {
+			t.Fatalf("MemberOf() error = %v", err)
+		}
+		if want := true; got != want {
+			t.Errorf("MemberOf() = %v; want %v", got, want)
+		}
+	})
+	t.Run("Subsumes", func(t *testing.T) {
+		got, err := tx.Subsumes(ctx, system, "mammal", "dog")
+		if err != nil {
+			t.Fatalf("Subsumes() error = %v", err)
+		}
+		if want := terminology.Subsumes; got != want {
+			t.Errorf("Subsumes() = %v; want %v", got, want)
+		}
+	})
+	t.Run("Expand", func(t *testing.T) {
+		got, err := tx.Expand(ctx, valueSet, url.Values{"count": {"1"}})
+		if err != nil {
+			t.Fatalf("Expand() error = %v", err)
+		}
+		if got, want := got.GetExpansion().GetTotal().GetValue(), int32(2); got != want {
+			t.Errorf("Expand() total = %v; want %v", got, want)
+		}
+		if got, want := len(got.GetExpansion().GetContains()), 1; got != want {
+			t.Errorf("Expand() contains = %v; want %v", got, want)
+		}
+	})
+	t.Run("Lookup", func(t *testing.T) {
+		got, err := tx.Lookup(ctx, dog, nil)
+		if err != nil {
+			t.Fatalf("Lookup() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"name", "display"}, names(got.GetParameter())); diff != "" {
+			t.Errorf("Lookup() mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("ValidateCS", func(t *testing.T) {
+		got, err := tx.ValidateCS(ctx, system, dog, nil)
+		if err != nil {
+			t.Fatalf("ValidateCS() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"result"}, names(got.GetParameter())); diff != "" {
+			t.Errorf("ValidateCS() mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("Translate", func(t *testing.T) {
+		got, err := tx.Translate(ctx, conceptMap, dog, nil)
+		if err != nil {
+			t.Fatalf("Translate() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"result", "match", "equivalence"}, names(got.GetParameter())); diff != "" {
+			t.Fatalf("Translate() mismatch (-want +got):\n%s", diff)
+		}
+		match, _ := got.Parameter[1].GetValue().(*fhir.Coding)
+		if got, want := match.GetCode().GetValue(), "canine"; got != want {
+			t.Errorf("Translate() match = %v; want %v", got, want)
+		}
+		equivalence, _ := got.Parameter[2].GetValue().(*fhir.Code)
+		if got, want := equivalence.GetValue(), "equivalent"; got != want {
+			t.Errorf("Translate() equivalence = %v; want %v", got, want)
+		}
+	})
+}
+
+// names returns the names of the parameters.
+func names(params []*parameters.ParametersParameter) []string {
+	var result []string
+	for _, param := range params {
+		result = append(result, param.GetName().GetValue())
+	}
+	return result
+}
+
+func TestTerminology_NotFound_ReturnsError(t *testing.T) {
+	server := newServer(t, nil)
+	tx := httpterminology.New(server.URL)
+	ctx := context.Background()
+	dog := terminology.Coding{System: system, Code: "dog"}
+
+	testCases := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"MemberOf", func() error { _, err := tx.MemberOf(ctx, dog, valueSet); return err }, terminology.ErrUnknownValueSet},
+		{"Subsumes", func() error { _, err := tx.Subsumes(ctx, system, "a", "b"); return err }, terminology.ErrUnknownCodeSystem},
+		{"Expand", func() error { _, err := tx.Expand(ctx, valueSet, nil); return err }, terminology.ErrUnknownValueSet},
+		{"Lookup", func() error { _, err := tx.Lookup(ctx, dog, nil); return err }, terminology.ErrUnknownCodeSystem},
+		{"ValidateCS", func() error { _, err := tx.ValidateCS(ctx, system, dog, nil); return err }, terminology.ErrUnknownCodeSystem},
+		{"Translate", func() error { _, err := tx.Translate(ctx, conceptMap, dog, nil); return err }, terminology.ErrUnknownConceptMap},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+
+			if got, want := err, tc.want; !errors.Is(got, want) {
+				t.Errorf("%v() error = %v; want %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+func TestTerminology_OperationOutcome_ReturnsError(t *testing.T) {
+	server := newServer(t, map[string]response{
+		"/CodeSystem/$lookup?code=dog&system=http%3A%2F%2Fexample.org%2Fanimals": {http.StatusBadRequest,
+			`{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"invalid","diagnostics":"bad request"}]}`},
+	})
+	tx := httpterminology.New(server.URL)
+
+	_, err := tx.Lookup(context.Background(), terminology.Coding{System: system, Code: "dog"}, nil)
+
+	var httpErr *httpterminology.Error
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Lookup() error = %v; want *httpterminology.Error", err)
+	}
+	if got, want := httpErr.StatusCode, http.StatusBadRequest; got != want {
+		t.Errorf("Error.StatusCode = %v; want %v", got, want)
+	}
+	if got, want := err.Error(), "fhir server: 400 Bad Request: bad request"; got != want {
+		t.Errorf("Error() = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,451 @@
+package terminology
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/codesystem"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/conceptmap"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/valueset"
+	"github.com/friendly-fhir/go-fhirpath/internal/resources"
+)
+
+// Memory is a [Terminology] that is backed by CodeSystem, ValueSet, and
+// ConceptMap resources held in memory, so that terminology-aware expressions
+// may be evaluated without a terminology server.
+//
+// Value sets are evaluated from their 'compose' definition, supporting the
+// inclusion and exclusion of enumerated concepts, whole code systems, other
+// value sets, and the 'is-a', 'descendent-of', '=', and 'regex' filters. A
+// value set without a 'compose' definition is evaluated from its 'expansion'.
+//
+// Subsumption is computed from the hierarchy of concepts in a code system,
+// which may be defined by nesting concepts, or with the 'parent' and 'child'
+// concept properties.
+//
+// Translations are computed from the groups of a concept map, without support
+// for dependencies or for the unmapped elements of a group.
+//
+// Resources must not be added while the terminology is in use.
+type Memory struct {
+	codeSystems map[string]*codeSystem
+	valueSets   map[string]*valueSet
+	conceptMaps map[string]*conceptMap
+	BaseTerminology
+}
+
+// NewMemory returns a [Memory] terminology containing the CodeSystem, ValueSet,
+// and ConceptMap resources.
+func NewMemory(resources ...fhir.Resource) (*Memory, error) {
+	m := &Memory{
+		codeSystems: map[string]*codeSystem{},
+		valueSets:   map[string]*valueSet{},
+		conceptMaps: map[string]*conceptMap{},
+	}
+	for _, resource := range resources {
+		if err := m.Add(resource); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add adds a CodeSystem, ValueSet, or ConceptMap resource to the terminology.
+//
+// The go-fhir model cannot represent nested concepts or value set exclusions,
+// so resources that use these should be added with [Memory.AddJSON] instead.
+func (m *Memory) Add(resource fhir.Resource) error {
+	switch resource := resource.(type) {
+	case *codesystem.CodeSystem:
+		return m.addCodeSystem(codeSystemDefinitionOf(resource))
+	case *valueset.ValueSet:
+		return m.addValueSet(valueSetDefinitionOf(resource))
+	case *conceptmap.ConceptMap:
+		return m.addConceptMap(resource)
+	}
+	return fmt.Errorf("terminology: unsupported resource %T", resource)
+}
+
+// AddJSON adds a CodeSystem, ValueSet, or ConceptMap resource, in the FHIR JSON
+// format, to the terminology.
+func (m *Memory) AddJSON(data []byte) error {
+	var header struct {
+		ResourceType string `json:"resourceType"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("terminology: %w", err)
+	}
+	switch header.ResourceType {
+	case "CodeSystem":
+		var def codeSystemDefinition
+		if err := json.Unmarshal(data, &def); err != nil {
+			return fmt.Errorf("terminology: %w", err)
+		}
+		return m.addCodeSystem(&def)
+	case "ValueSet":
+		var def valueSetDefinition
+		if err := json.Unmarshal(data, &def); err != nil {
+			return fmt.Errorf("terminology: %w", err)
+		}
+		return m.addValueSet(&def)
+	case "ConceptMap":
+		resource, err := resources.Decode(data)
+		if err != nil {
+			return fmt.Errorf("terminology: %w", err)
+		}
+		return m.Add(resource)
+	}
+	return fmt.Errorf("terminology: unsupported resource type '%v'", header.ResourceType)
+}
+
+// MemberOf reports whether the coding is a member of the value set. If the
+// coding has no system, it is a member if the value set contains its code in
+// any system.
+func (m *Memory) MemberOf(ctx context.Context, coding Coding, valueSet string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return m.memberOf(coding, valueSet, nil)
+}
+
+// Subsumes returns the relationship between codeA and codeB in the hierarchy of
+// the code system.
+func (m *Memory) Subsumes(ctx context.Context, system, codeA, codeB string) (Outcome, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	cs, err := m.codeSystem(system, "")
+	if err != nil {
+		return "", err
+	}
+	for _, code := range []string{codeA, codeB} {
+		if _, ok := cs.concepts[code]; !ok {
+			return "", fmt.Errorf("code system '%v': unknown code '%v'", system, code)
+		}
+	}
+	switch {
+	case codeA == codeB:
+		return Equivalent, nil
+	case cs.isDescendant(codeB, codeA):
+		return Subsumes, nil
+	case cs.isDescendant(codeA, codeB):
+		return SubsumedBy, nil
+	}
+	return NotSubsumed, nil
+}
+
+var _ Terminology = (*Memory)(nil)
+
+func (m *Memory) codeSystem(url, version string) (*codeSystem, error) {
+	if cs, ok := m.codeSystems[url+"|"+version]; ok && version != "" {
+		return cs, nil
+	}
+	if cs, ok := m.codeSystems[url]; ok {
+		return cs, nil
+	}
+	return nil, fmt.Errorf("%w '%v'", ErrUnknownCodeSystem, url)
+}
+
+// codeSystem is the hierarchy of concepts defined by a CodeSystem.
+type codeSystem struct {
+	url      string
+	version  string
+	name     string
+	concepts map[string]*concept
+	order    []string
+}
+
+type concept struct {
+	code       string
+	display    string
+	properties map[string][]string
+	parents    []string
+}
+
+func (m *Memory) addCodeSystem(def *codeSystemDefinition) error {
+	if def.URL == "" {
+		return fmt.Errorf("terminology: CodeSystem has no url")
+	}
+	cs := &codeSystem{url: def.URL, version: def.Version, name: def.Name, concepts: map[string]*concept{}}
+	var children [][2]string
+	var add func(defs []*conceptDefinition, parent string)
+	add = func(defs []*conceptDefinition, parent string) {
+		for _, def := range defs {
+			c := &concept{code: def.Code, display: def.Display, properties: map[string][]string{}}
+			if parent != "" {
+				c.parents = append(c.parents, parent)
+			}
+			for _, property := range def.Property {
+				c.properties[property.Code] = append(c.properties[property.Code], property.Value)
+				switch property.Code {
+				case "parent":
+					c.parents = append(c.parents, property.Value)
+				case "child":
+					children = append(children, [2]string{def.Code, property.Value})
+				}
+			}
+			cs.concepts[def.Code] = c
+			cs.order = append(cs.order, def.Code)
+			add(def.Concept, def.Code)
+		}
+	}
+	add(def.Concept, "")
+	for _, link := range children {
+		if child, ok := cs.concepts[link[1]]; ok {
+			child.parents = append(child.parents, link[0])
+		}
+	}
+
+	m.codeSystems[def.URL] = cs
+	if def.Version != "" {
+		m.codeSystems[def.URL+"|"+def.Version] = cs
+	}
+	return nil
+}
+
+// isDescendant returns whether the concept identified by code is a descendant
+// of the concept identified by ancestor.
+func (cs *codeSystem) isDescendant(code, ancestor string) bool {
+	seen := map[string]bool{code: true}
+	queue := []string{code}
+	for len(queue) > 0 {
+		c, ok := cs.concepts[queue[0]]
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+		for _, parent := range c.parents {
+			if parent == ancestor {
+				return true
+			}
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return false
+}
+
+// valueSet is the definition of a ValueSet, from either its 'compose' or its
+// 'expansion'.
+type valueSet struct {
+	url       string
+	version   string
+	compose   bool
+	include   []*rule
+	exclude   []*rule
+	expansion []Coding
+}
+
+// rule is a single include or exclude rule of a value set composition. A code
+// matches the rule if it matches every criterion of the rule.
+type rule struct {
+	system    string
+	version   string
+	concepts  []Coding
+	filters   []*filter
+	valueSets []string
+}
+
+type filter struct {
+	property string
+	op       string
+	value    string
+	pattern  *regexp.Regexp
+}
+
+func (m *Memory) addValueSet(def *valueSetDefinition) error {
+	if def.URL == "" {
+		return fmt.Errorf("terminology: ValueSet has no url")
+	}
+	vs := &valueSet{url: def.URL, version: def.Version}
+	if def.Compose != nil {
+		vs.compose = true
+		for _, include := range def.Compose.Include {
+			r, err := newRule(include)
+			if err != nil {
+				return fmt.Errorf("terminology: ValueSet '%v': %w", def.URL, err)
+			}
+			vs.include = append(vs.include, r)
+		}
+		for _, exclude := range def.Compose.Exclude {
+			r, err := newRule(exclude)
+			if err != nil {
+				return fmt.Errorf("terminology: ValueSet '%v': %w", def.URL, err)
+			}
+			vs.exclude = append(vs.exclude, r)
+		}
+	}
+	if def.Expansion != nil {
+		var add func(defs []*containsDefinition)
+		add = func(defs []*containsDefinition) {
+			for _, def := range defs {
+				if def.Code != "" {
+					vs.expansion = append(vs.expansion, Coding{System: def.System, Version: def.Version, Code: def.Code, Display: def.Display})
+				}
+				add(def.Contains)
+			}
+		}
+		add(def.Expansion.Contains)
+	}
+
+	m.valueSets[def.URL] = vs
+	if def.Version != "" {
+		m.valueSets[def.URL+"|"+def.Version] = vs
+	}
+	return nil
+}
+
+func newRule(def *includeDefinition) (*rule, error) {
+	r := &rule{system: def.System, version: def.Version, valueSets: def.ValueSet}
+	for _, concept := range def.Concept {
+		r.concepts = append(r.concepts, Coding{System: def.System, Version: def.Version, Code: concept.Code, Display: concept.Display})
+	}
+	for _, def := range def.Filter {
+		f := &filter{property: def.Property, op: def.Op, value: def.Value}
+		switch def.Op {
+		case "is-a", "descendent-of", "=":
+		case "regex":
+			pattern, err := regexp.Compile("^(?:" + def.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("filter '%v': %w", def.Property, err)
+			}
+			f.pattern = pattern
+		default:
+			return nil, fmt.Errorf("filter '%v': unsupported operator '%v'", def.Property, def.Op)
+		}
+		r.filters = append(r.filters, f)
+	}
+	return r, nil
+}
+
+// valueSet returns the value set identified by the url, along with the value
+// sets that are being evaluated once it is added to seen.
+func (m *Memory) valueSet(url string, seen []string) (*valueSet, []string, error) {
+	vs, ok := m.valueSets[url]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w '%v'", ErrUnknownValueSet, url)
+	}
+	if slices.Contains(seen, vs.url) {
+		return nil, nil, fmt.Errorf("value set '%v': circular reference", vs.url)
+	}
+	return vs, append(slices.Clip(seen), vs.url), nil
+}
+
+// memberOf reports whether the coding is a member of the value set, where seen
+// are the value sets that are already being evaluated.
+func (m *Memory) memberOf(coding Coding, url string, seen []string) (bool, error) {
+	vs, seen, err := m.valueSet(url, seen)
+	if err != nil {
+		return false, err
+	}
+
+	if !vs.compose {
+		return slices.ContainsFunc(vs.expansion, func(c Coding) bool {
+			return c.Code == coding.Code && (coding.System == "" || c.System == coding.System)
+		}), nil
+	}
+	included, err := m.matchesAny(vs.include, coding, seen)
+	if err != nil || !included {
+		return false, wrapValueSet(vs.url, err)
+	}
+	excluded, err := m.matchesAny(vs.exclude, coding, seen)
+	if err != nil {
+		return false, wrapValueSet(vs.url, err)
+	}
+	return !excluded, nil
+}
+
+func wrapValueSet(url string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("value set '%v': %w", url, err)
+}
+
+func (m *Memory) matchesAny(rules []*rule, coding Coding, seen []string) (bool, error) {
+	for _, r := range rules {
+		ok, err := m.matches(r, coding, seen)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// matches reports whether the coding matches the include or exclude rule.
+func (m *Memory) matches(r *rule, coding Coding, seen []string) (bool, error) {
+	if r.system == "" && len(r.valueSets) == 0 {
+		return false, nil
+	}
+	if r.system != "" {
+		if coding.System != "" && coding.System != r.system {
+			return false, nil
+		}
+		ok, err := m.matchesSystem(r, coding.Code)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	for _, url := range r.valueSets {
+		ok, err := m.memberOf(coding, url, seen)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchesSystem reports whether the code matches the concepts or filters that
+// the rule selects from its code system.
+func (m *Memory) matchesSystem(r *rule, code string) (bool, error) {
+	if len(r.concepts) > 0 {
+		return slices.ContainsFunc(r.concepts, func(c Coding) bool {
+			return c.Code == code
+		}), nil
+	}
+	cs, err := m.codeSystem(r.system, r.version)
+	if err != nil {
+		return false, err
+	}
+	c, ok := cs.concepts[code]
+	if !ok {
+		return false, nil
+	}
+	for _, f := range r.filters {
+		if !f.matches(cs, c) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (f *filter) matches(cs *codeSystem, c *concept) bool {
+	switch f.op {
+	case "is-a":
+		return c.code == f.value || cs.isDescendant(c.code, f.value)
+	case "descendent-of":
+		return cs.isDescendant(c.code, f.value)
+	case "=":
+		return slices.Contains(f.values(c), f.value)
+	case "regex":
+		return slices.ContainsFunc(f.values(c), f.pattern.MatchString)
+	}
+	return false
+}
+
+// values returns the values of the filtered property of the concept.
+func (f *filter) values(c *concept) []string {
+	switch f.property {
+	case "concept", "code":
+		return []string{c.code}
+	case "display":
+		return []string{c.display}
+	}
+	return c.properties[f.property]
+}
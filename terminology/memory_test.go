@@ -0,0 +1,299 @@
+package terminology_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/codesystem"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/patient"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/valueset"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
+)
+
+const (
+	animals = "http://example.org/CodeSystem/animals"
+	colours = "http://example.org/CodeSystem/colours"
+)
+
+// animalsJSON is a CodeSystem with a nested hierarchy:
+//
+//	animal
+//	├── mammal
+//	│   ├── dog
+//	│   └── cat
+//	└── bird
+//	    └── parrot
+const animalsJSON = `{
+	"resourceType": "CodeSystem",
+	"url": "http://example.org/CodeSystem/animals",
+	"version": "1.0.0",
+	"concept": [{
+		"code": "animal",
+		"display": "Animal",
+		"concept": [{
+			"code": "mammal",
+			"display": "Mammal",
+			"concept": [
+				{"code": "dog", "display": "Dog", "property": [{"code": "legs", "valueInteger": 4}]},
+				{"code": "cat", "display": "Cat", "property": [{"code": "legs", "valueInteger": 4}]}
+			]
+		}, {
+			"code": "bird",
+			"display": "Bird",
+			"concept": [
+				{"code": "parrot", "display": "Parrot", "property": [{"code": "legs", "valueInteger": 2}]}
+			]
+		}]
+	}]
+}`
+
+// colours is a CodeSystem with a hierarchy defined by the 'parent' property.
+func coloursCodeSystem() *codesystem.CodeSystem {
+	conceptOf := func(code, parent string) *codesystem.CodeSystemConcept {
+		c := &codesystem.CodeSystemConcept{Code: &fhir.Code{Value: code}}
+		if parent != "" {
+			c.Property = []*codesystem.CodeSystemConceptProperty{{
+				Code:  &fhir.Code{Value: "parent"},
+				Value: &fhir.Code{Value: parent},
+			}}
+		}
+		return c
+	}
+	return &codesystem.CodeSystem{
+		URL: &fhir.URI{Value: colours},
+		Concept: []*codesystem.CodeSystemConcept{
+			conceptOf("colour", ""),
+			conceptOf("red", "colour"),
+			conceptOf("crimson", "red"),
+			conceptOf("blue", "colour"),
+		},
+	}
+}
+
+func valueSetJSON(url, compose string) []byte {
+	return []byte(`{"resourceType": "ValueSet", "url": "` + url + `", "compose": ` + compose + `}`)
+}
+
+func newTestMemory(t *testing.T) *terminology.Memory {
+	t.Helper()
+	m, err := terminology.NewMemory(
+		coloursCodeSystem(),
+		&valueset.ValueSet{
+			URL: &fhir.URI{Value: "http://example.org/ValueSet/primary"},
+			Compose: &valueset.ValueSetCompose{
+				Include: []*valueset.ValueSetComposeInclude{{
+					System: &fhir.URI{Value: colours},
+					Concept: []*valueset.ValueSetComposeIncludeConcept{
+						{Code: &fhir.Code{Value: "red"}},
+						{Code: &fhir.Code{Value: "blue"}},
+					},
+				}},
+			},
+		},
+		&valueset.ValueSet{
+			URL: &fhir.URI{Value: "http://example.org/ValueSet/expanded"},
+			Expansion: &valueset.ValueSetExpansion{
+				Contains: []*valueset.ValueSetExpansionContains{
+					{System: &fhir.URI{Value: colours}, Code: &fhir.Code{Value: "blue"}},
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+	for _, data := range [][]byte{
+		[]byte(animalsJSON),
+		valueSetJSON("http://example.org/ValueSet/mammals", `{
+			"include": [{"system": "`+animals+`", "filter": [{"property": "concept", "op": "is-a", "value": "mammal"}]}],
+			"exclude": [{"system": "`+animals+`", "concept": [{"code": "cat"}]}]
+		}`),
+		valueSetJSON("http://example.org/ValueSet/birds", `{
+			"include": [{"system": "`+animals+`", "filter": [{"property": "concept", "op": "descendent-of", "value": "bird"}]}]
+		}`),
+		valueSetJSON("http://example.org/ValueSet/quadrupeds", `{
+			"include": [{"system": "`+animals+`", "filter": [{"property": "legs", "op": "=", "value": "4"}]}]
+		}`),
+		valueSetJSON("http://example.org/ValueSet/p-animals", `{
+			"include": [{"system": "`+animals+`", "filter": [{"property": "code", "op": "regex", "value": "p.*"}]}]
+		}`),
+		valueSetJSON("http://example.org/ValueSet/all-animals", `{
+			"include": [{"system": "`+animals+`"}]
+		}`),
+		valueSetJSON("http://example.org/ValueSet/pets", `{
+			"include": [
+				{"valueSet": ["http://example.org/ValueSet/mammals"]},
+				{"valueSet": ["http://example.org/ValueSet/p-animals"]}
+			]
+		}`),
+	} {
+		if err := m.AddJSON(data); err != nil {
+			t.Fatalf("AddJSON() error = %v", err)
+		}
+	}
+	return m
+}
+
+func TestMemoryMemberOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		coding   terminology.Coding
+		valueSet string
+		want     bool
+	}{
+		{"Enumerated concept", terminology.Coding{System: colours, Code: "red"}, "http://example.org/ValueSet/primary", true},
+		{"Concept not enumerated", terminology.Coding{System: colours, Code: "crimson"}, "http://example.org/ValueSet/primary", false},
+		{"Code without system", terminology.Coding{Code: "blue"}, "http://example.org/ValueSet/primary", true},
+		{"Different system", terminology.Coding{System: animals, Code: "red"}, "http://example.org/ValueSet/primary", false},
+		{"Expansion", terminology.Coding{System: colours, Code: "blue"}, "http://example.org/ValueSet/expanded", true},
+		{"Not in expansion", terminology.Coding{System: colours, Code: "red"}, "http://example.org/ValueSet/expanded", false},
+		{"Is-a includes self", terminology.Coding{System: animals, Code: "mammal"}, "http://example.org/ValueSet/mammals", true},
+		{"Is-a includes descendant", terminology.Coding{System: animals, Code: "dog"}, "http://example.org/ValueSet/mammals", true},
+		{"Is-a excludes other", terminology.Coding{System: animals, Code: "parrot"}, "http://example.org/ValueSet/mammals", false},
+		{"Excluded concept", terminology.Coding{System: animals, Code: "cat"}, "http://example.org/ValueSet/mammals", false},
+		{"Descendent-of excludes self", terminology.Coding{System: animals, Code: "bird"}, "http://example.org/ValueSet/birds", false},
+		{"Descendent-of includes descendant", terminology.Coding{System: animals, Code: "parrot"}, "http://example.org/ValueSet/birds", true},
+		{"Property equals", terminology.Coding{System: animals, Code: "cat"}, "http://example.org/ValueSet/quadrupeds", true},
+		{"Property not equal", terminology.Coding{System: animals, Code: "parrot"}, "http://example.org/ValueSet/quadrupeds", false},
+		{"Regex", terminology.Coding{System: animals, Code: "parrot"}, "http://example.org/ValueSet/p-animals", true},
+		{"Regex matches whole code", terminology.Coding{System: animals, Code: "mammal"}, "http://example.org/ValueSet/p-animals", false},
+		{"Whole code system", terminology.Coding{System: animals, Code: "bird"}, "http://example.org/ValueSet/all-animals", true},
+		{"Unknown code", terminology.Coding{System: animals, Code: "fish"}, "http://example.org/ValueSet/all-animals", false},
+		{"Included value set", terminology.Coding{System: animals, Code: "dog"}, "http://example.org/ValueSet/pets", true},
+		{"Second included value set", terminology.Coding{System: animals, Code: "parrot"}, "http://example.org/ValueSet/pets", true},
+		{"Not in included value sets", terminology.Coding{System: animals, Code: "cat"}, "http://example.org/ValueSet/pets", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMemory(t)
+
+			got, err := m.MemberOf(context.Background(), tc.coding, tc.valueSet)
+			if err != nil {
+				t.Fatalf("MemberOf(%v, %q) error = %v", tc.coding, tc.valueSet, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("MemberOf(%v, %q) = %v; want %v", tc.coding, tc.valueSet, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemoryMemberOf_UnknownReference_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		valueSet string
+		want     error
+	}{
+		{"Unknown value set", "http://example.org/ValueSet/unknown", terminology.ErrUnknownValueSet},
+		{"Unknown code system", "http://example.org/ValueSet/unknown-system", terminology.ErrUnknownCodeSystem},
+		{"Unknown included value set", "http://example.org/ValueSet/unknown-include", terminology.ErrUnknownValueSet},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMemory(t)
+			for _, data := range [][]byte{
+				valueSetJSON("http://example.org/ValueSet/unknown-system", `{"include": [{"system": "http://example.org/unknown"}]}`),
+				valueSetJSON("http://example.org/ValueSet/unknown-include", `{"include": [{"valueSet": ["http://example.org/ValueSet/unknown"]}]}`),
+			} {
+				if err := m.AddJSON(data); err != nil {
+					t.Fatalf("AddJSON() error = %v", err)
+				}
+			}
+
+			_, err := m.MemberOf(context.Background(), terminology.Coding{Code: "a"}, tc.valueSet)
+
+			if got, want := err, tc.want; !errors.Is(got, want) {
+				t.Errorf("MemberOf() error = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMemorySubsumes(t *testing.T) {
+	testCases := []struct {
+		name   string
+		system string
+		codeA  string
+		codeB  string
+		want   terminology.Outcome
+	}{
+		{"Same code", animals, "dog", "dog", terminology.Equivalent},
+		{"Parent of code", animals, "mammal", "dog", terminology.Subsumes},
+		{"Ancestor of code", animals, "animal", "parrot", terminology.Subsumes},
+		{"Child of code", animals, "cat", "mammal", terminology.SubsumedBy},
+		{"Unrelated codes", animals, "cat", "bird", terminology.NotSubsumed},
+		{"Parent property", colours, "colour", "crimson", terminology.Subsumes},
+		{"Parent property reversed", colours, "crimson", "red", terminology.SubsumedBy},
+		{"Versioned system", animals + "|1.0.0", "bird", "parrot", terminology.Subsumes},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMemory(t)
+
+			got, err := m.Subsumes(context.Background(), tc.system, tc.codeA, tc.codeB)
+			if err != nil {
+				t.Fatalf("Subsumes(%q, %q) error = %v", tc.codeA, tc.codeB, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("Subsumes(%q, %q) = %v; want %v", tc.codeA, tc.codeB, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemorySubsumes_UnknownCodeSystem_ReturnsError(t *testing.T) {
+	m := newTestMemory(t)
+
+	_, err := m.Subsumes(context.Background(), "http://example.org/unknown", "a", "b")
+
+	if got, want := err, terminology.ErrUnknownCodeSystem; !errors.Is(got, want) {
+		t.Errorf("Subsumes() error = %v; want %v", got, want)
+	}
+}
+
+func TestMemoryAdd_InvalidResource_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		add  func(m *terminology.Memory) error
+	}{
+		{"Unsupported resource", func(m *terminology.Memory) error {
+			return m.Add(&patient.Patient{})
+		}},
+		{"Unsupported resource type", func(m *terminology.Memory) error {
+			return m.AddJSON([]byte(`{"resourceType": "Patient"}`))
+		}},
+		{"Missing url", func(m *terminology.Memory) error {
+			return m.Add(&codesystem.CodeSystem{})
+		}},
+		{"Unsupported filter", func(m *terminology.Memory) error {
+			return m.AddJSON(valueSetJSON("http://example.org/ValueSet/invalid", `{
+				"include": [{"system": "`+animals+`", "filter": [{"property": "concept", "op": "generalizes", "value": "dog"}]}]
+			}`))
+		}},
+		{"Invalid regex", func(m *terminology.Memory) error {
+			return m.AddJSON(valueSetJSON("http://example.org/ValueSet/invalid", `{
+				"include": [{"system": "`+animals+`", "filter": [{"property": "code", "op": "regex", "value": "("}]}]
+			}`))
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := terminology.NewMemory()
+			if err != nil {
+				t.Fatalf("NewMemory() error = %v", err)
+			}
+
+			if err := tc.add(m); err == nil {
+				t.Errorf("Add() error = nil; want error")
+			}
+		})
+	}
+}
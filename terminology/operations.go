@@ -0,0 +1,355 @@
+package terminology
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/conceptmap"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/parameters"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/valueset"
+)
+
+// Expand returns the expansion of the value set. The 'filter' parameter
+// restricts the expansion to concepts whose code or display contains the
+// filter text, and the 'offset' and 'count' parameters page the expansion.
+func (m *Memory) Expand(ctx context.Context, valueSet string, params url.Values) (*valueset.ValueSet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	codings, err := m.expand(valueSet, nil)
+	if err != nil {
+		return nil, err
+	}
+	if filter := strings.ToLower(params.Get("filter")); filter != "" {
+		codings = slices.DeleteFunc(codings, func(c Coding) bool {
+			return !strings.Contains(strings.ToLower(c.Code), filter) && !strings.Contains(strings.ToLower(c.Display), filter)
+		})
+	}
+	total := len(codings)
+	offset, err := intParam(params, "offset", 0)
+	if err != nil {
+		return nil, err
+	}
+	count, err := intParam(params, "count", total)
+	if err != nil {
+		return nil, err
+	}
+	codings = codings[min(offset, total):min(offset+count, total)]
+
+	vs := m.valueSets[valueSet]
+	result := &valueset.ValueSet{
+		URL:    &fhir.URI{Value: vs.url},
+		Status: &fhir.Code{Value: "active"},
+		Expansion: &valueset.ValueSetExpansion{
+			Timestamp: &fhir.DateTime{Value: time.Now().UTC().Format(time.RFC3339)},
+			Total:     &fhir.Integer{Value: int32(total)},
+			Offset:    &fhir.Integer{Value: int32(offset)},
+		},
+	}
+	if vs.version != "" {
+		result.Version = &fhir.String{Value: vs.version}
+	}
+	for _, coding := range codings {
+		contains := &valueset.ValueSetExpansionContains{
+			System: &fhir.URI{Value: coding.System},
+			Code:   &fhir.Code{Value: coding.Code},
+		}
+		if coding.Version != "" {
+			contains.Version = &fhir.String{Value: coding.Version}
+		}
+		if coding.Display != "" {
+			contains.Display = &fhir.String{Value: coding.Display}
+		}
+		result.Expansion.Contains = append(result.Expansion.Contains, contains)
+	}
+	return result, nil
+}
+
+func intParam(params url.Values, name string, def int) (int, error) {
+	if !params.Has(name) {
+		return def, nil
+	}
+	value, err := strconv.Atoi(params.Get(name))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("parameter '%v': invalid value '%v'", name, params.Get(name))
+	}
+	return value, nil
+}
+
+// expand returns the codings in the value set, without duplicates.
+func (m *Memory) expand(url string, seen []string) ([]Coding, error) {
+	vs, seen, err := m.valueSet(url, seen)
+	if err != nil {
+		return nil, err
+	}
+	if !vs.compose {
+		return slices.Clone(vs.expansion), nil
+	}
+
+	var result []Coding
+	keys := map[string]bool{}
+	for _, r := range vs.include {
+		codings, err := m.expandRule(r, seen)
+		if err != nil {
+			return nil, wrapValueSet(vs.url, err)
+		}
+		for _, coding := range codings {
+			excluded, err := m.matchesAny(vs.exclude, coding, seen)
+			if err != nil {
+				return nil, wrapValueSet(vs.url, err)
+			}
+			if !excluded && !keys[coding.String()] {
+				keys[coding.String()] = true
+				result = append(result, coding)
+			}
+		}
+	}
+	return result, nil
+}
+
+// expandRule returns the codings that match the include rule.
+func (m *Memory) expandRule(r *rule, seen []string) ([]Coding, error) {
+	var candidates []Coding
+	var err error
+	switch {
+	case r.system != "":
+		candidates, err = m.enumerate(r)
+	case len(r.valueSets) > 0:
+		candidates, err = m.expand(r.valueSets[0], seen)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Coding
+	for _, candidate := range candidates {
+		ok, err := m.matches(r, candidate, seen)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, candidate)
+		}
+	}
+	return result, nil
+}
+
+// enumerate returns the codings that the rule may select from its code system,
+// before its filters are applied.
+func (m *Memory) enumerate(r *rule) ([]Coding, error) {
+	cs, csErr := m.codeSystem(r.system, r.version)
+	if len(r.concepts) > 0 {
+		result := slices.Clone(r.concepts)
+		for i, coding := range result {
+			if c, ok := m.concept(cs, coding.Code); ok && coding.Display == "" {
+				result[i].Display = c.display
+			}
+		}
+		return result, nil
+	}
+	if csErr != nil {
+		return nil, csErr
+	}
+	result := make([]Coding, 0, len(cs.order))
+	for _, code := range cs.order {
+		result = append(result, Coding{System: cs.url, Version: r.version, Code: code, Display: cs.concepts[code].display})
+	}
+	return result, nil
+}
+
+func (m *Memory) concept(cs *codeSystem, code string) (*concept, bool) {
+	if cs == nil {
+		return nil, false
+	}
+	c, ok := cs.concepts[code]
+	return c, ok
+}
+
+// Lookup returns the name and version of the code system of the coding, and the
+// display of its concept.
+func (m *Memory) Lookup(ctx context.Context, coding Coding, _ url.Values) (*parameters.Parameters, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cs, err := m.codeSystem(coding.System, coding.Version)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := cs.concepts[coding.Code]
+	if !ok {
+		return nil, fmt.Errorf("code system '%v': unknown code '%v'", coding.System, coding.Code)
+	}
+	name := cs.name
+	if name == "" {
+		name = cs.url
+	}
+	result := &parameters.Parameters{}
+	addParameter(result, "name", &fhir.String{Value: name})
+	if cs.version != "" {
+		addParameter(result, "version", &fhir.String{Value: cs.version})
+	}
+	if c.display != "" {
+		addParameter(result, "display", &fhir.String{Value: c.display})
+	}
+	return result, nil
+}
+
+// ValidateVS validates that the coding is in the value set, and that its
+// display, if any, is the display of its concept.
+func (m *Memory) ValidateVS(ctx context.Context, valueSet string, coding Coding, _ url.Values) (*parameters.Parameters, error) {
+	ok, err := m.MemberOf(ctx, coding, valueSet)
+	if err != nil {
+		return nil, err
+	}
+	cs, _ := m.codeSystem(coding.System, coding.Version)
+	c, _ := m.concept(cs, coding.Code)
+	if !ok {
+		return validation(false, fmt.Sprintf("The code '%v' is not in the value set '%v'", coding, valueSet), c), nil
+	}
+	return validateDisplay(coding, c), nil
+}
+
+// ValidateCS validates that the coding is defined by the code system, and that
+// its display, if any, is the display of its concept.
+func (m *Memory) ValidateCS(ctx context.Context, codeSystem string, coding Coding, _ url.Values) (*parameters.Parameters, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cs, err := m.codeSystem(codeSystem, coding.Version)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := cs.concepts[coding.Code]
+	if !ok || (coding.System != "" && coding.System != cs.url) {
+		return validation(false, fmt.Sprintf("The code '%v' is not in the code system '%v'", coding, codeSystem), nil), nil
+	}
+	return validateDisplay(coding, c), nil
+}
+
+// validateDisplay returns a successful validation of the coding if its display
+// is empty or matches the display of the concept.
+func validateDisplay(coding Coding, c *concept) *parameters.Parameters {
+	if c != nil && coding.Display != "" && c.display != "" && !strings.EqualFold(coding.Display, c.display) {
+		return validation(false, fmt.Sprintf("The display '%v' is not valid for the code '%v'; expected '%v'", coding.Display, coding, c.display), c)
+	}
+	return validation(true, "", c)
+}
+
+func validation(result bool, message string, c *concept) *parameters.Parameters {
+	params := &parameters.Parameters{}
+	addParameter(params, "result", &fhir.Boolean{Value: result})
+	if message != "" {
+		addParameter(params, "message", &fhir.String{Value: message})
+	}
+	if c != nil && c.display != "" {
+		addParameter(params, "display", &fhir.String{Value: c.display})
+	}
+	return params
+}
+
+// Translate translates the coding with the groups of the concept map whose
+// source is the system of the coding.
+func (m *Memory) Translate(ctx context.Context, conceptMap string, coding Coding, _ url.Values) (*parameters.Parameters, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cm, ok := m.conceptMaps[conceptMap]
+	if !ok {
+		return nil, fmt.Errorf("%w '%v'", ErrUnknownConceptMap, conceptMap)
+	}
+
+	var matches []*parameters.ParametersParameter
+	result := false
+	for _, group := range cm.groups {
+		if coding.System != "" && group.source != "" && group.source != coding.System {
+			continue
+		}
+		for _, target := range group.elements[coding.Code] {
+			if target.code == "" {
+				continue
+			}
+			if target.equivalence != "unmatched" && target.equivalence != "disjoint" {
+				result = true
+			}
+			match := &fhir.Coding{System: &fhir.URI{Value: group.target}, Code: &fhir.Code{Value: target.code}}
+			if target.display != "" {
+				match.Display = &fhir.String{Value: target.display}
+			}
+			matches = append(matches,
+				parameterOf("match", match),
+				parameterOf("equivalence", &fhir.Code{Value: target.equivalence}),
+			)
+		}
+	}
+
+	params := &parameters.Parameters{}
+	addParameter(params, "result", &fhir.Boolean{Value: result})
+	if !result {
+		addParameter(params, "message", &fhir.String{Value: fmt.Sprintf("No translations found for the code '%v'", coding)})
+	}
+	params.Parameter = append(params.Parameter, matches...)
+	return params, nil
+}
+
+// conceptMap is the mappings defined by a ConceptMap.
+type conceptMap struct {
+	groups []*conceptMapGroup
+}
+
+type conceptMapGroup struct {
+	source   string
+	target   string
+	elements map[string][]*conceptMapTarget
+}
+
+type conceptMapTarget struct {
+	code        string
+	display     string
+	equivalence string
+}
+
+func (m *Memory) addConceptMap(resource *conceptmap.ConceptMap) error {
+	url := resource.GetURL().GetValue()
+	if url == "" {
+		return fmt.Errorf("terminology: ConceptMap has no url")
+	}
+	cm := &conceptMap{}
+	for _, group := range resource.GetGroup() {
+		g := &conceptMapGroup{
+			source:   group.GetSource().GetValue(),
+			target:   group.GetTarget().GetValue(),
+			elements: map[string][]*conceptMapTarget{},
+		}
+		for _, element := range group.GetElement() {
+			code := element.GetCode().GetValue()
+			for _, target := range element.GetTarget() {
+				g.elements[code] = append(g.elements[code], &conceptMapTarget{
+					code:        target.GetCode().GetValue(),
+					display:     target.GetDisplay().GetValue(),
+					equivalence: target.GetEquivalence().GetValue(),
+				})
+			}
+		}
+		cm.groups = append(cm.groups, g)
+	}
+
+	m.conceptMaps[url] = cm
+	if version := resource.GetVersion().GetValue(); version != "" {
+		m.conceptMaps[url+"|"+version] = cm
+	}
+	return nil
+}
+
+func parameterOf(name string, value fhir.Element) *parameters.ParametersParameter {
+	return &parameters.ParametersParameter{Name: &fhir.String{Value: name}, Value: value}
+}
+
+func addParameter(params *parameters.Parameters, name string, value fhir.Element) {
+	params.Parameter = append(params.Parameter, parameterOf(name, value))
+}
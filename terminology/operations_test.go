@@ -0,0 +1,314 @@
+package terminology_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	fhir "github.com/friendly-fhir/go-fhir/r4/core"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/parameters"
+	"github.com/friendly-fhir/go-fhirpath/terminology"
+	"github.com/google/go-cmp/cmp"
+)
+
+const conceptMapJSON = `{
+	"resourceType": "ConceptMap",
+	"url": "http://example.org/ConceptMap/colours-to-animals",
+	"group": [{
+		"source": "http://example.org/CodeSystem/colours",
+		"target": "http://example.org/CodeSystem/animals",
+		"element": [
+			{"code": "red", "target": [
+				{"code": "parrot", "display": "Parrot", "equivalence": "wider"},
+				{"code": "cat", "equivalence": "inexact"}
+			]},
+			{"code": "blue", "target": [{"equivalence": "unmatched"}]}
+		]
+	}]
+}`
+
+// paramsOf returns the parameters as 'name=value' strings.
+func paramsOf(params *parameters.Parameters) []string {
+	var result []string
+	for _, param := range params.GetParameter() {
+		var value any
+		switch v := param.GetValue().(type) {
+		case *fhir.Boolean:
+			value = v.Value
+		case *fhir.String:
+			value = v.Value
+		case *fhir.Code:
+			value = v.Value
+		case *fhir.Coding:
+			value = v.GetSystem().GetValue() + "|" + v.GetCode().GetValue()
+		}
+		result = append(result, fmt.Sprintf("%v=%v", param.GetName().GetValue(), value))
+	}
+	return result
+}
+
+func TestMemoryExpand(t *testing.T) {
+	testCases := []struct {
+		name      string
+		valueSet  string
+		params    url.Values
+		want      []string
+		wantTotal int32
+	}{
+		{
+			name:      "Enumerated concepts",
+			valueSet:  "http://example.org/ValueSet/primary",
+			want:      []string{colours + "|red", colours + "|blue"},
+			wantTotal: 2,
+		}, {
+			name:      "Filters and exclusions",
+			valueSet:  "http://example.org/ValueSet/mammals",
+			want:      []string{animals + "|mammal", animals + "|dog"},
+			wantTotal: 2,
+		}, {
+			name:      "Included value sets without duplicates",
+			valueSet:  "http://example.org/ValueSet/pets",
+			want:      []string{animals + "|mammal", animals + "|dog", animals + "|parrot"},
+			wantTotal: 3,
+		}, {
+			name:      "Filter by display",
+			valueSet:  "http://example.org/ValueSet/all-animals",
+			params:    url.Values{"filter": {"AR"}},
+			want:      []string{animals + "|parrot"},
+			wantTotal: 1,
+		}, {
+			name:      "Paged",
+			valueSet:  "http://example.org/ValueSet/all-animals",
+			params:    url.Values{"offset": {"1"}, "count": {"2"}},
+			want:      []string{animals + "|mammal", animals + "|dog"},
+			wantTotal: 6,
+		}, {
+			name:      "Offset past the end",
+			valueSet:  "http://example.org/ValueSet/all-animals",
+			params:    url.Values{"offset": {"10"}},
+			wantTotal: 6,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMemory(t)
+
+			got, err := m.Expand(context.Background(), tc.valueSet, tc.params)
+			if err != nil {
+				t.Fatalf("Expand(%q) error = %v", tc.valueSet, err)
+			}
+
+			var codes []string
+			for _, contains := range got.GetExpansion().GetContains() {
+				codes = append(codes, contains.GetSystem().GetValue()+"|"+contains.GetCode().GetValue())
+			}
+			if diff := cmp.Diff(tc.want, codes); diff != "" {
+				t.Errorf("Expand(%q) contains mismatch (-want +got):\n%s", tc.valueSet, diff)
+			}
+			if got, want := got.GetExpansion().GetTotal().GetValue(), tc.wantTotal; got != want {
+				t.Errorf("Expand(%q) total = %v; want %v", tc.valueSet, got, want)
+			}
+		})
+	}
+}
+
+func TestMemoryExpand_InvalidRequest_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		valueSet string
+		params   url.Values
+		want     error
+	}{
+		{"Unknown value set", "http://example.org/ValueSet/unknown", nil, terminology.ErrUnknownValueSet},
+		{"Invalid count", "http://example.org/ValueSet/primary", url.Values{"count": {"-1"}}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMemory(t)
+
+			_, err := m.Expand(context.Background(), tc.valueSet, tc.params)
+
+			if err == nil {
+				t.Fatalf("Expand(%q) error = nil; want error", tc.valueSet)
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Errorf("Expand(%q) error = %v; want %v", tc.valueSet, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemoryLookup(t *testing.T) {
+	m := newTestMemory(t)
+
+	got, err := m.Lookup(context.Background(), terminology.Coding{System: animals, Code: "dog"}, nil)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	want := []string{"name=" + animals, "version=1.0.0", "display=Dog"}
+	if diff := cmp.Diff(want, paramsOf(got)); diff != "" {
+		t.Errorf("Lookup() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMemoryLookup_UnknownCode_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		coding terminology.Coding
+		want   error
+	}{
+		{"Unknown code system", terminology.Coding{System: "http://example.org/unknown", Code: "dog"}, terminology.ErrUnknownCodeSystem},
+		{"Unknown code", terminology.Coding{System: animals, Code: "fish"}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMemory(t)
+
+			_, err := m.Lookup(context.Background(), tc.coding, nil)
+
+			if err == nil {
+				t.Fatalf("Lookup(%v) error = nil; want error", tc.coding)
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Errorf("Lookup(%v) error = %v; want %v", tc.coding, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemoryValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		validate func(m *terminology.Memory) (*parameters.Parameters, error)
+		want     []string
+	}{
+		{
+			name: "Value set member",
+			validate: func(m *terminology.Memory) (*parameters.Parameters, error) {
+				return m.ValidateVS(context.Background(), "http://example.org/ValueSet/mammals", terminology.Coding{System: animals, Code: "dog", Display: "dog"}, nil)
+			},
+			want: []string{"result=true", "display=Dog"},
+		}, {
+			name: "Value set non-member",
+			validate: func(m *terminology.Memory) (*parameters.Parameters, error) {
+				return m.ValidateVS(context.Background(), "http://example.org/ValueSet/mammals", terminology.Coding{System: animals, Code: "cat"}, nil)
+			},
+			want: []string{
+				"result=false",
+				"message=The code '" + animals + "|cat' is not in the value set 'http://example.org/ValueSet/mammals'",
+				"display=Cat",
+			},
+		}, {
+			name: "Value set member with wrong display",
+			validate: func(m *terminology.Memory) (*parameters.Parameters, error) {
+				return m.ValidateVS(context.Background(), "http://example.org/ValueSet/mammals", terminology.Coding{System: animals, Code: "dog", Display: "Wolf"}, nil)
+			},
+			want: []string{
+				"result=false",
+				"message=The display 'Wolf' is not valid for the code '" + animals + "|dog'; expected 'Dog'",
+				"display=Dog",
+			},
+		}, {
+			name: "Code system concept",
+			validate: func(m *terminology.Memory) (*parameters.Parameters, error) {
+				return m.ValidateCS(context.Background(), animals, terminology.Coding{Code: "parrot"}, nil)
+			},
+			want: []string{"result=true", "display=Parrot"},
+		}, {
+			name: "Code system unknown code",
+			validate: func(m *terminology.Memory) (*parameters.Parameters, error) {
+				return m.ValidateCS(context.Background(), animals, terminology.Coding{Code: "fish"}, nil)
+			},
+			want: []string{"result=false", "message=The code 'fish' is not in the code system '" + animals + "'"},
+		}, {
+			name: "Code system different system",
+			validate: func(m *terminology.Memory) (*parameters.Parameters, error) {
+				return m.ValidateCS(context.Background(), animals, terminology.Coding{System: colours, Code: "dog"}, nil)
+			},
+			want: []string{"result=false", "message=The code '" + colours + "|dog' is not in the code system '" + animals + "'"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMemory(t)
+
+			got, err := tc.validate(m)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, paramsOf(got)); diff != "" {
+				t.Errorf("Validate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMemoryTranslate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		coding terminology.Coding
+		want   []string
+	}{
+		{
+			name:   "Mapped code",
+			coding: terminology.Coding{System: colours, Code: "red"},
+			want: []string{
+				"result=true",
+				"match=" + animals + "|parrot",
+				"equivalence=wider",
+				"match=" + animals + "|cat",
+				"equivalence=inexact",
+			},
+		}, {
+			name:   "Unmatched code",
+			coding: terminology.Coding{System: colours, Code: "blue"},
+			want: []string{
+				"result=false",
+				"message=No translations found for the code '" + colours + "|blue'",
+			},
+		}, {
+			name:   "Different system",
+			coding: terminology.Coding{System: animals, Code: "red"},
+			want: []string{
+				"result=false",
+				"message=No translations found for the code '" + animals + "|red'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMemory(t)
+			if err := m.AddJSON([]byte(conceptMapJSON)); err != nil {
+				t.Fatalf("AddJSON() error = %v", err)
+			}
+
+			got, err := m.Translate(context.Background(), "http://example.org/ConceptMap/colours-to-animals", tc.coding, nil)
+			if err != nil {
+				t.Fatalf("Translate(%v) error = %v", tc.coding, err)
+			}
+
+			if diff := cmp.Diff(tc.want, paramsOf(got)); diff != "" {
+				t.Errorf("Translate(%v) mismatch (-want +got):\n%s", tc.coding, diff)
+			}
+		})
+	}
+}
+
+func TestMemoryTranslate_UnknownConceptMap_ReturnsError(t *testing.T) {
+	m := newTestMemory(t)
+
+	_, err := m.Translate(context.Background(), "http://example.org/ConceptMap/unknown", terminology.Coding{Code: "red"}, nil)
+
+	if got, want := err, terminology.ErrUnknownConceptMap; !errors.Is(got, want) {
+		t.Errorf("Translate() error = %v; want %v", got, want)
+	}
+}
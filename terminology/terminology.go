@@ -0,0 +1,161 @@
+/*
+Package terminology provides an interface for the terminology services that are
+used by the FHIRPath 'memberOf', 'subsumes', and 'subsumedBy' functions and by
+the '%terminologies' API, along with an implementation that is backed by
+CodeSystem, ValueSet, and ConceptMap resources held in memory.
+
+See: https://hl7.org/fhir/R4/fhirpath.html#txapi
+*/
+package terminology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/parameters"
+	"github.com/friendly-fhir/go-fhir/r4/core/resources/valueset"
+)
+
+var (
+	// ErrUnknownCodeSystem is an error raised when a code system is not known
+	// to the terminology service.
+	ErrUnknownCodeSystem = errors.New("unknown code system")
+
+	// ErrUnknownValueSet is an error raised when a value set is not known to
+	// the terminology service.
+	ErrUnknownValueSet = errors.New("unknown value set")
+
+	// ErrUnknownConceptMap is an error raised when a concept map is not known
+	// to the terminology service.
+	ErrUnknownConceptMap = errors.New("unknown concept map")
+
+	// ErrUnsupported is an error raised when a terminology service does not
+	// support an operation.
+	ErrUnsupported = errors.New("unsupported terminology operation")
+)
+
+// Coding is a code defined by a code system.
+type Coding struct {
+	// System is the canonical url of the code system. This may be empty if the
+	// system is implied by the context that the code is used in, such as the
+	// binding of a 'code' element.
+	System string
+
+	// Version is the version of the code system, if known.
+	Version string
+
+	// Code is the symbol that identifies the concept within the code system.
+	Code string
+
+	// Display is the human-readable representation of the concept.
+	Display string
+}
+
+// String returns the coding in the form 'system|code'.
+func (c Coding) String() string {
+	if c.System == "" {
+		return c.Code
+	}
+	return c.System + "|" + c.Code
+}
+
+// Outcome is the relationship between two codes of the same code system, as
+// reported by the FHIR $subsumes operation.
+type Outcome string
+
+const (
+	// Equivalent indicates that the codes identify the same concept.
+	Equivalent Outcome = "equivalent"
+
+	// Subsumes indicates that the first code subsumes the second.
+	Subsumes Outcome = "subsumes"
+
+	// SubsumedBy indicates that the first code is subsumed by the second.
+	SubsumedBy Outcome = "subsumed-by"
+
+	// NotSubsumed indicates that neither code subsumes the other.
+	NotSubsumed Outcome = "not-subsumed"
+)
+
+// Terminology is an interface for the terminology services that are required
+// to evaluate FHIRPath expressions.
+type Terminology interface {
+	// MemberOf reports whether the coding is a member of the value set that is
+	// identified by the canonical url. If the value set is not known, an error
+	// wrapping ErrUnknownValueSet is returned.
+	MemberOf(ctx context.Context, coding Coding, valueSet string) (bool, error)
+
+	// Subsumes returns the relationship between codeA and codeB, which are
+	// both codes of the code system identified by system. If the code system is
+	// not known, an error wrapping ErrUnknownCodeSystem is returned.
+	Subsumes(ctx context.Context, system, codeA, codeB string) (Outcome, error)
+
+	// Expand returns the expansion of the value set identified by the canonical
+	// url, as with the FHIR ValueSet/$expand operation.
+	Expand(ctx context.Context, valueSet string, params url.Values) (*valueset.ValueSet, error)
+
+	// Lookup returns the details of the coding, as with the FHIR
+	// CodeSystem/$lookup operation.
+	Lookup(ctx context.Context, coding Coding, params url.Values) (*parameters.Parameters, error)
+
+	// ValidateVS validates that the coding is in the value set identified by
+	// the canonical url, as with the FHIR ValueSet/$validate-code operation.
+	ValidateVS(ctx context.Context, valueSet string, coding Coding, params url.Values) (*parameters.Parameters, error)
+
+	// ValidateCS validates that the coding is defined by the code system
+	// identified by the canonical url, as with the FHIR
+	// CodeSystem/$validate-code operation.
+	ValidateCS(ctx context.Context, codeSystem string, coding Coding, params url.Values) (*parameters.Parameters, error)
+
+	// Translate translates the coding with the concept map identified by the
+	// canonical url, as with the FHIR ConceptMap/$translate operation.
+	//
+	// The R4 model of Parameters cannot represent parameter parts, so each
+	// match is represented by a 'match' parameter with the matched Coding,
+	// followed by an 'equivalence' parameter with the equivalence of the match.
+	Translate(ctx context.Context, conceptMap string, coding Coding, params url.Values) (*parameters.Parameters, error)
+
+	isTerminology()
+}
+
+// BaseTerminology is an embeddable type that implements the [Terminology]
+// interface.
+//
+// This type must be embedded into a type that wishes to implement the
+// [Terminology] interface, as it will enable forward-compatibility, and
+// implement unexported functions that are required in the interface.
+type BaseTerminology struct{}
+
+func (BaseTerminology) MemberOf(_ context.Context, _ Coding, valueSet string) (bool, error) {
+	return false, fmt.Errorf("%w '%v': no terminology configured", ErrUnknownValueSet, valueSet)
+}
+
+func (BaseTerminology) Subsumes(_ context.Context, system, _, _ string) (Outcome, error) {
+	return "", fmt.Errorf("%w '%v': no terminology configured", ErrUnknownCodeSystem, system)
+}
+
+func (BaseTerminology) Expand(context.Context, string, url.Values) (*valueset.ValueSet, error) {
+	return nil, fmt.Errorf("%w 'expand'", ErrUnsupported)
+}
+
+func (BaseTerminology) Lookup(context.Context, Coding, url.Values) (*parameters.Parameters, error) {
+	return nil, fmt.Errorf("%w 'lookup'", ErrUnsupported)
+}
+
+func (BaseTerminology) ValidateVS(context.Context, string, Coding, url.Values) (*parameters.Parameters, error) {
+	return nil, fmt.Errorf("%w 'validateVS'", ErrUnsupported)
+}
+
+func (BaseTerminology) ValidateCS(context.Context, string, Coding, url.Values) (*parameters.Parameters, error) {
+	return nil, fmt.Errorf("%w 'validateCS'", ErrUnsupported)
+}
+
+func (BaseTerminology) Translate(context.Context, string, Coding, url.Values) (*parameters.Parameters, error) {
+	return nil, fmt.Errorf("%w 'translate'", ErrUnsupported)
+}
+
+func (BaseTerminology) isTerminology() {}
+
+var _ Terminology = (*BaseTerminology)(nil)